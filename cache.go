@@ -0,0 +1,129 @@
+package gql
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"sync"
+)
+
+/*
+validationCache keeps the results of the validation of the documents, keyed by the schema and the
+hash of the query, so the same document is only validated once for a schema. The validation
+doesn't depend on the variables, so they're still coerced for every request.
+
+The schema and its root types are part of the key, so when the schema or one of its root types
+is swapped, the results of the old one are not used anymore, and they're removed as the least
+recently used ones. Changing the types of a schema in place is not noticed by the cache.
+*/
+type validationCache struct {
+	mu      sync.Mutex
+	size    int
+	entries map[validationKey]*list.Element
+	lru     *list.List
+}
+
+type validationKey struct {
+	// the schema and the root types are kept by the key, so their addresses can't be reused
+	schema       *Schema
+	query        *Object
+	mutation     *Object
+	subscription *Object
+	hash         [sha256.Size]byte
+	// the result is different if the introspection is denied for the request
+	disableIntrospection bool
+}
+
+func newValidationKey(s *Schema, query string, disableIntrospection bool) validationKey {
+	return validationKey{
+		schema:               s,
+		query:                s.Query,
+		mutation:             s.Mutation,
+		subscription:         s.Subscription,
+		hash:                 sha256.Sum256([]byte(query)),
+		disableIntrospection: disableIntrospection,
+	}
+}
+
+type validationEntry struct {
+	key  validationKey
+	errs []*Error
+}
+
+func newValidationCache(size int) *validationCache {
+	return &validationCache{
+		size:    size,
+		entries: map[validationKey]*list.Element{},
+		lru:     list.New(),
+	}
+}
+
+// get returns the errors of the validation of the query, and if the query was validated
+func (c *validationCache) get(s *Schema, query string, disableIntrospection bool) ([]*Error, bool) {
+	key := newValidationKey(s, query, disableIntrospection)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(el)
+	return copyErrors(el.Value.(*validationEntry).errs), true
+}
+
+// add stores the errors of the validation of the query, removing the least recently used
+// result if the cache is full
+func (c *validationCache) add(s *Schema, query string, disableIntrospection bool, errs []*Error) {
+	key := newValidationKey(s, query, disableIntrospection)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&validationEntry{key: key, errs: copyErrors(errs)})
+	if c.lru.Len() > c.size {
+		el := c.lru.Back()
+		c.lru.Remove(el)
+		delete(c.entries, el.Value.(*validationEntry).key)
+	}
+}
+
+// copyErrors copies the errors, so the ones in the results can be changed without changing the
+// cached ones
+func copyErrors(errs []*Error) []*Error {
+	out := make([]*Error, len(errs))
+	for i, err := range errs {
+		e := *err
+		if err.Locations != nil {
+			e.Locations = make([]*ErrorLocation, len(err.Locations))
+			for j, l := range err.Locations {
+				if l != nil {
+					loc := *l
+					e.Locations[j] = &loc
+				}
+			}
+		}
+		if err.Path != nil {
+			e.Path = append([]interface{}{}, err.Path...)
+		}
+		if err.Extensions != nil {
+			e.Extensions = make(map[string]interface{}, len(err.Extensions))
+			for k, v := range err.Extensions {
+				e.Extensions[k] = copyExtension(v)
+			}
+		}
+		out[i] = &e
+	}
+	return out
+}
+
+// copyExtension copies the slices that are set as extensions by the validation, like the suggestions
+func copyExtension(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []string:
+		return append([]string{}, v...)
+	case []interface{}:
+		return append([]interface{}{}, v...)
+	}
+	return v
+}
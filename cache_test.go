@@ -0,0 +1,107 @@
+package gql_test
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rigglo/gql"
+	"github.com/rigglo/gql/pkg/language/visitor"
+)
+
+func Test_ValidationCache(t *testing.T) {
+	var validations int32
+	countingRule := &gql.ValidationRule{
+		Name: "Counting",
+		Visitor: func(ctx *gql.ValidationContext) *visitor.Visitor {
+			atomic.AddInt32(&validations, 1)
+			return &visitor.Visitor{}
+		},
+	}
+	schema := &gql.Schema{
+		Query: &gql.Object{
+			Name: "Query",
+			Fields: gql.Fields{
+				"echo": &gql.Field{
+					Type: gql.String,
+					Arguments: gql.Arguments{
+						"value": &gql.Argument{Type: gql.String},
+					},
+					Resolver: func(ctx gql.Context) (interface{}, error) {
+						return ctx.Args()["value"], nil
+					},
+				},
+			},
+		},
+	}
+	e := gql.NewExecutor(gql.ExecutorConfig{
+		Schema:              schema,
+		ValidationRules:     append([]*gql.ValidationRule{countingRule}, gql.SpecifiedRules...),
+		ValidationCacheSize: 2,
+	})
+	execute := func(query string, variables map[string]interface{}) *gql.Result {
+		return e.Execute(context.Background(), gql.Params{Query: query, Variables: variables})
+	}
+	expectValidations := func(n int32) {
+		t.Helper()
+		if v := atomic.LoadInt32(&validations); v != n {
+			t.Fatalf("expected %v validations, got %v", n, v)
+		}
+	}
+
+	query := `query($v: String) { ...F } fragment F on Query { echo(value: $v) }`
+	for _, v := range []string{"a", "b"} {
+		r := execute(query, map[string]interface{}{"v": v})
+		if len(r.Errors) != 0 || r.Data["echo"] != v {
+			t.Fatalf("expected %v, got %v %v", v, r.Data, r.Errors)
+		}
+	}
+	expectValidations(1)
+
+	invalid := `{ echo(valeu: "a") }`
+	first := execute(invalid, nil)
+	first.Errors[0].Message = "changed"
+	first.Errors[0].Locations[0].Line = 42
+	first.Errors[0].Extensions["suggestions"].([]string)[0] = "changed"
+	second := execute(invalid, nil)
+	if len(second.Errors) != 1 || second.Errors[0].Message == "changed" {
+		t.Fatalf("expected the cached validation error, got %v", second.Errors)
+	}
+	if locs := second.Errors[0].Locations; len(locs) != 1 || locs[0].Line != 1 {
+		t.Fatalf("expected the cached location, got %+v", locs)
+	}
+	if s := second.Errors[0].Extensions["suggestions"]; !reflect.DeepEqual(s, []string{"value"}) {
+		t.Fatalf("expected the cached suggestions, got %v", s)
+	}
+	expectValidations(2)
+
+	// the least recently used document is removed
+	execute(`{ a: echo }`, nil)
+	execute(invalid, nil)
+	expectValidations(3)
+	execute(query, nil)
+	expectValidations(4)
+
+	// swapping a root type invalidates the results
+	schema.Query = &gql.Object{
+		Name:   "Query",
+		Fields: schema.Query.Fields,
+	}
+	execute(query, nil)
+	expectValidations(5)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if r := execute(query, map[string]interface{}{"v": "c"}); len(r.Errors) != 0 {
+				t.Errorf("unexpected errors: %v", r.Errors)
+			}
+		}()
+	}
+	wg.Wait()
+	expectValidations(5)
+}
@@ -0,0 +1,411 @@
+package gql
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/printer"
+)
+
+/*
+Constraint is a declarative constraint on the value of an argument or an input field. The
+constraints are checked after the arguments of a field are coerced, and if a value violates
+one of them, the field is not resolved and a BAD_USER_INPUT error is reported with the path
+of the value in the arguments.
+
+	"createUser": &gql.Field{
+		Type: User,
+		Arguments: gql.Arguments{
+			"name": &gql.Argument{
+				Type:        gql.NewNonNull(gql.String),
+				Constraints: gql.Constraints{gql.MinLength(3), gql.MaxLength(20)},
+			},
+		},
+	}
+
+The built-in constraints are also added to the directives of the argument or input field as the
+@constraint directive, so they're shown in the SDL.
+*/
+type Constraint interface {
+	// Check returns an error if the value violates the constraint, the value is never nil
+	Check(value interface{}) error
+}
+
+/*
+Constraints is an alias for a bunch of Constraint
+*/
+type Constraints []Constraint
+
+/*
+ConstraintFunc is a custom constraint, it's called with the coerced value
+*/
+type ConstraintFunc func(value interface{}) error
+
+/*
+Check calls the function
+*/
+func (f ConstraintFunc) Check(value interface{}) error {
+	return f(value)
+}
+
+/*
+Min is the minimum of a number value, or of the items of a list of numbers
+*/
+func Min(min float64) Constraint {
+	return &boundConstraint{name: "min", limit: min}
+}
+
+/*
+Max is the maximum of a number value, or of the items of a list of numbers
+*/
+func Max(max float64) Constraint {
+	return &boundConstraint{name: "max", limit: max}
+}
+
+/*
+MinLength is the minimum length of a string (in characters) or a list value
+*/
+func MinLength(min int) Constraint {
+	return &lengthConstraint{name: "minLength", limit: min}
+}
+
+/*
+MaxLength is the maximum length of a string (in characters) or a list value
+*/
+func MaxLength(max int) Constraint {
+	return &lengthConstraint{name: "maxLength", limit: max}
+}
+
+/*
+Pattern is a regular expression that a string value, or the items of a list of strings,
+must match. It panics if the expression can not be compiled.
+*/
+func Pattern(expr string) Constraint {
+	return &patternConstraint{re: regexp.MustCompile(expr)}
+}
+
+/*
+OneOf lists the allowed values, like a subset of the values of an enum. The coerced values are
+compared, so for enums they're the Values of the EnumValues, not their names.
+*/
+func OneOf(values ...interface{}) Constraint {
+	return &oneOfConstraint{values: values}
+}
+
+type boundConstraint struct {
+	name  string
+	limit float64
+}
+
+func (c *boundConstraint) Check(value interface{}) error {
+	v := reflect.ValueOf(value)
+	var n float64
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		n = v.Float()
+	default:
+		return nil
+	}
+	if c.name == "min" && n < c.limit {
+		return fmt.Errorf("must be at least %v", c.limit)
+	}
+	if c.name == "max" && n > c.limit {
+		return fmt.Errorf("must be at most %v", c.limit)
+	}
+	return nil
+}
+
+func (c *boundConstraint) directiveArgument(t Type) (string, interface{}) {
+	return c.name, c.limit
+}
+
+type lengthConstraint struct {
+	name  string
+	limit int
+}
+
+func (c *lengthConstraint) Check(value interface{}) error {
+	var n int
+	switch v := value.(type) {
+	case string:
+		n = utf8.RuneCountInString(v)
+	case []interface{}:
+		n = len(v)
+	default:
+		return nil
+	}
+	if c.name == "minLength" && n < c.limit {
+		return fmt.Errorf("must be at least %v long", c.limit)
+	}
+	if c.name == "maxLength" && n > c.limit {
+		return fmt.Errorf("must be at most %v long", c.limit)
+	}
+	return nil
+}
+
+func (c *lengthConstraint) directiveArgument(t Type) (string, interface{}) {
+	return c.name, c.limit
+}
+
+type patternConstraint struct {
+	re *regexp.Regexp
+}
+
+func (c *patternConstraint) Check(value interface{}) error {
+	if s, ok := value.(string); ok && !c.re.MatchString(s) {
+		return fmt.Errorf("must match the pattern '%s'", c.re)
+	}
+	return nil
+}
+
+func (c *patternConstraint) directiveArgument(t Type) (string, interface{}) {
+	return "pattern", c.re.String()
+}
+
+type oneOfConstraint struct {
+	values []interface{}
+}
+
+func (c *oneOfConstraint) Check(value interface{}) error {
+	for _, v := range c.values {
+		if reflect.DeepEqual(v, value) {
+			return nil
+		}
+	}
+	vs := make([]string, len(c.values))
+	for i, v := range c.values {
+		vs[i] = fmt.Sprint(v)
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(vs, ", "))
+}
+
+// directiveArgument lists the allowed values as strings, the names of the enum values for enums
+// and the GraphQL literals for the other types, like "1.5"
+func (c *oneOfConstraint) directiveArgument(t Type) (string, interface{}) {
+	named := unwrapper(t)
+	vs := make([]interface{}, len(c.values))
+	for i, v := range c.values {
+		switch v := toAstValue(named, v).(type) {
+		case *ast.EnumValue:
+			vs[i] = v.Value
+		case *ast.StringValue:
+			vs[i] = v.Value
+		case nil:
+			vs[i] = fmt.Sprint(c.values[i])
+		default:
+			vs[i] = printer.PrintValue(v)
+		}
+	}
+	return "oneOf", vs
+}
+
+// itemConstraint is implemented by the constraints that are checked on the items of lists
+func (c *boundConstraint) itemConstraint()   {}
+func (c *patternConstraint) itemConstraint() {}
+func (c *oneOfConstraint) itemConstraint()   {}
+
+// checkConstraints checks the constraints on the value, and returns the path of the value that
+// violates one of them with the error
+func checkConstraints(cs Constraints, value interface{}, path []interface{}) ([]interface{}, error) {
+	for _, c := range cs {
+		items, isList := value.([]interface{})
+		if _, ok := c.(interface{ itemConstraint() }); ok && isList {
+			for i, item := range items {
+				if item == nil {
+					continue
+				}
+				if err := c.Check(item); err != nil {
+					return appendPath(path, i), err
+				}
+			}
+			continue
+		}
+		if err := c.Check(value); err != nil {
+			return path, err
+		}
+	}
+	return nil, nil
+}
+
+// checkInputValue checks the constraints of an argument or input field on its coerced value and
+// the constraints of the input fields in the value
+func checkInputValue(t Type, cs Constraints, value interface{}, path []interface{}) ([]interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if p, err := checkConstraints(cs, value, path); err != nil {
+		return p, err
+	}
+	switch t := unwrapNonNull(t).(type) {
+	case *List:
+		items, ok := value.([]interface{})
+		if !ok {
+			return checkInputValue(t.Unwrap(), nil, value, path)
+		}
+		for i, item := range items {
+			if p, err := checkInputValue(t.Unwrap(), nil, item, appendPath(path, i)); err != nil {
+				return p, err
+			}
+		}
+	case *InputObject:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, nil
+		}
+		// check the fields in a stable order, so the same error is reported every time
+		names := make([]string, 0, len(m))
+		for name := range m {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			f, ok := t.Fields[name]
+			if !ok {
+				continue
+			}
+			if p, err := checkInputValue(f.Type, f.Constraints, m[name], appendPath(path, name)); err != nil {
+				return p, err
+			}
+		}
+	}
+	return nil, nil
+}
+
+func unwrapNonNull(t Type) Type {
+	if nn, ok := t.(*NonNull); ok {
+		return nn.Unwrap()
+	}
+	return t
+}
+
+// argumentPathString formats the path of a value in the arguments, like input.tags[1]
+func argumentPathString(path []interface{}) string {
+	out := ""
+	for _, k := range path {
+		switch k := k.(type) {
+		case int:
+			out += fmt.Sprintf("[%d]", k)
+		default:
+			if out != "" {
+				out += "."
+			}
+			out += fmt.Sprint(k)
+		}
+	}
+	return out
+}
+
+// checkArgumentConstraints checks the constraints of the arguments of a field on the coerced
+// values, and reports the first violation as an error of the field
+func checkArgumentConstraints(ctx *gqlCtx, path []interface{}, args Arguments, f *ast.Field, values map[string]interface{}) bool {
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		argPath, err := checkInputValue(args[name].Type, args[name].Constraints, value, []interface{}{name})
+		if err == nil {
+			continue
+		}
+		loc := f.Location
+		if a, ok := getArgOfArgs(name, f.Arguments); ok {
+			loc = a.Location
+		}
+		ctx.addErr(withCode(&Error{
+			Message: fmt.Sprintf("invalid value for argument '%s': %s", argumentPathString(argPath), err.Error()),
+			Path:    path,
+			Locations: []*ErrorLocation{
+				{
+					Column: loc.Column,
+					Line:   loc.Line,
+				},
+			},
+			Extensions: map[string]interface{}{
+				"argumentPath": argPath,
+			},
+		}, ErrBadUserInput))
+		return false
+	}
+	return true
+}
+
+// withConstraints returns the directives with the @constraint directive of the built-in constraints
+// on a value of the type, if there are any
+func withConstraints(ds TypeSystemDirectives, t Type, cs Constraints) TypeSystemDirectives {
+	values := map[string]interface{}{}
+	for _, c := range cs {
+		if c, ok := c.(interface {
+			directiveArgument(t Type) (string, interface{})
+		}); ok {
+			name, value := c.directiveArgument(t)
+			values[name] = value
+		}
+	}
+	if len(values) == 0 {
+		return ds
+	}
+	return append(append(TypeSystemDirectives{}, ds...), &constraint{values: values})
+}
+
+// constraint is the @constraint directive, its values are the arguments of the built-in constraints
+type constraint struct {
+	values map[string]interface{}
+}
+
+func (c *constraint) GetName() string {
+	return "constraint"
+}
+
+func (c *constraint) GetDescription() string {
+	return "The @constraint directive shows the constraints on the values of arguments and input fields"
+}
+
+func (c *constraint) GetArguments() Arguments {
+	return Arguments{
+		"min": &Argument{
+			Type: Float,
+		},
+		"max": &Argument{
+			Type: Float,
+		},
+		"minLength": &Argument{
+			Type: Int,
+		},
+		"maxLength": &Argument{
+			Type: Int,
+		},
+		"pattern": &Argument{
+			Type: String,
+		},
+		"oneOf": &Argument{
+			Type: NewList(NewNonNull(String)),
+		},
+	}
+}
+
+func (c *constraint) GetLocations() []DirectiveLocation {
+	return []DirectiveLocation{
+		ArgumentDefinitionLoc,
+		InputFieldDefinitionLoc,
+	}
+}
+
+func (c *constraint) GetValues() map[string]interface{} {
+	return c.values
+}
+
+var constraintDirective = &constraint{}
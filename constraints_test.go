@@ -0,0 +1,238 @@
+package gql_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rigglo/gql"
+)
+
+var constraintsKind = &gql.Enum{
+	Name: "Kind",
+	Values: gql.EnumValues{
+		&gql.EnumValue{Name: "BOOK", Value: "BOOK"},
+		&gql.EnumValue{Name: "MOVIE", Value: "MOVIE"},
+		&gql.EnumValue{Name: "GAME", Value: "GAME"},
+	},
+}
+
+var constraintsLevel = &gql.Enum{
+	Name: "Level",
+	Values: gql.EnumValues{
+		&gql.EnumValue{Name: "LOW", Value: 1},
+		&gql.EnumValue{Name: "MEDIUM", Value: 2},
+		&gql.EnumValue{Name: "HIGH", Value: 3},
+	},
+}
+
+var constraintsFilter = &gql.InputObject{
+	Name: "Filter",
+	Fields: gql.InputFields{
+		"author": &gql.InputField{
+			Type:        gql.String,
+			Constraints: gql.Constraints{gql.MinLength(2)},
+		},
+		"rating": &gql.InputField{
+			Type:        gql.Float,
+			Constraints: gql.Constraints{gql.Min(0), gql.Max(5)},
+		},
+		"kinds": &gql.InputField{
+			Type:        gql.NewList(constraintsKind),
+			Constraints: gql.Constraints{gql.OneOf("BOOK", "MOVIE")},
+		},
+		"level": &gql.InputField{
+			Type:        constraintsLevel,
+			Constraints: gql.Constraints{gql.OneOf(1, 2)},
+		},
+	},
+}
+
+var constraintsResolved int
+
+var constraintsSchema = &gql.Schema{
+	Query: &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"search": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"text": &gql.Argument{
+						Type:        gql.String,
+						Constraints: gql.Constraints{gql.MinLength(3), gql.MaxLength(10), gql.Pattern(`^[a-z ]+$`)},
+					},
+					"limit": &gql.Argument{
+						Type:         gql.Int,
+						DefaultValue: 10,
+						Constraints:  gql.Constraints{gql.Min(1), gql.Max(100)},
+					},
+					"tags": &gql.Argument{
+						Type:        gql.NewList(gql.String),
+						Constraints: gql.Constraints{gql.MaxLength(2), gql.Pattern(`^#`)},
+					},
+					"filter": &gql.Argument{
+						Type: constraintsFilter,
+					},
+					"page": &gql.Argument{
+						Type: gql.Int,
+						Constraints: gql.Constraints{gql.ConstraintFunc(func(v interface{}) error {
+							if v.(int)%2 != 0 {
+								return errors.New("must be even")
+							}
+							return nil
+						})},
+					},
+				},
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					constraintsResolved++
+					return "ok", nil
+				},
+			},
+		},
+	},
+}
+
+func Test_Constraints(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		message   string
+		path      []interface{}
+	}{
+		{
+			name:  "Valid",
+			query: `{ search(text: "books", limit: 5, tags: ["#a", "#b"], filter: { author: "Jo", rating: 4.5, kinds: [BOOK] }, page: 2) }`,
+		},
+		{
+			name:  "NullValues",
+			query: `{ search(text: null, tags: ["#a", null], filter: { author: null }) }`,
+		},
+		{
+			name:    "MinLength",
+			query:   `{ search(text: "ab") }`,
+			message: "invalid value for argument 'text': must be at least 3 long",
+			path:    []interface{}{"text"},
+		},
+		{
+			name:    "MaxLength",
+			query:   `{ search(text: "abcdefghijk") }`,
+			message: "invalid value for argument 'text': must be at most 10 long",
+			path:    []interface{}{"text"},
+		},
+		{
+			name:    "Pattern",
+			query:   `{ search(text: "Books") }`,
+			message: "invalid value for argument 'text': must match the pattern '^[a-z ]+$'",
+			path:    []interface{}{"text"},
+		},
+		{
+			name:    "Min",
+			query:   `{ search(limit: 0) }`,
+			message: "invalid value for argument 'limit': must be at least 1",
+			path:    []interface{}{"limit"},
+		},
+		{
+			name:  "MaxFromVariable",
+			query: `query Q($limit: Int) { search(limit: $limit) }`,
+			variables: map[string]interface{}{
+				"limit": 101,
+			},
+			message: "invalid value for argument 'limit': must be at most 100",
+			path:    []interface{}{"limit"},
+		},
+		{
+			name:    "ListLength",
+			query:   `{ search(tags: ["#a", "#b", "#c"]) }`,
+			message: "invalid value for argument 'tags': must be at most 2 long",
+			path:    []interface{}{"tags"},
+		},
+		{
+			name:    "ListItem",
+			query:   `{ search(tags: ["#a", "b"]) }`,
+			message: "invalid value for argument 'tags[1]': must match the pattern '^#'",
+			path:    []interface{}{"tags", 1},
+		},
+		{
+			name:    "InputField",
+			query:   `{ search(filter: { author: "J" }) }`,
+			message: "invalid value for argument 'filter.author': must be at least 2 long",
+			path:    []interface{}{"filter", "author"},
+		},
+		{
+			name:  "InputFieldFromVariable",
+			query: `query Q($filter: Filter) { search(filter: $filter) }`,
+			variables: map[string]interface{}{
+				"filter": map[string]interface{}{"rating": 5.5},
+			},
+			message: "invalid value for argument 'filter.rating': must be at most 5",
+			path:    []interface{}{"filter", "rating"},
+		},
+		{
+			name:    "EnumSubset",
+			query:   `{ search(filter: { kinds: [BOOK, GAME] }) }`,
+			message: "invalid value for argument 'filter.kinds[1]': must be one of BOOK, MOVIE",
+			path:    []interface{}{"filter", "kinds", 1},
+		},
+		{
+			name:    "Custom",
+			query:   `{ search(page: 3) }`,
+			message: "invalid value for argument 'page': must be even",
+			path:    []interface{}{"page"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			constraintsResolved = 0
+			r := gql.Execute(context.Background(), constraintsSchema, gql.Params{Query: tt.query, Variables: tt.variables})
+			if tt.message == "" {
+				if len(r.Errors) != 0 || r.Data["search"] != "ok" {
+					t.Fatalf("expected the field to be resolved, got %v %+v", r.Data, r.Errors)
+				}
+				return
+			}
+			if len(r.Errors) != 1 {
+				t.Fatalf("expected one error, got %+v", r.Errors)
+			}
+			err := r.Errors[0]
+			if err.Message != tt.message {
+				t.Fatalf("expected error '%s', got '%s'", tt.message, err.Message)
+			}
+			if !errors.Is(err, gql.ErrBadUserInput) {
+				t.Fatalf("expected a bad user input error, got %v", err.Extensions)
+			}
+			if !reflect.DeepEqual(err.Extensions["argumentPath"], tt.path) {
+				t.Fatalf("expected argument path %v, got %v", tt.path, err.Extensions["argumentPath"])
+			}
+			if !reflect.DeepEqual(err.Path, []interface{}{"search"}) {
+				t.Fatalf("expected the path of the field, got %v", err.Path)
+			}
+			if constraintsResolved != 0 || r.Data["search"] != nil {
+				t.Fatalf("expected the field not to be resolved, got %v", r.Data)
+			}
+		})
+	}
+}
+
+func Test_ConstraintsSDL(t *testing.T) {
+	sdl := constraintsSchema.SDL()
+	for _, s := range []string{
+		`text: String @constraint(maxLength: 10, minLength: 3, pattern: "^[a-z ]+$")`,
+		`limit: Int @constraint(max: 100, min: 1)`,
+		`author: String @constraint(minLength: 2)`,
+		`rating: Float @constraint(max: 5, min: 0)`,
+		`kinds: [Kind] @constraint(oneOf: [ "BOOK", "MOVIE"])`,
+		`level: Level @constraint(oneOf: [ "LOW", "MEDIUM"])`,
+		`page: Int`,
+		`directive @constraint(max: Float, maxLength: Int, min: Float, minLength: Int, oneOf: [String!], pattern: String) on ARGUMENT_DEFINITION | INPUT_FIELD_DEFINITION`,
+	} {
+		if !strings.Contains(sdl, s) {
+			t.Fatalf("expected '%s' in the SDL, got\n%s", s, sdl)
+		}
+	}
+	if strings.Contains(sdl, "page: Int @constraint") {
+		t.Fatalf("expected no @constraint for the custom constraint, got\n%s", sdl)
+	}
+}
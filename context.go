@@ -24,10 +24,15 @@ type gqlCtx struct {
 	implementors     map[string][]Type
 	directives       map[string]Directive
 	fragments        map[string]*ast.Fragment
-	fragmentUsage    map[string]bool
-	variableDefs     map[string]map[string]*ast.Variable
-	variableUsages   map[string]map[string]struct{}
 	extensions       []Extension
+	errorPresenter   ErrorPresenter
+	recoverFunc      RecoverFunc
+	middlewares      []Middleware
+	resolvers        *sync.Map
+
+	disableSuggestions   bool
+	disableIntrospection bool
+	validationCache      *validationCache
 }
 
 func newContext(ctx context.Context, schema *Schema, doc *ast.Document, params *Params, concurrencyLimit int, concurrency bool) *gqlCtx {
@@ -44,10 +49,7 @@ func newContext(ctx context.Context, schema *Schema, doc *ast.Document, params *
 		implementors:     map[string][]Type{},
 		directives:       map[string]Directive{},
 		fragments:        map[string]*ast.Fragment{},
-		fragmentUsage:    map[string]bool{},
 		variables:        map[string]interface{}{},
-		variableDefs:     map[string]map[string]*ast.Variable{},
-		variableUsages:   map[string]map[string]struct{}{},
 	}
 }
 
@@ -57,6 +59,27 @@ func (c *gqlCtx) addErr(err *Error) {
 	c.res.Errors = append(c.res.Errors, err)
 }
 
+// presentError creates the Error for the Result from an error returned by a resolver
+func (c *gqlCtx) presentError(err error, path []interface{}, f *ast.Field) *Error {
+	presenter := c.errorPresenter
+	if presenter == nil {
+		presenter = DefaultErrorPresenter
+	}
+	e := presenter(c.ctx, err, path)
+	if e == nil {
+		e = DefaultErrorPresenter(c.ctx, err, path)
+	}
+	if e.Locations == nil && f != nil {
+		e.Locations = []*ErrorLocation{
+			{
+				Column: f.Location.Column,
+				Line:   f.Location.Line,
+			},
+		}
+	}
+	return e
+}
+
 /*
 Context for the field resolver functions
 */
@@ -65,19 +88,37 @@ type Context interface {
 	Context() context.Context
 	// Path of the field
 	Path() []interface{}
-	// Args of the field
+	// Args of the field, the arguments that are not provided and have no default value are left
+	// out, while the ones set to null are in it with a nil value, as the fields of input objects
 	Args() map[string]interface{}
 	// Parent object's data
 	Parent() interface{}
+	// Field is the definition of the field that is being resolved
+	Field() *Field
+	// ParentType is the type that contains the field
+	ParentType() *Object
+	// ReturnType is the type of the field
+	ReturnType() Type
+	// FieldAST is the field in the query, with its alias, arguments and directives
+	FieldAST() *ast.Field
+	// Operation is the operation that is being executed
+	Operation() *ast.Operation
+	// Variables are the coerced variable values of the operation
+	Variables() map[string]interface{}
+	// Schema that is being executed
+	Schema() *Schema
 }
 
 type resolveContext struct {
-	ctx    context.Context
-	gqlCtx *gqlCtx
-	path   []interface{}
-	fields []string
-	args   map[string]interface{}
-	parent interface{}
+	ctx        context.Context
+	gqlCtx     *gqlCtx
+	path       []interface{}
+	fields     []string
+	args       map[string]interface{}
+	parent     interface{}
+	field      *Field
+	parentType *Object
+	fieldAST   *ast.Field
 }
 
 func (r *resolveContext) Context() context.Context {
@@ -99,3 +140,34 @@ func (r *resolveContext) Args() map[string]interface{} {
 func (r *resolveContext) Parent() interface{} {
 	return r.parent
 }
+
+func (r *resolveContext) Field() *Field {
+	return r.field
+}
+
+func (r *resolveContext) ParentType() *Object {
+	return r.parentType
+}
+
+func (r *resolveContext) ReturnType() Type {
+	if r.field == nil {
+		return nil
+	}
+	return r.field.Type
+}
+
+func (r *resolveContext) FieldAST() *ast.Field {
+	return r.fieldAST
+}
+
+func (r *resolveContext) Operation() *ast.Operation {
+	return r.gqlCtx.operation
+}
+
+func (r *resolveContext) Variables() map[string]interface{} {
+	return r.gqlCtx.variables
+}
+
+func (r *resolveContext) Schema() *Schema {
+	return r.gqlCtx.schema
+}
@@ -2,6 +2,7 @@ package gql
 
 import (
 	"context"
+	"sort"
 
 	"github.com/rigglo/gql/pkg/language/ast"
 )
@@ -33,10 +34,19 @@ func (ds TypeSystemDirectives) ast() []*ast.Directive {
 			Name:      d.GetName(),
 			Arguments: make([]*ast.Argument, 0),
 		}
-		for an, a := range d.GetArguments() {
+		// the arguments without values are left out, the others are in a stable order
+		args, values := d.GetArguments(), d.GetValues()
+		names := make([]string, 0, len(values))
+		for an := range args {
+			if _, ok := values[an]; ok {
+				names = append(names, an)
+			}
+		}
+		sort.Strings(names)
+		for _, an := range names {
 			od.Arguments = append(od.Arguments, &ast.Argument{
 				Name:  an,
-				Value: toAstValue(a.Type, d.GetValues()[an]),
+				Value: toAstValue(args[an].Type, values[an]),
 			})
 		}
 		out = append(out, &od)
@@ -224,8 +234,51 @@ func (d *deprecated) GetValues() map[string]interface{} {
 	}
 }
 
+/*
+OneOfInput returns the @oneOf directive, that makes an input object a OneOf Input Object, where
+exactly one of the fields must be provided with a non-null value. The fields of a OneOf Input
+Object must be nullable and must not have default values.
+
+	var UserBy = &gql.InputObject{
+		Name:       "UserBy",
+		Directives: gql.TypeSystemDirectives{gql.OneOfInput()},
+		Fields: gql.InputFields{
+			"id":    &gql.InputField{Type: gql.ID},
+			"email": &gql.InputField{Type: gql.String},
+		},
+	}
+*/
+func OneOfInput() TypeSystemDirective {
+	return oneOfDirective
+}
+
+type oneOf struct{}
+
+func (d *oneOf) GetName() string {
+	return "oneOf"
+}
+
+func (d *oneOf) GetDescription() string {
+	return "The `@oneOf` directive is used within the type system definition language to indicate an Input Object is a OneOf Input Object"
+}
+
+func (d *oneOf) GetArguments() Arguments {
+	return Arguments{}
+}
+
+func (d *oneOf) GetLocations() []DirectiveLocation {
+	return []DirectiveLocation{
+		InputObjectLoc,
+	}
+}
+
+func (d *oneOf) GetValues() map[string]interface{} {
+	return map[string]interface{}{}
+}
+
 var (
 	skipDirective       = &skip{}
 	includeDirective    = &include{}
 	deprecatedDirective = &deprecated{}
+	oneOfDirective      = &oneOf{}
 )
@@ -1,10 +1,39 @@
 package gql
 
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	"github.com/rigglo/gql/pkg/language/parser"
+)
+
 /*
 Errors is an alias for a bunch of Error
+
+A resolver can return Errors to report multiple errors for the same field, each of them
+is added to the Result with the path of the field.
+
+	return nil, gql.Errors{
+		&gql.Error{Message: "first problem"},
+		&gql.Error{Message: "second problem", Extensions: map[string]interface{}{"code": "SECOND"}},
+	}
 */
 type Errors []*Error
 
+/*
+Error implements the error interface
+*/
+func (es Errors) Error() string {
+	msgs := make([]string, 0, len(es))
+	for _, e := range es {
+		msgs = append(msgs, e.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
 /*
 Error for the Result of the validation/execution
 */
@@ -22,6 +51,102 @@ func (e *Error) Error() string {
 	return e.Message
 }
 
+/*
+GetMessage returns the message of the error, so an Error can be used as a CustomError
+*/
+func (e *Error) GetMessage() string {
+	return e.Message
+}
+
+/*
+GetExtensions returns the extensions of the error, so an Error can be used as a CustomError
+*/
+func (e *Error) GetExtensions() map[string]interface{} {
+	return e.Extensions
+}
+
+/*
+Is reports whether the error has the given ErrorCode in its "code" extension, so the built-in
+errors can be checked with errors.Is
+
+	if errors.Is(err, gql.ErrBadUserInput) {
+		// the variables or arguments are invalid
+	}
+*/
+func (e *Error) Is(target error) bool {
+	c, ok := target.(ErrorCode)
+	return ok && e.Extensions["code"] == string(c)
+}
+
+/*
+Is reports whether any of the errors has the given ErrorCode
+*/
+func (es Errors) Is(target error) bool {
+	for _, e := range es {
+		if e != nil && e.Is(target) {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+ErrorCode is a stable, machine-readable code of the built-in errors, set in the "code" extension
+of the errors. The codes are also sentinel errors, so they can be used with errors.Is.
+*/
+type ErrorCode string
+
+/*
+Error implements the error interface
+*/
+func (c ErrorCode) Error() string {
+	return string(c)
+}
+
+const (
+	// ErrParseFailed is the code of the syntax errors in the document
+	ErrParseFailed ErrorCode = "GRAPHQL_PARSE_FAILED"
+	// ErrValidationFailed is the code of the errors found by the validation rules
+	ErrValidationFailed ErrorCode = "GRAPHQL_VALIDATION_FAILED"
+	// ErrBadUserInput is the code of the invalid variable and argument values
+	ErrBadUserInput ErrorCode = "BAD_USER_INPUT"
+	// ErrOperationResolutionFailure is the code of the errors when the operation to execute can
+	// not be selected from the document
+	ErrOperationResolutionFailure ErrorCode = "OPERATION_RESOLUTION_FAILURE"
+	// ErrPersistedQueryNotFound is the code for the handlers when a persisted query is not known
+	ErrPersistedQueryNotFound ErrorCode = "PERSISTED_QUERY_NOT_FOUND"
+	// ErrInternalServerError is the code of the errors while completing the results of the fields
+	// and of the masked errors
+	ErrInternalServerError ErrorCode = "INTERNAL_SERVER_ERROR"
+)
+
+// withCode sets the code extension of the error, if it's not set yet
+func withCode(e *Error, code ErrorCode) *Error {
+	if e.Extensions == nil {
+		e.Extensions = map[string]interface{}{}
+	}
+	if _, ok := e.Extensions["code"]; !ok {
+		e.Extensions["code"] = string(code)
+	}
+	return e
+}
+
+// newParseError creates the Error of a syntax error in the document
+func newParseError(err error) *Error {
+	e := &Error{
+		Message:   err.Error(),
+		Locations: []*ErrorLocation{},
+	}
+	var pe *parser.ParserError
+	if errors.As(err, &pe) {
+		e.Locations = append(e.Locations, &ErrorLocation{
+			Column: pe.Column,
+			Line:   pe.Line,
+		})
+	}
+	return withCode(e, ErrParseFailed)
+}
+
 /*
 ErrorLocation represents the location of an error in the query
 */
@@ -63,6 +188,177 @@ type CustomError interface {
 	GetExtensions() map[string]interface{}
 }
 
+/*
+Partial lets a resolver return data and errors at the same time. The errors are added to the
+Result, but the field is still completed with the given data instead of null.
+
+	return gql.Partial(items, gql.Errors{
+		&gql.Error{Message: "could not load some of the items"},
+	})
+*/
+func Partial(data interface{}, err error) (interface{}, error) {
+	if err == nil {
+		return data, nil
+	}
+	return data, &partialError{err}
+}
+
+type partialError struct {
+	err error
+}
+
+func (p *partialError) Error() string {
+	return p.err.Error()
+}
+
+func (p *partialError) Unwrap() error {
+	return p.err
+}
+
+// splitError returns the errors that has to be reported separately for a field
+// and if the data returned by the resolver should be kept
+func splitError(err error) ([]error, bool) {
+	partial := false
+	if p, ok := err.(*partialError); ok {
+		err = p.err
+		partial = true
+	}
+	out := []error{}
+	var es Errors
+	if errors.As(err, &es) {
+		for _, e := range es {
+			if e != nil {
+				out = append(out, e)
+			}
+		}
+	} else if m, ok := err.(interface{ Unwrap() []error }); ok {
+		for _, e := range m.Unwrap() {
+			if e != nil {
+				es, _ := splitError(e)
+				out = append(out, es...)
+			}
+		}
+	} else {
+		out = append(out, err)
+	}
+	// the field is null without data, so there must be an error for it,
+	// even if the resolver returned an empty list of errors
+	if len(out) == 0 && !partial {
+		out = append(out, withCode(&Error{Message: errInternalServerError}, ErrInternalServerError))
+	}
+	return out, partial
+}
+
+/*
+ErrorPresenter turns an error returned by a resolver into the Error that is added to the Result.
+The path is the path of the field that returned the error. If the returned Error has no locations,
+the executor sets them to the location of the field in the query.
+*/
+type ErrorPresenter func(ctx context.Context, err error, path []interface{}) *Error
+
+/*
+RecoverFunc is called with the recovered value when a resolver panics, the returned error is
+reported for the field (through the ErrorPresenter) instead of crashing the process.
+*/
+type RecoverFunc func(ctx context.Context, v interface{}) error
+
+/*
+DefaultErrorPresenter keeps the message and the extensions of a CustomError (also if it's wrapped),
+for any other error the message of the error is used.
+*/
+func DefaultErrorPresenter(ctx context.Context, err error, path []interface{}) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		return &Error{
+			Message:    e.Message,
+			Locations:  e.Locations,
+			Path:       path,
+			Extensions: e.Extensions,
+		}
+	}
+	var ce CustomError
+	if errors.As(err, &ce) {
+		return &Error{
+			Message:    ce.GetMessage(),
+			Path:       path,
+			Extensions: ce.GetExtensions(),
+		}
+	}
+	return &Error{
+		Message: err.Error(),
+		Path:    path,
+	}
+}
+
+/*
+PanicError is the error of a resolver that panicked, returned by DefaultRecoverFunc. Its message
+is generic, so the recovered value never reaches the clients, the value is only kept for the
+report function of MaskErrors or a custom ErrorPresenter.
+*/
+type PanicError struct {
+	Value interface{}
+}
+
+func (e *PanicError) Error() string {
+	return errInternalServerError
+}
+
+/*
+DefaultRecoverFunc returns a *PanicError with the recovered value
+*/
+func DefaultRecoverFunc(ctx context.Context, v interface{}) error {
+	return &PanicError{Value: v}
+}
+
+/*
+MaskErrors returns an ErrorPresenter for production use. CustomErrors are presented as they are,
+but any other error is replaced by a generic message and a correlation id in the extensions,
+so internal details never reach the clients. An *Error or Errors is masked as well, even though
+it implements CustomError, only errors created with NewError or custom types are kept.
+The report function (if not nil) is called with the correlation id and the original error,
+so it can be logged and found later.
+*/
+func MaskErrors(report func(ctx context.Context, id string, err error)) ErrorPresenter {
+	return func(ctx context.Context, err error, path []interface{}) *Error {
+		if isUserError(err) {
+			return DefaultErrorPresenter(ctx, err, path)
+		}
+		id := newCorrelationID()
+		if report != nil {
+			report(ctx, id, err)
+		}
+		return &Error{
+			Message: errInternalServerError,
+			Path:    path,
+			Extensions: map[string]interface{}{
+				"code":          string(ErrInternalServerError),
+				"correlationId": id,
+			},
+		}
+	}
+}
+
+// isUserError reports whether there's a CustomError in the chain of the error, other than an *Error
+// or Errors, which may hold any message, for example the one of a database error
+func isUserError(err error) bool {
+	for ; err != nil; err = errors.Unwrap(err) {
+		switch err.(type) {
+		case *Error, Errors:
+		case CustomError:
+			return true
+		}
+	}
+	return false
+}
+
+func newCorrelationID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
 const (
 	errValidateOperationName                  = "Operation name '%s' is defined multiple times"
 	errAnonymousOperationDefinitions          = "Can not use anonymous operation where multiple operation definitions exist"
@@ -70,4 +366,9 @@ const (
 	errLeafFieldSelectionsSelectionMissing    = "Selection on type '%s' is missing"
 	errFieldDoesNotExist                      = "Field '%s' does not exist on type '%s'"
 	errResponseShapeMismatch                  = "fields in set can not be merged: %s"
+	errInternalServerError                    = "Internal server error"
+	errNullValueOnNonNull                     = "null value on a NonNull field"
+	errListValueExpected                      = "expected a list value, got '%s'"
+	errInvalidEnumResult                      = "invalid result for Enum '%s'"
+	errAbstractTypeNotResolved                = "could not resolve the object type of '%s'"
 )
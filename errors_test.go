@@ -0,0 +1,384 @@
+package gql_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rigglo/gql"
+)
+
+var errorsTestSchema = &gql.Schema{
+	Query: &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"panic": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					panic("something went wrong")
+				},
+			},
+			"internal": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, errors.New("connection refused: 10.0.0.1:5432")
+				},
+			},
+			"custom": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, gql.NewError("not found", map[string]interface{}{"code": "NOT_FOUND"})
+				},
+			},
+			"wrapped": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, fmt.Errorf("loading user: %w", gql.NewError("not found", map[string]interface{}{"code": "NOT_FOUND"}))
+				},
+			},
+			"wrappedError": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, fmt.Errorf("loading user: %w", &gql.Error{Message: "connection refused: 10.0.0.1:5432"})
+				},
+			},
+			"multiple": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, gql.Errors{
+						&gql.Error{Message: "first"},
+						&gql.Error{Message: "second", Extensions: map[string]interface{}{"code": "SECOND"}},
+					}
+				},
+			},
+			"empty": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return "ignored", gql.Errors{}
+				},
+			},
+			"nilErrors": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, gql.Errors{nil}
+				},
+			},
+			"partial": &gql.Field{
+				Type: gql.NewList(gql.String),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return gql.Partial([]string{"a", "b"}, gql.Errors{
+						&gql.Error{Message: "could not load c"},
+					})
+				},
+			},
+			"ok": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return "ok", nil
+				},
+			},
+			"nonNull": &gql.Field{
+				Type: gql.NewNonNull(gql.String),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, nil
+				},
+			},
+			"echo": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"value": &gql.Argument{
+						Type: gql.String,
+					},
+				},
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return ctx.Args()["value"], nil
+				},
+			},
+		},
+	},
+	Subscription: &gql.Object{
+		Name: "Subscription",
+		Fields: gql.Fields{
+			"panic": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					panic("something went wrong")
+				},
+			},
+		},
+	},
+}
+
+func Test_ErrorPresenter(t *testing.T) {
+	ctx := context.Background()
+	var reported error
+	tests := []struct {
+		name      string
+		config    gql.ExecutorConfig
+		query     string
+		message   string
+		extension string
+	}{
+		{
+			name:    "recoverPanic",
+			config:  gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:   `{ panic ok }`,
+			message: "Internal server error",
+		},
+		{
+			name: "customRecoverFunc",
+			config: gql.ExecutorConfig{
+				Schema: errorsTestSchema,
+				RecoverFunc: func(ctx context.Context, v interface{}) error {
+					return errors.New("recovered")
+				},
+			},
+			query:   `{ panic ok }`,
+			message: "recovered",
+		},
+		{
+			name:    "defaultPresenter",
+			config:  gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:   `{ internal ok }`,
+			message: "connection refused: 10.0.0.1:5432",
+		},
+		{
+			name: "maskInternalError",
+			config: gql.ExecutorConfig{
+				Schema: errorsTestSchema,
+				ErrorPresenter: gql.MaskErrors(func(ctx context.Context, id string, err error) {
+					reported = err
+				}),
+			},
+			query:     `{ internal ok }`,
+			message:   "Internal server error",
+			extension: "correlationId",
+		},
+		{
+			name: "maskWrappedError",
+			config: gql.ExecutorConfig{
+				Schema: errorsTestSchema,
+				ErrorPresenter: gql.MaskErrors(func(ctx context.Context, id string, err error) {
+					reported = err
+				}),
+			},
+			query:     `{ wrappedError ok }`,
+			message:   "Internal server error",
+			extension: "correlationId",
+		},
+		{
+			name: "keepCustomError",
+			config: gql.ExecutorConfig{
+				Schema:         errorsTestSchema,
+				ErrorPresenter: gql.MaskErrors(nil),
+			},
+			query:     `{ custom ok }`,
+			message:   "not found",
+			extension: "code",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.NewExecutor(tt.config).Execute(ctx, gql.Params{Query: tt.query})
+			if len(r.Errors) != 1 {
+				t.Fatalf("expected one error, got %+v", r.Errors)
+			}
+			if r.Errors[0].Message != tt.message {
+				t.Fatalf("expected message '%s', got '%s'", tt.message, r.Errors[0].Message)
+			}
+			if _, ok := r.Errors[0].Extensions[tt.extension]; tt.extension != "" && !ok {
+				t.Fatalf("expected extension '%s', got %v", tt.extension, r.Errors[0].Extensions)
+			}
+			if r.Data["ok"] != "ok" {
+				t.Fatalf("expected the other fields to be resolved, got %v", r.Data)
+			}
+		})
+	}
+	if reported == nil {
+		t.Fatal("expected the masked error to be reported")
+	}
+}
+
+func Test_RecoverPanic(t *testing.T) {
+	ctx := context.Background()
+	var reported error
+	exec := gql.NewExecutor(gql.ExecutorConfig{
+		Schema: errorsTestSchema,
+		ErrorPresenter: gql.MaskErrors(func(ctx context.Context, id string, err error) {
+			reported = err
+		}),
+	})
+
+	r := exec.Execute(ctx, gql.Params{Query: `{ panic }`})
+	if len(r.Errors) != 1 || r.Errors[0].Message != "Internal server error" {
+		t.Fatalf("expected an internal server error, got %+v", r.Errors)
+	}
+	var perr *gql.PanicError
+	if !errors.As(reported, &perr) || perr.Value != "something went wrong" {
+		t.Fatalf("expected the panic value to be reported, got %#v", reported)
+	}
+
+	reported = nil
+	ch, err := exec.Subscribe(ctx, `subscription { panic }`, "", nil)
+	if ch != nil || err == nil {
+		t.Fatalf("expected the subscription to fail, got %v", err)
+	}
+	if strings.Contains(err.Error(), "something went wrong") {
+		t.Fatalf("expected the panic value not to be in the error, got %v", err)
+	}
+	if !errors.As(reported, &perr) || perr.Value != "something went wrong" {
+		t.Fatalf("expected the panic value of the subscription to be reported, got %#v", reported)
+	}
+}
+
+func Test_MultipleAndWrappedErrors(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		query    string
+		messages []string
+		data     interface{}
+		code     string
+	}{
+		{
+			name:     "wrappedCustomError",
+			query:    `{ wrapped }`,
+			messages: []string{"not found"},
+			code:     "NOT_FOUND",
+		},
+		{
+			name:     "multipleErrors",
+			query:    `{ multiple }`,
+			messages: []string{"first", "second"},
+			code:     "SECOND",
+		},
+		{
+			name:     "emptyErrors",
+			query:    `{ empty }`,
+			messages: []string{"Internal server error"},
+			code:     "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:     "nilErrors",
+			query:    `{ nilErrors }`,
+			messages: []string{"Internal server error"},
+			code:     "INTERNAL_SERVER_ERROR",
+		},
+		{
+			name:     "partialData",
+			query:    `{ partial }`,
+			messages: []string{"could not load c"},
+			data:     []interface{}{"a", "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.Execute(ctx, errorsTestSchema, gql.Params{Query: tt.query})
+			if len(r.Errors) != len(tt.messages) {
+				t.Fatalf("expected %v errors, got %+v", len(tt.messages), r.Errors)
+			}
+			code := ""
+			for i, e := range r.Errors {
+				if e.Message != tt.messages[i] {
+					t.Fatalf("expected message '%s', got '%s'", tt.messages[i], e.Message)
+				}
+				if len(e.Path) != 1 {
+					t.Fatalf("expected the path of the field, got %v", e.Path)
+				}
+				if c, ok := e.Extensions["code"]; ok {
+					code = c.(string)
+				}
+			}
+			if code != tt.code {
+				t.Fatalf("expected code '%s', got '%s'", tt.code, code)
+			}
+			if tt.data != nil && fmt.Sprint(r.Data["partial"]) != fmt.Sprint(tt.data) {
+				t.Fatalf("expected data %v, got %v", tt.data, r.Data)
+			}
+		})
+	}
+}
+
+func Test_ErrorCodes(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		config    gql.ExecutorConfig
+		query     string
+		variables map[string]interface{}
+		code      gql.ErrorCode
+	}{
+		{
+			name:   "parseFailed",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `{ ok `,
+			code:   gql.ErrParseFailed,
+		},
+		{
+			name:   "validationFailed",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `{ unknown }`,
+			code:   gql.ErrValidationFailed,
+		},
+		{
+			name:   "operationNotFound",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `query A { ok } query B { ok }`,
+			code:   gql.ErrOperationResolutionFailure,
+		},
+		{
+			name:   "missingVariable",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `query Q($v: String!) { echo(value: $v) }`,
+			code:   gql.ErrBadUserInput,
+		},
+		{
+			name:      "invalidVariable",
+			config:    gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:     `query Q($v: String) { echo(value: $v) }`,
+			variables: map[string]interface{}{"v": []interface{}{}},
+			code:      gql.ErrBadUserInput,
+		},
+		{
+			name:   "nullOnNonNull",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `{ nonNull }`,
+			code:   gql.ErrInternalServerError,
+		},
+		{
+			name: "maskedError",
+			config: gql.ExecutorConfig{
+				Schema:         errorsTestSchema,
+				ErrorPresenter: gql.MaskErrors(nil),
+			},
+			query: `{ internal }`,
+			code:  gql.ErrInternalServerError,
+		},
+		{
+			name:   "resolverCode",
+			config: gql.ExecutorConfig{Schema: errorsTestSchema},
+			query:  `{ custom }`,
+			code:   gql.ErrorCode("NOT_FOUND"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.NewExecutor(tt.config).Execute(ctx, gql.Params{Query: tt.query, Variables: tt.variables})
+			if len(r.Errors) != 1 {
+				t.Fatalf("expected one error, got %+v", r.Errors)
+			}
+			if c := r.Errors[0].Extensions["code"]; c != string(tt.code) {
+				t.Fatalf("expected code '%s', got '%v'", tt.code, c)
+			}
+			var err error = r.Errors[0]
+			if !errors.Is(err, tt.code) || !errors.Is(fmt.Errorf("executing: %w", gql.Errors(r.Errors)), tt.code) {
+				t.Fatalf("expected the error to be %s", tt.code)
+			}
+			if errors.Is(err, gql.ErrPersistedQueryNotFound) {
+				t.Fatalf("expected the error not to be %s", gql.ErrPersistedQueryNotFound)
+			}
+		})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 
@@ -19,7 +20,11 @@ type Result struct {
 }
 
 type Executor struct {
-	config *ExecutorConfig
+	config      *ExecutorConfig
+	resolvers   *sync.Map
+	validations *validationCache
+	// schemaErrors are the violations of the type system rules found by ValidateSchema
+	schemaErrors Errors
 }
 
 type ExecutorConfig struct {
@@ -27,46 +32,70 @@ type ExecutorConfig struct {
 	EnableGoroutines bool
 	Schema           *Schema
 	Extensions       []Extension
+	// ErrorPresenter is used to present the errors returned by the resolvers, DefaultErrorPresenter if not set
+	ErrorPresenter ErrorPresenter
+	// RecoverFunc is called when a resolver panics, DefaultRecoverFunc if not set
+	RecoverFunc RecoverFunc
+	// Middlewares are applied around every field resolver, the first one is the outermost
+	Middlewares []Middleware
+	// ParserOptions limit the size and depth of the parsed documents
+	ParserOptions parser.Options
+	// ValidationRules are used to validate the documents, SpecifiedRules if not set
+	ValidationRules []*ValidationRule
+	// DisableSuggestions leaves the "did you mean" suggestions out of the validation errors, since
+	// they reveal parts of the schema, like when the introspection is disabled
+	DisableSuggestions bool
+	// Introspection decides if the introspection is allowed for a request, it's always allowed if
+	// not set. If it's denied, the suggestions are left out of the validation errors too.
+	Introspection IntrospectionPolicy
+	// ValidationCacheSize is the number of validated documents, with their errors, that are
+	// cached for the schema, so the same query is not validated again, 0 disables the cache
+	ValidationCacheSize int
 }
 
 func DefaultExecutor(s *Schema) *Executor {
-	return &Executor{
-		config: &ExecutorConfig{
-			EnableGoroutines: false,
-			Schema:           s,
-		},
-	}
+	return NewExecutor(ExecutorConfig{
+		EnableGoroutines: false,
+		Schema:           s,
+	})
 }
 
+/*
+NewExecutor creates an executor with the config. The schema is validated with ValidateSchema,
+if it's invalid, every request fails with the violations as INTERNAL_SERVER_ERROR errors.
+*/
 func NewExecutor(c ExecutorConfig) *Executor {
-	return &Executor{
-		config: &c,
+	e := &Executor{
+		config:    &c,
+		resolvers: &sync.Map{},
+	}
+	if c.Schema != nil {
+		for _, err := range validateSchema(c.Schema) {
+			e.schemaErrors = append(e.schemaErrors, withCode(err, ErrInternalServerError))
+		}
+	}
+	if c.ValidationCacheSize > 0 {
+		e.validations = newValidationCache(c.ValidationCacheSize)
 	}
+	return e
 }
 
 func (e *Executor) Execute(ctx context.Context, p Params) *Result {
+	if len(e.schemaErrors) > 0 {
+		return &Result{
+			Errors: copyErrors(e.schemaErrors),
+		}
+	}
 	for _, exts := range e.config.Extensions {
 		ctx = exts.Init(ctx, p)
 	}
 
 	callExtensions(ctx, e.config.Extensions, EventParseStart, nil)
-	doc, err := parser.Parse([]byte(p.Query))
+	doc, err := parser.ParseWithOptions([]byte(p.Query), e.config.ParserOptions)
 	callExtensions(ctx, e.config.Extensions, EventParseFinish, err)
 	if err != nil {
 		return &Result{
-			Errors: Errors{
-				&Error{
-					err.Error(),
-					[]*ErrorLocation{
-						{
-							Column: err.(*parser.ParserError).Column,
-							Line:   err.(*parser.ParserError).Line,
-						},
-					},
-					nil,
-					nil,
-				},
-			},
+			Errors: Errors{newParseError(err)},
 		}
 	}
 	types, directives, implementors := getTypes(e.config.Schema)
@@ -76,9 +105,16 @@ func (e *Executor) Execute(ctx context.Context, p Params) *Result {
 	gqlctx.directives = directives
 	gqlctx.implementors = implementors
 	gqlctx.extensions = e.config.Extensions
+	gqlctx.errorPresenter = e.config.ErrorPresenter
+	gqlctx.recoverFunc = e.config.RecoverFunc
+	gqlctx.middlewares = e.config.Middlewares
+	gqlctx.resolvers = e.resolvers
+	gqlctx.disableIntrospection = e.config.Introspection != nil && !e.config.Introspection(ctx)
+	gqlctx.disableSuggestions = e.config.DisableSuggestions || gqlctx.disableIntrospection
+	gqlctx.validationCache = e.validations
 
 	callExtensions(ctx, e.config.Extensions, EventValidationStart, nil)
-	validate(gqlctx)
+	validate(gqlctx, e.config.ValidationRules)
 	callExtensions(ctx, e.config.Extensions, EventValidationFinish, gqlctx.res.Errors)
 	if len(gqlctx.res.Errors) > 0 {
 		return gqlctx.res
@@ -113,15 +149,18 @@ func (e *Executor) Subscribe(ctx context.Context, query string, operationName st
 	if e.config.Schema.Subscription == nil {
 		return nil, errors.New("Schema does not provide subscriptions")
 	}
+	if len(e.schemaErrors) > 0 {
+		return nil, fmt.Errorf("invalid schema: %w", Errors(copyErrors(e.schemaErrors)))
+	}
 	p := Params{
 		Query:         query,
 		OperationName: operationName,
 		Variables:     variables,
 	}
 
-	doc, err := parser.Parse([]byte(p.Query))
+	doc, err := parser.ParseWithOptions([]byte(p.Query), e.config.ParserOptions)
 	if err != nil {
-		return nil, err
+		return nil, newParseError(err)
 	}
 	types, directives, implementors := getTypes(e.config.Schema)
 
@@ -129,20 +168,27 @@ func (e *Executor) Subscribe(ctx context.Context, query string, operationName st
 	gqlctx.types = types
 	gqlctx.directives = directives
 	gqlctx.implementors = implementors
-
-	validate(gqlctx)
+	gqlctx.errorPresenter = e.config.ErrorPresenter
+	gqlctx.recoverFunc = e.config.RecoverFunc
+	gqlctx.middlewares = e.config.Middlewares
+	gqlctx.resolvers = e.resolvers
+	gqlctx.disableIntrospection = e.config.Introspection != nil && !e.config.Introspection(ctx)
+	gqlctx.disableSuggestions = e.config.DisableSuggestions || gqlctx.disableIntrospection
+	gqlctx.validationCache = e.validations
+
+	validate(gqlctx, e.config.ValidationRules)
 	if len(gqlctx.res.Errors) > 0 {
-		return nil, errors.New("validation error: invalid document")
+		return nil, fmt.Errorf("validation error: invalid document: %w", Errors(gqlctx.res.Errors))
 	}
 
 	getOperation(gqlctx)
 	if len(gqlctx.res.Errors) > 0 {
-		return nil, errors.New("invalid operation")
+		return nil, fmt.Errorf("invalid operation: %w", Errors(gqlctx.res.Errors))
 	}
 
 	coerceVariableValues(gqlctx)
 	if len(gqlctx.res.Errors) > 0 {
-		return nil, errors.New("invalid variables")
+		return nil, fmt.Errorf("invalid variables: %w", Errors(gqlctx.res.Errors))
 	}
 
 	return subscribe(gqlctx)
@@ -165,11 +211,12 @@ func getOperation(ctx *gqlCtx) {
 		if len(ctx.doc.Operations) == 1 {
 			op = ctx.doc.Operations[0]
 		} else {
-			ctx.addErr(&Error{
+			ctx.addErr(withCode(&Error{
 				Message:   "missing operationName",
 				Path:      []interface{}{},
 				Locations: []*ErrorLocation{},
-			})
+			}, ErrOperationResolutionFailure))
+			return
 		}
 	} else {
 		for _, o := range ctx.doc.Operations {
@@ -182,21 +229,23 @@ func getOperation(ctx *gqlCtx) {
 		ctx.operation = op
 		return
 	}
-	ctx.addErr(&Error{
+	ctx.addErr(withCode(&Error{
 		Message:   "operation not found",
 		Path:      []interface{}{},
 		Locations: []*ErrorLocation{},
-	})
+	}, ErrOperationResolutionFailure))
 	return
 }
 
+// coerceVariableValues coerces the values of the variables of the operation as in the spec
+// (6.1.2), the variables that are not provided and have no default value are left out
 func coerceVariableValues(ctx *gqlCtx) {
 	coercedValues := map[string]interface{}{}
 
 	for _, varDef := range ctx.operation.Variables {
 		varType, Err := resolveAstType(ctx.types, varDef.Type)
 		if Err != nil {
-			ctx.addErr(&Error{
+			ctx.addErr(withCode(&Error{
 				Message: "invalid type for variable",
 				Path:    []interface{}{},
 				Locations: []*ErrorLocation{
@@ -205,11 +254,11 @@ func coerceVariableValues(ctx *gqlCtx) {
 						Line:   varDef.Location.Line,
 					},
 				},
-			})
+			}, ErrBadUserInput))
 			continue
 		}
 		if !isInputType(varType) {
-			ctx.addErr(&Error{
+			ctx.addErr(withCode(&Error{
 				Message: "variable type is not input type",
 				Path:    []interface{}{},
 				Locations: []*ErrorLocation{
@@ -218,29 +267,21 @@ func coerceVariableValues(ctx *gqlCtx) {
 						Line:   varDef.Location.Line,
 					},
 				},
-			})
+			}, ErrBadUserInput))
 			continue
 		}
 		value, hasValue := ctx.params.Variables[varDef.Name]
 		if !hasValue && varDef.DefaultValue != nil {
-			defaultValue, err := coerceValue(ctx, varDef.DefaultValue, varType)
+			// the default value is a literal without variables, so no variables are needed
+			defaultValue, err := coerceLiteral(ctx, varDef.DefaultValue, varType, []interface{}{varDef.Name})
 			if err != nil {
-				ctx.addErr(&Error{
-					Message: "couldn't coerece default value of variable",
-					Path:    []interface{}{},
-					Locations: []*ErrorLocation{
-						{
-							Column: varDef.Location.Column,
-							Line:   varDef.Location.Line,
-						},
-					},
-				})
+				ctx.addErr(newVariableError(varDef, err))
 				continue
 			}
 			coercedValues[varDef.Name] = defaultValue
 		} else if varType.GetKind() == NonNullKind && (!hasValue || value == nil) {
-			ctx.addErr(&Error{
-				Message: "null value or missing value for non null type",
+			ctx.addErr(withCode(&Error{
+				Message: fmt.Sprintf("null value or missing value for variable '$%s' of non null type '%s'", varDef.Name, varType),
 				Path:    []interface{}{},
 				Locations: []*ErrorLocation{
 					{
@@ -248,31 +289,41 @@ func coerceVariableValues(ctx *gqlCtx) {
 						Line:   varDef.Location.Line,
 					},
 				},
-			})
+				Extensions: map[string]interface{}{
+					"variablePath": []interface{}{varDef.Name},
+				},
+			}, ErrBadUserInput))
 			continue
 		} else if hasValue {
-			if value == nil {
-				coercedValues[varDef.Name] = nil
-			} else if cv, err := coerceValue(ctx, value, varType); err == nil {
-				coercedValues[varDef.Name] = cv
-			} else {
-				ctx.addErr(&Error{
-					Message: err.Error(),
-					Path:    []interface{}{},
-					Locations: []*ErrorLocation{
-						{
-							Column: varDef.Location.Column,
-							Line:   varDef.Location.Line,
-						},
-					},
-				})
+			cv, err := coerceInputValue(value, varType, []interface{}{varDef.Name})
+			if err != nil {
+				ctx.addErr(newVariableError(varDef, err))
 				continue
 			}
+			coercedValues[varDef.Name] = cv
 		}
 	}
 	ctx.variables = coercedValues
 }
 
+// newVariableError reports the invalid value of a variable, with its path in the variable, like
+// $input.items[2].price
+func newVariableError(varDef *ast.Variable, err *coercionError) *Error {
+	return withCode(&Error{
+		Message: fmt.Sprintf("invalid value for variable '$%s': %s", argumentPathString(err.path), err.Error()),
+		Path:    []interface{}{},
+		Locations: []*ErrorLocation{
+			{
+				Column: varDef.Location.Column,
+				Line:   varDef.Location.Line,
+			},
+		},
+		Extensions: map[string]interface{}{
+			"variablePath": err.path,
+		},
+	}, ErrBadUserInput)
+}
+
 func resolveAstType(types map[string]Type, t ast.Type) (Type, *Error) {
 	switch t.Kind() {
 	case ast.List:
@@ -292,7 +343,7 @@ func resolveAstType(types map[string]Type, t ast.Type) (Type, *Error) {
 			return t, nil
 		}
 		return nil, &Error{
-			Message: "invalid type",
+			Message: fmt.Sprintf("Unknown type '%s'.", t.GetValue().(string)),
 			Locations: []*ErrorLocation{
 				{
 					Column: t.(*ast.NamedType).Location.Column,
@@ -314,7 +365,7 @@ func resolveOperation(ctx *gqlCtx) {
 		ctx.res = executeMutation(ctx, ctx.operation)
 	default:
 		ctx.res = &Result{
-			Errors: Errors{&Error{"invalid operation", nil, nil, nil}},
+			Errors: Errors{withCode(&Error{Message: "invalid operation"}, ErrOperationResolutionFailure)},
 		}
 	}
 }
@@ -349,34 +400,53 @@ func subscribe(ctx *gqlCtx) (<-chan interface{}, error) {
 	for rkey, fs := range gfields {
 		fieldName := fs[0].Name
 		if !strings.HasPrefix(fieldName, "__") {
-			res, err := ctx.schema.Subscription.Fields[fieldName].Resolver(
+			args, ok := coerceArgumentValues(ctx, []interface{}{rkey}, ctx.schema.Subscription, fs[0])
+			if !ok {
+				return nil, fmt.Errorf("invalid arguments: %w", Errors(ctx.res.Errors))
+			}
+			path := []interface{}{rkey}
+			res, err := callResolver(ctx, ctx.fieldResolver(ctx.schema.Subscription, fieldName),
 				&resolveContext{
-					ctx:    ctx.ctx, // this is the original context
-					gqlCtx: ctx,     // execution context
-					args:   coerceArgumentValues(ctx, []interface{}{rkey}, ctx.schema.Subscription, fs[0]),
-					parent: ctx.schema.RootValue, // root value
-					path:   []interface{}{rkey},
+					ctx:        ctx.ctx, // this is the original context
+					gqlCtx:     ctx,     // execution context
+					args:       args,
+					parent:     ctx.schema.RootValue, // root value
+					path:       path,
+					field:      ctx.schema.Subscription.Fields[fieldName],
+					parentType: ctx.schema.Subscription,
+					fieldAST:   fs[0],
 				},
 			)
 			if err != nil {
-				return nil, err
+				errs, _ := splitError(err)
+				for _, e := range errs {
+					ctx.addErr(ctx.presentError(e, path, fs[0]))
+				}
+				return nil, fmt.Errorf("subscription failed: %w", Errors(ctx.res.Errors))
 			}
 
 			if ch, ok := res.(chan interface{}); ok {
+				ft := ctx.schema.Subscription.Fields[fieldName].Type
 				go func() {
 					for v := range ch {
-						res, hasErr := completeValue(ctx, nil, ctx.schema.Subscription.Fields[fieldName].Type, fs, v)
-						if hasErr {
+						res, hasErr := completeValue(ctx, []interface{}{rkey}, ft, fs, v)
+						ctx.errMu.Lock()
+						errs := ctx.res.Errors
+						ctx.res.Errors = []*Error{}
+						ctx.errMu.Unlock()
+						// the error only nulls the data if the field is NonNull
+						if hasErr && ft.GetKind() == NonNullKind {
 							out <- &Result{
 								Data:   nil,
-								Errors: ctx.res.Errors,
+								Errors: errs,
 							}
-							ctx.res.Errors = []*Error{}
+							continue
 						}
 						out <- &Result{
 							Data: map[string]interface{}{
 								rkey: res,
 							},
+							Errors: errs,
 						}
 					}
 					close(out)
@@ -388,18 +458,42 @@ func subscribe(ctx *gqlCtx) (<-chan interface{}, error) {
 	return nil, errors.New("invalid subscription")
 }
 
+// executeSelectionSet executes the selection set on the object value, if a field with a NonNull type
+// is null because of an error, the whole selection set is null and the second return value is true
 func executeSelectionSet(ctx *gqlCtx, path []interface{}, ss []ast.Selection, ot *Object, ov interface{}) (map[string]interface{}, bool) {
 	gfields := collectFields(ctx, ot, ss, nil)
 	resMap := map[string]interface{}{}
 	hasNullErrs := false
+	mu := sync.Mutex{}
 	conc := ctx.concurrency
 	if ctx.schema.Mutation != nil {
 		conc = ot.Name == ctx.schema.Mutation.Name
 	}
+
+	execute := func(rkey string, fs ast.Fields) {
+		var (
+			rval   interface{}
+			hasErr bool
+		)
+		if strings.HasPrefix(fs[0].Name, "__") {
+			rval, hasErr = resolveMetaFields(ctx, appendPath(path, rkey), fs, ot)
+		} else {
+			fieldType := ot.Fields[fs[0].Name].GetType()
+			rval, hasErr = executeField(ctx, appendPath(path, rkey), ot, ov, fieldType, fs)
+			// the error only propagates to the parent if the field is NonNull
+			hasErr = hasErr && fieldType.GetKind() == NonNullKind
+		}
+		mu.Lock()
+		if hasErr {
+			hasNullErrs = true
+		}
+		resMap[rkey] = rval
+		mu.Unlock()
+	}
+
 	if conc {
 		wg := sync.WaitGroup{}
 		wg.Add(len(gfields))
-		mu := sync.Mutex{}
 
 		for rkey, fields := range gfields {
 			fs := fields
@@ -407,63 +501,19 @@ func executeSelectionSet(ctx *gqlCtx, path []interface{}, ss []ast.Selection, ot
 			select {
 			case ctx.sem <- struct{}{}:
 				go func() {
-					var (
-						rval   interface{}
-						hasErr bool
-					)
-					if strings.HasPrefix(fs[0].Name, "__") {
-						rval, hasErr = resolveMetaFields(ctx, fs, ot)
-					} else {
-						fieldType := ot.Fields[fs[0].Name].GetType()
-						rval, hasErr = executeField(ctx, append(path, fs[0].Alias), ot, ov, fieldType, fs)
-					}
-					if hasErr {
-						hasNullErrs = true
-					}
-					mu.Lock()
-					resMap[rkey] = rval
-					mu.Unlock()
+					execute(rkey, fs)
 					<-ctx.sem
 					wg.Done()
 				}()
 			default:
-				var (
-					rval   interface{}
-					hasErr bool
-				)
-				if strings.HasPrefix(fs[0].Name, "__") {
-					rval, hasErr = resolveMetaFields(ctx, fs, ot)
-				} else {
-					fieldType := ot.Fields[fs[0].Name].GetType()
-					rval, hasErr = executeField(ctx, append(path, fs[0].Alias), ot, ov, fieldType, fs)
-				}
-				if hasErr {
-					hasNullErrs = true
-				}
-				mu.Lock()
-				resMap[rkey] = rval
-				mu.Unlock()
+				execute(rkey, fs)
 				wg.Done()
 			}
 		}
 		wg.Wait()
 	} else {
 		for rkey, fs := range gfields {
-			fieldName := fs[0].Name
-			var (
-				rval   interface{}
-				hasErr bool
-			)
-			if strings.HasPrefix(fieldName, "__") {
-				rval, hasErr = resolveMetaFields(ctx, fs, ot)
-			} else {
-				fieldType := ot.Fields[fieldName].GetType()
-				rval, hasErr = executeField(ctx, append(path, fs[0].Alias), ot, ov, fieldType, fs)
-			}
-			if hasErr {
-				hasNullErrs = true
-			}
-			resMap[rkey] = rval
+			execute(rkey, fs)
 		}
 	}
 	if hasNullErrs {
@@ -472,6 +522,14 @@ func executeSelectionSet(ctx *gqlCtx, path []interface{}, ss []ast.Selection, ot
 	return resMap, false
 }
 
+// appendPath returns a new path with the key appended, it never shares the underlying
+// array with the parent path, since sibling fields can be executed concurrently
+func appendPath(path []interface{}, key interface{}) []interface{} {
+	out := make([]interface{}, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, key)
+}
+
 func collectFields(ctx *gqlCtx, t *Object, ss []ast.Selection, vFrags []string) map[string]ast.Fields {
 	if vFrags == nil {
 		vFrags = []string{}
@@ -599,33 +657,51 @@ func getArgOfArgs(an string, as []*ast.Argument) (*ast.Argument, bool) {
 	return nil, false
 }
 
+// executeField resolves and completes the value of a field, the returned bool is true
+// if the value is null because of a field error
 func executeField(ctx *gqlCtx, path []interface{}, ot *Object, ov interface{}, ft Type, fs ast.Fields) (interface{}, bool) {
 	f := fs[0]
-	return completeValue(ctx, path, ot.Fields[f.Name].GetType(), fs, resolveFieldValue(ctx, path, f, ot, ov, f.Name, coerceArgumentValues(ctx, path, ot, f)))
+	args, ok := coerceArgumentValues(ctx, path, ot, f)
+	if !ok {
+		return nil, true
+	}
+	v, ok := resolveFieldValue(ctx, path, f, ot, ov, f.Name, args)
+	if !ok {
+		return nil, true
+	}
+	return completeValue(ctx, path, ot.Fields[f.Name].GetType(), fs, v)
 }
 
-func coerceArgumentValues(ctx *gqlCtx, path []interface{}, ot *Object, f *ast.Field) map[string]interface{} {
+// coerceArgumentValues coerces the arguments of the field as in the spec (6.4.1) and checks their
+// constraints, it returns false if there was an error, so the field can't be resolved. The
+// arguments that are not provided and have no default value are left out of the values, while
+// the ones set to null are in it with a nil value.
+func coerceArgumentValues(ctx *gqlCtx, path []interface{}, ot *Object, f *ast.Field) (map[string]interface{}, bool) {
 	coercedVals := map[string]interface{}{}
 	argDefs := ot.Fields[f.Name].Arguments
-	for argName, argDef := range argDefs {
-		defaultValue := argDef.DefaultValue
+	// coerce the arguments in a stable order, so the same error is reported every time
+	names := make([]string, 0, len(argDefs))
+	for name := range argDefs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, argName := range names {
+		argDef := argDefs[argName]
 		argVal, hasValue := getArgOfArgs(argName, f.Arguments)
 		var value interface{}
-		if argVal != nil {
-			if argVal.Value.Kind() == ast.VariableValueKind {
-				varName := argVal.Value.(*ast.VariableValue).Name
-
-				//ctx.mu.Lock()
-				value, hasValue = ctx.variables[varName]
-				//ctx.mu.Unlock()
+		isVariable := false
+		if hasValue {
+			if vv, ok := argVal.Value.(*ast.VariableValue); ok {
+				value, hasValue = ctx.variables[vv.Name]
+				isVariable = true
 			} else {
 				value = argVal.Value
 			}
 		}
 		if !hasValue && argDef.IsDefaultValueSet() {
-			coercedVals[argName] = defaultValue
-		} else if argDef.Type.GetKind() == NonNullKind && (!hasValue || value == nil) {
-			ctx.addErr(&Error{
+			coercedVals[argName] = argDef.DefaultValue
+		} else if argDef.Type.GetKind() == NonNullKind && (!hasValue || isNullValue(value)) {
+			ctx.addErr(withCode(&Error{
 				Message: fmt.Sprintf("Argument '%s' is a Non-Null field, but got null value", argName),
 				Path:    path,
 				Locations: []*ErrorLocation{
@@ -634,174 +710,305 @@ func coerceArgumentValues(ctx *gqlCtx, path []interface{}, ot *Object, f *ast.Fi
 						Line:   f.Location.Line,
 					},
 				},
-			})
+				Extensions: map[string]interface{}{
+					"argumentPath": []interface{}{argName},
+				},
+			}, ErrBadUserInput))
+			return nil, false
 		} else if hasValue {
-			if value == nil {
-				coercedVals[argName] = value
-			} else if argVal.Value.Kind() == ast.VariableValueKind {
+			if isVariable {
+				// the values of the variables are already coerced
 				coercedVals[argName] = value
-			} else {
-				coercedVal, err := coerceValue(ctx, value, argDef.Type)
-				if err != nil {
-					ctx.addErr(&Error{
-						Message: err.Error(),
-						Path:    path,
-						Locations: []*ErrorLocation{
-							{
-								Column: argVal.Location.Column,
-								Line:   argVal.Location.Line,
-							},
+				continue
+			}
+			coercedVal, err := coerceLiteral(ctx, argVal.Value, argDef.Type, []interface{}{argName})
+			if err != nil {
+				ctx.addErr(withCode(&Error{
+					Message: fmt.Sprintf("invalid value for argument '%s': %s", argumentPathString(err.path), err.Error()),
+					Path:    path,
+					Locations: []*ErrorLocation{
+						{
+							Column: argVal.Location.Column,
+							Line:   argVal.Location.Line,
 						},
-					})
-				} else {
-					coercedVals[argName] = coercedVal
-				}
+					},
+					Extensions: map[string]interface{}{
+						"argumentPath": err.path,
+					},
+				}, ErrBadUserInput))
+				return nil, false
 			}
+			coercedVals[argName] = coercedVal
 		}
+	}
+	return coercedVals, checkArgumentConstraints(ctx, path, argDefs, f, coercedVals)
+}
+
+// coercionError is an error of the coercion of an input value, with the path of the invalid
+// value, that starts with the name of the variable or argument
+type coercionError struct {
+	path []interface{}
+	err  error
+}
+
+func (e *coercionError) Error() string {
+	return e.err.Error()
+}
 
+func newCoercionError(path []interface{}, format string, args ...interface{}) *coercionError {
+	return &coercionError{
+		path: path,
+		err:  fmt.Errorf(format, args...),
 	}
-	return coercedVals
 }
 
-func coerceValue(ctx *gqlCtx, val interface{}, t Type) (interface{}, error) {
-	switch {
-	case t.GetKind() == NonNullKind:
-		if _, ok := val.(ast.NullValue); ok || val == nil {
-			return nil, errors.New("Null value on NonNull type")
-		}
-		return coerceValue(ctx, val, t.(*NonNull).Unwrap())
-	case t.GetKind() == ListKind:
-		wt := t.(*List).Unwrap()
-		switch val := val.(type) {
-		case *ast.ListValue:
-			res := make([]interface{}, len(val.Values))
-			for i := 0; i < len(res); i++ {
-				r, err := coerceValue(ctx, val.Values[i].GetValue(), wt)
-				if err != nil {
-					return nil, err
-				}
-				res[i] = r
-			}
-			return res, nil
-		case []interface{}:
-			res := make([]interface{}, len(val))
-			for i := 0; i < len(res); i++ {
-				r, err := coerceValue(ctx, val[i], wt)
-				if err != nil {
-					return nil, err
-				}
-				res[i] = r
+// isNullValue returns if the value is null, either nil or a null literal
+func isNullValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	_, ok := value.(*ast.NullValue)
+	return ok
+}
+
+// coerceInputValue coerces a value provided at runtime, like the value of a variable, to the
+// input type
+func coerceInputValue(value interface{}, t Type, path []interface{}) (interface{}, *coercionError) {
+	if t.GetKind() == NonNullKind {
+		if value == nil {
+			return nil, newCoercionError(path, "null value for non null type '%s'", t)
+		}
+		return coerceInputValue(value, t.(*NonNull).Unwrap(), path)
+	}
+	if value == nil {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			// a single value is coerced to a list of one item
+			item, err := coerceInputValue(value, t.Unwrap(), path)
+			if err != nil {
+				return nil, err
 			}
-			return res, nil
-		}
-		return nil, fmt.Errorf("invalid list value")
-	case t.GetKind() == ScalarKind:
-		if raw, ok := val.(ast.Value); ok {
-			return t.(*Scalar).CoerceInputFunc(raw.GetValue())
-		}
-		return t.(*Scalar).CoerceInputFunc(val)
-	case t.GetKind() == EnumKind:
-		e := t.(*Enum)
-		switch val := val.(type) {
-		case ast.Value:
-			for _, ev := range e.Values {
-				if ev.Name == val.GetValue().(string) {
-					return ev.Value, nil
-				}
+			return []interface{}{item}, nil
+		}
+		res := make([]interface{}, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			item, err := coerceInputValue(rv.Index(i).Interface(), t.Unwrap(), appendPath(path, i))
+			if err != nil {
+				return nil, err
 			}
-		case string:
-			for _, ev := range e.Values {
-				if ev.Name == val {
+			res[i] = item
+		}
+		return res, nil
+	case *Scalar:
+		v, err := t.CoerceInputFunc(value)
+		if err != nil {
+			return nil, &coercionError{path: path, err: err}
+		}
+		return v, nil
+	case *Enum:
+		if name, ok := value.(string); ok {
+			for _, ev := range t.Values {
+				if ev.Name == name {
 					return ev.Value, nil
 				}
 			}
 		}
-		return nil, fmt.Errorf("invalid enum value")
-	case t.GetKind() == InputObjectKind:
+		return nil, newCoercionError(path, "invalid value for enum '%s'", t.Name)
+	case *InputObject:
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, newCoercionError(path, "invalid value for input object '%s'", t.Name)
+		}
+		if err := checkUnknownFields(t, namesOf(m), path); err != nil {
+			return nil, err
+		}
 		res := map[string]interface{}{}
-		o := t.(*InputObject)
-		switch val := val.(type) {
-		case *ast.ObjectValue:
-			for _, astf := range val.Fields {
-				field, ok := o.Fields[astf.Name]
-				if !ok {
-					return nil, fmt.Errorf("field '%s' is not defined on '%s'", astf.Name, o.Name)
-				}
-
-				if astf.Value.Kind() == ast.NullValueKind && field.Type.GetKind() != NonNullKind {
-					res[astf.Name] = nil
-				}
-				if astf.Value.Kind() != ast.VariableValueKind {
-					if fv, err := coerceValue(ctx, astf.Value, field.Type); err == nil {
-						res[astf.Name] = fv
-					} else {
-						return nil, err
-					}
+		for _, fn := range sortedNames(namesOf(t.Fields)) {
+			field := t.Fields[fn]
+			fv, ok := m[fn]
+			if !ok {
+				if err := coerceAbsentField(res, fn, field, path); err != nil {
+					return nil, err
 				}
-				if astf.Value.Kind() == ast.VariableValueKind {
-					vv := astf.Value.(*ast.VariableValue)
-
-					ctx.mu.Lock()
-					varVal, ok := ctx.variables[vv.Name]
-					ctx.mu.Unlock()
+				continue
+			}
+			cv, err := coerceInputValue(fv, field.Type, appendPath(path, fn))
+			if err != nil {
+				return nil, err
+			}
+			res[fn] = cv
+		}
+		if t.IsOneOf() {
+			if err := checkOneOfValue(t, res); err != nil {
+				return nil, &coercionError{path: path, err: err}
+			}
+		}
+		return res, nil
+	}
+	return nil, newCoercionError(path, "'%s' is not an input type", t)
+}
 
-					if ok && varVal == nil && field.Type.GetKind() == NonNullKind {
-						return nil, fmt.Errorf("null value on NonNull type")
-					} else if ok {
-						res[astf.Name] = varVal
-					} else {
-						ctx.mu.Lock()
-						vDef := ctx.variableDefs[ctx.operation.Name][vv.Name]
-						ctx.mu.Unlock()
-
-						if vDef.DefaultValue != nil {
-							defVal, err := coerceValue(ctx, vDef.DefaultValue, field.Type)
-							if err != nil {
-								return nil, err
-							}
-							res[astf.Name] = defVal
-						} else {
-							if field.IsDefaultValueSet() {
-								res[astf.Name] = field.DefaultValue
-							}
-						}
-					}
-				}
+// coerceLiteral coerces a value in the document to the input type, the variables in the value
+// are replaced by their coerced values
+func coerceLiteral(ctx *gqlCtx, value ast.Value, t Type, path []interface{}) (interface{}, *coercionError) {
+	if vv, ok := value.(*ast.VariableValue); ok {
+		// the variables not provided are null in lists, the absent input fields are handled
+		// when the input object is coerced
+		v := ctx.variables[vv.Name]
+		if v == nil && t.GetKind() == NonNullKind {
+			return nil, newCoercionError(path, "null value for non null type '%s'", t)
+		}
+		return v, nil
+	}
+	if t.GetKind() == NonNullKind {
+		if value.Kind() == ast.NullValueKind {
+			return nil, newCoercionError(path, "null value for non null type '%s'", t)
+		}
+		return coerceLiteral(ctx, value, t.(*NonNull).Unwrap(), path)
+	}
+	if value.Kind() == ast.NullValueKind {
+		return nil, nil
+	}
+	switch t := t.(type) {
+	case *List:
+		lv, ok := value.(*ast.ListValue)
+		if !ok {
+			// a single value is coerced to a list of one item
+			item, err := coerceLiteral(ctx, value, t.Unwrap(), path)
+			if err != nil {
+				return nil, err
 			}
-			return res, nil
-		case map[string]interface{}:
-			for fn, field := range o.GetFields() {
-				fv, ok := val[fn]
-				if !ok && field.IsDefaultValueSet() {
-					res[fn] = field.DefaultValue
-				} else if !ok && field.Type.GetKind() == NonNullKind {
-					return nil, fmt.Errorf("No value provided for NonNull type")
+			return []interface{}{item}, nil
+		}
+		res := make([]interface{}, len(lv.Values))
+		for i, v := range lv.Values {
+			item, err := coerceLiteral(ctx, v, t.Unwrap(), appendPath(path, i))
+			if err != nil {
+				return nil, err
+			}
+			res[i] = item
+		}
+		return res, nil
+	case *Scalar:
+		v, err := t.CoerceInputFunc(value.GetValue())
+		if err != nil {
+			return nil, &coercionError{path: path, err: err}
+		}
+		return v, nil
+	case *Enum:
+		if ev, ok := value.(*ast.EnumValue); ok {
+			for _, v := range t.Values {
+				if v.Name == ev.Value {
+					return v.Value, nil
 				}
-				if fv == nil && field.Type.GetKind() != NonNullKind {
-					res[fn] = nil
+			}
+		}
+		return nil, newCoercionError(path, "invalid value for enum '%s'", t.Name)
+	case *InputObject:
+		ov, ok := value.(*ast.ObjectValue)
+		if !ok {
+			return nil, newCoercionError(path, "invalid value for input object '%s'", t.Name)
+		}
+		fields := map[string]*ast.ObjectFieldValue{}
+		for _, f := range ov.Fields {
+			fields[f.Name] = f
+		}
+		if err := checkUnknownFields(t, namesOf(fields), path); err != nil {
+			return nil, err
+		}
+		res := map[string]interface{}{}
+		for _, fn := range sortedNames(namesOf(t.Fields)) {
+			field := t.Fields[fn]
+			f, ok := fields[fn]
+			if ok {
+				if vv, isVariable := f.Value.(*ast.VariableValue); isVariable {
+					_, ok = ctx.variables[vv.Name]
 				}
-				if fv != nil {
-					if cv, err := coerceValue(ctx, fv, field.Type); err == nil {
-						res[fn] = cv
-					} else {
-						return nil, err
-					}
+			}
+			if !ok {
+				if err := coerceAbsentField(res, fn, field, path); err != nil {
+					return nil, err
 				}
+				continue
 			}
-			return res, nil
+			cv, err := coerceLiteral(ctx, f.Value, field.Type, appendPath(path, fn))
+			if err != nil {
+				return nil, err
+			}
+			res[fn] = cv
+		}
+		if t.IsOneOf() {
+			if err := checkOneOfValue(t, res); err != nil {
+				return nil, &coercionError{path: path, err: err}
+			}
+		}
+		return res, nil
+	}
+	return nil, newCoercionError(path, "'%s' is not an input type", t)
+}
+
+// coerceAbsentField sets the default value of the input field that is not provided, or returns
+// an error if the field is non null, otherwise the field is left out of the value
+func coerceAbsentField(res map[string]interface{}, name string, field *InputField, path []interface{}) *coercionError {
+	if field.IsDefaultValueSet() {
+		res[name] = field.DefaultValue
+	} else if field.Type.GetKind() == NonNullKind {
+		return newCoercionError(appendPath(path, name), "no value provided for non null type '%s'", field.Type)
+	}
+	return nil
+}
+
+// checkUnknownFields returns an error for the first field in the value that is not defined on
+// the input object
+func checkUnknownFields(o *InputObject, names []string, path []interface{}) *coercionError {
+	for _, fn := range sortedNames(names) {
+		if _, ok := o.Fields[fn]; !ok {
+			return newCoercionError(appendPath(path, fn), "field '%s' is not defined on '%s'", fn, o.Name)
+		}
+	}
+	return nil
+}
+
+func sortedNames(names []string) []string {
+	sort.Strings(names)
+	return names
+}
+
+// checkOneOfValue checks that exactly one field of the OneOf input object is given, and its
+// value is not null
+func checkOneOfValue(o *InputObject, val map[string]interface{}) error {
+	if len(val) != 1 {
+		return fmt.Errorf("exactly one field must be provided for OneOf input object '%s'", o.Name)
+	}
+	for fn, v := range val {
+		if v == nil {
+			return fmt.Errorf("field '%s' of OneOf input object '%s' must not be null", fn, o.Name)
 		}
 	}
-	return nil, errors.New("invalid object value")
+	return nil
 }
 
-func resolveMetaFields(ctx *gqlCtx, fs []*ast.Field, t Type) (interface{}, bool) {
+func resolveMetaFields(ctx *gqlCtx, path []interface{}, fs []*ast.Field, t Type) (interface{}, bool) {
 	switch fs[0].Name {
 	case "__typename":
 		return t.GetName(), false
+	}
+	if ctx.disableIntrospection {
+		// the documents with introspection fail the validation, this only guards the execution
+		ctx.addErr(newFieldError("introspection is disabled", path, fs))
+		return nil, true
+	}
+	switch fs[0].Name {
 	case "__schema":
-		return completeValue(ctx, []interface{}{}, schemaIntrospection, fs, true)
+		return completeValue(ctx, path, introspectionQuery.Fields["__schema"].Type, fs, ctx.schema)
 	case "__type":
-		return executeField(ctx, []interface{}{}, introspectionQuery, nil, typeIntrospection, fs)
+		// __type is nullable, so an error never propagates to the parent
+		v, _ := executeField(ctx, path, introspectionQuery, nil, typeIntrospection, fs)
+		return v, false
 	}
 	return nil, true
 }
@@ -834,60 +1041,52 @@ func defaultResolver(fname string) Resolver {
 	}
 }
 
-func resolveFieldValue(ctx *gqlCtx, path []interface{}, fast *ast.Field, ot *Object, ov interface{}, fn string, args map[string]interface{}) interface{} {
-	var r Resolver
-	if r = ot.Fields[fn].Resolver; r == nil {
-		r = defaultResolver(fn)
-	}
-
-	// call directives for field definition
-	for _, d := range ot.Fields[fn].Directives {
-		if di, ok := d.(FieldDefinitionDirective); ok {
-			r = di.VisitFieldDefinition(ctx.ctx, *ot.Fields[fn], r)
-		}
-	}
+// resolveFieldValue calls the resolver of the field, the returned bool is false if the resolver returned an error
+// (that is already added to the result), except if it returned partial data
+func resolveFieldValue(ctx *gqlCtx, path []interface{}, fast *ast.Field, ot *Object, ov interface{}, fn string, args map[string]interface{}) (interface{}, bool) {
+	r := ctx.fieldResolver(ot, fn)
 
 	resCtx := &resolveContext{
-		ctx:    ctx.ctx, // this is the original context
-		gqlCtx: ctx,     // execution context
-		args:   args,
-		parent: ov, // parent's value
-		path:   path,
+		ctx:        ctx.ctx, // this is the original context
+		gqlCtx:     ctx,     // execution context
+		args:       args,
+		parent:     ov, // parent's value
+		path:       path,
+		field:      ot.Fields[fn],
+		parentType: ot,
+		fieldAST:   fast,
 	}
 
 	callExtensions(ctx.ctx, ctx.extensions, EventFieldResolverStart, resCtx)
-	v, err := r(resCtx)
+	v, err := callResolver(ctx, r, resCtx)
 	callExtensions(ctx.ctx, ctx.extensions, EventFieldResolverFinish, v)
 	if err != nil {
-		if e, ok := err.(CustomError); ok {
-			ctx.addErr(&Error{
-				Message: e.GetMessage(),
-				Path:    path,
-				Locations: []*ErrorLocation{
-					{
-						Column: fast.Location.Column,
-						Line:   fast.Location.Line,
-					},
-				},
-				Extensions: e.GetExtensions(),
-			})
-		} else {
-			ctx.addErr(&Error{
-				Message: err.Error(),
-				Path:    path,
-				Locations: []*ErrorLocation{
-					{
-						Column: fast.Location.Column,
-						Line:   fast.Location.Line,
-					},
-				},
-			})
+		errs, partial := splitError(err)
+		for _, e := range errs {
+			ctx.addErr(ctx.presentError(e, path, fast))
+		}
+		if !partial {
+			return nil, false
 		}
-		v = nil
-	} else if ot.Fields[fn].Type.GetKind() == NonNullKind && v == nil && err == nil {
-		ctx.addErr(&Error{Message: "null value on a NonNull field", Path: path})
 	}
-	return v
+	return v, true
+}
+
+// callResolver calls the resolver and recovers if it panics
+func callResolver(ctx *gqlCtx, r Resolver, resCtx *resolveContext) (v interface{}, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			recoverFunc := ctx.recoverFunc
+			if recoverFunc == nil {
+				recoverFunc = DefaultRecoverFunc
+			}
+			v, err = nil, recoverFunc(ctx.ctx, p)
+			if err == nil {
+				err = errors.New(errInternalServerError)
+			}
+		}
+	}()
+	return r(resCtx)
 }
 
 func isNil(i interface{}) bool {
@@ -901,20 +1100,19 @@ func isNil(i interface{}) bool {
 	return false
 }
 
-// returns the completed value and a bool value if there is a NonNull error
+// completeValue completes the result of a field according to its type. The returned bool is true if
+// the value is null because of an error that is already added to the result. Whoever holds the
+// value decides if it has to propagate further: lists and selection sets propagate it to their
+// own parent only if the type of the item or the field is NonNull.
 func completeValue(ctx *gqlCtx, path []interface{}, ft Type, fs ast.Fields, result interface{}) (interface{}, bool) {
 	if ft.GetKind() == NonNullKind {
 		// Step 1 - NonNull kinds
 		rval, hasErr := completeValue(ctx, path, ft.(*NonNull).Unwrap(), fs, result)
-		if hasErr || rval == nil {
+		if hasErr {
+			return nil, true
+		} else if rval == nil {
+			ctx.addErr(newFieldError(errNullValueOnNonNull, path, fs))
 			return nil, true
-		} else if ft.(*NonNull).Unwrap().GetKind() == ListKind {
-			v := reflect.ValueOf(rval)
-			for i := 0; i < v.Len(); i++ {
-				if v.Index(i).Interface() == nil {
-					return nil, true
-				}
-			}
 		}
 		return rval, false
 	} else if isNil(result) {
@@ -924,60 +1122,56 @@ func completeValue(ctx *gqlCtx, path []interface{}, ft Type, fs ast.Fields, resu
 		// Step 3 - go through the list and complete each value, then return result
 		lt := ft.(*List)
 		v := reflect.ValueOf(result)
+		if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+			ctx.addErr(newFieldError(fmt.Sprintf(errListValueExpected, v.Kind()), path, fs))
+			return nil, true
+		}
 		res := make([]interface{}, v.Len())
+		// if an item is null because of an error, and the items are NonNull, the whole list is null
+		hasNullErrs := false
+		mu := sync.Mutex{}
+		complete := func(i int) {
+			rval, hasErr := completeValue(ctx, appendPath(path, i), lt.Unwrap(), fs, v.Index(i).Interface())
+			mu.Lock()
+			if hasErr && lt.Unwrap().GetKind() == NonNullKind {
+				hasNullErrs = true
+			}
+			res[i] = rval
+			mu.Unlock()
+		}
 		if ctx.concurrency {
 			wg := sync.WaitGroup{}
 			wg.Add(v.Len())
-			//mu := sync.Mutex{}
 			for i := 0; i < v.Len(); i++ {
 				select {
 				case ctx.sem <- struct{}{}:
 					i := i
 					go func() {
-						rval, hasErr := completeValue(ctx, append(path, i), lt.Unwrap(), fs, v.Index(i).Interface())
-						if hasErr {
-							//mu.Lock()
-							res[i] = nil
-							//mu.Unlock()
-						} else {
-							//mu.Lock()
-							res[i] = rval
-							//mu.Unlock()
-						}
+						complete(i)
 						<-ctx.sem
 						wg.Done()
 					}()
 				default:
-					rval, hasErr := completeValue(ctx, append(path, i), lt.Unwrap(), fs, v.Index(i).Interface())
-					if hasErr {
-						//mu.Lock()
-						res[i] = nil
-						//mu.Unlock()
-					} else {
-						//mu.Lock()
-						res[i] = rval
-						//mu.Unlock()
-					}
+					complete(i)
 					wg.Done()
 				}
 			}
 			wg.Wait()
 		} else {
 			for i := 0; i < v.Len(); i++ {
-				rval, hasErr := completeValue(ctx, append(path, i), lt.Unwrap(), fs, v.Index(i).Interface())
-				if hasErr {
-					res[i] = nil
-				} else {
-					res[i] = rval
-				}
+				complete(i)
 			}
 		}
+		if hasNullErrs {
+			return nil, true
+		}
 		return res, false
 	} else if ft.GetKind() == ScalarKind {
 		// Step 4.1 - coerce scalar value
 		res, err := ft.(*Scalar).CoerceResult(result)
 		if err != nil {
-			ctx.addErr(&Error{Message: err.Error(), Path: path})
+			ctx.addErr(newFieldError(err.Error(), path, fs))
+			return nil, true
 		}
 		return res, false
 	} else if ft.GetKind() == EnumKind {
@@ -987,22 +1181,55 @@ func completeValue(ctx *gqlCtx, path []interface{}, ft Type, fs ast.Fields, resu
 				return ev.Name, false
 			}
 		}
-		ctx.addErr(&Error{Message: "invalid result", Path: path})
-		return nil, false
+		ctx.addErr(newFieldError(fmt.Sprintf(errInvalidEnumResult, ft.GetName()), path, fs))
+		return nil, true
 	} else if ft.GetKind() == ObjectKind {
-		return executeSelectionSet(ctx, path, fs[0].SelectionSet, ft.(*Object), result)
+		return executeSelectionSet(ctx, path, mergeSelectionSets(fs), ft.(*Object), result)
 	} else if ft.GetKind() == InterfaceKind {
 		ot := ft.(*Interface).Resolve(ctx.ctx, result)
-		subSel := fs[0].SelectionSet
-		return executeSelectionSet(ctx, path, subSel, ot, result)
+		if ot == nil {
+			ctx.addErr(newFieldError(fmt.Sprintf(errAbstractTypeNotResolved, ft.GetName()), path, fs))
+			return nil, true
+		}
+		return executeSelectionSet(ctx, path, mergeSelectionSets(fs), ot, result)
 	} else if ft.GetKind() == UnionKind {
 		ot := ft.(*Union).Resolve(ctx.ctx, result)
-		subSel := fs[0].SelectionSet
-		return executeSelectionSet(ctx, path, subSel, ot, result)
+		if ot == nil {
+			ctx.addErr(newFieldError(fmt.Sprintf(errAbstractTypeNotResolved, ft.GetName()), path, fs))
+			return nil, true
+		}
+		return executeSelectionSet(ctx, path, mergeSelectionSets(fs), ot, result)
 	}
 	return nil, true
 }
 
+// mergeSelectionSets merges the selection sets of the fields with the same response key
+func mergeSelectionSets(fs ast.Fields) []ast.Selection {
+	if len(fs) == 1 {
+		return fs[0].SelectionSet
+	}
+	ss := []ast.Selection{}
+	for _, f := range fs {
+		ss = append(ss, f.SelectionSet...)
+	}
+	return ss
+}
+
+// newFieldError creates an Error for a field with its path and location, for the errors while
+// completing the value of the field
+func newFieldError(msg string, path []interface{}, fs ast.Fields) *Error {
+	return withCode(&Error{
+		Message: msg,
+		Path:    path,
+		Locations: []*ErrorLocation{
+			{
+				Column: fs[0].Location.Column,
+				Line:   fs[0].Location.Line,
+			},
+		},
+	}, ErrInternalServerError)
+}
+
 func getTypes(s *Schema) (map[string]Type, map[string]Directive, map[string][]Type) {
 	types := map[string]Type{
 		"String":   String,
@@ -1016,6 +1243,7 @@ func getTypes(s *Schema) (map[string]Type, map[string]Directive, map[string][]Ty
 		"skip":       skipDirective,
 		"include":    includeDirective,
 		"deprecated": deprecatedDirective,
+		"oneOf":      oneOfDirective,
 	}
 	implementors := map[string][]Type{}
 	addIntrospectionTypes(types)
@@ -1144,7 +1372,7 @@ func gatherDirectives(directives map[string]TypeSystemDirective, t Type) {
 			}
 		}
 		for _, v := range t.(*InputObject).GetFields() {
-			for _, d := range v.Directives {
+			for _, d := range withConstraints(v.Directives, v.Type, v.Constraints) {
 				if _, ok := directives[d.GetName()]; !ok {
 					directives[d.GetName()] = d
 				}
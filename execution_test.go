@@ -0,0 +1,825 @@
+package gql_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/rigglo/gql"
+	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/testutil"
+)
+
+var (
+	nullTestSchema = &gql.Schema{
+		Query: nullTestQuery,
+	}
+
+	nullTestQuery = &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"data": &gql.Field{
+				Type: nullTestDataType,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return struct{}{}, nil
+				},
+			},
+			"nonNullData": &gql.Field{
+				Type: gql.NewNonNull(nullTestDataType),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return struct{}{}, nil
+				},
+			},
+		},
+	}
+
+	nullTestDataType = &gql.Object{
+		Name: "DataType",
+		Fields: gql.Fields{
+			"sync": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, nil
+				},
+			},
+			"syncNonNull": &gql.Field{
+				Type: gql.NewNonNull(gql.String),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, nil
+				},
+			},
+			"syncError": &gql.Field{
+				Type: gql.String,
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, errors.New("sync error")
+				},
+			},
+			"syncNonNullError": &gql.Field{
+				Type: gql.NewNonNull(gql.String),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return nil, errors.New("sync non-null error")
+				},
+			},
+			"list": &gql.Field{
+				Type: gql.NewList(gql.String),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return []interface{}{"a", nil, "c"}, nil
+				},
+			},
+			"listNN": &gql.Field{
+				Type: gql.NewList(gql.NewNonNull(gql.String)),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return []interface{}{"a", nil, "c"}, nil
+				},
+			},
+			"nnList": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.String)),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return []interface{}{"a", nil, "c"}, nil
+				},
+			},
+			"nnListNN": &gql.Field{
+				Type: gql.NewNonNull(gql.NewList(gql.NewNonNull(gql.String))),
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return []interface{}{"a", nil, "c"}, nil
+				},
+			},
+		},
+	}
+)
+
+func init() {
+	nullTestDataType.AddField("nest", &gql.Field{
+		Type: nullTestDataType,
+		Resolver: func(ctx gql.Context) (interface{}, error) {
+			return struct{}{}, nil
+		},
+	})
+	nullTestDataType.AddField("nonNullNest", &gql.Field{
+		Type: gql.NewNonNull(nullTestDataType),
+		Resolver: func(ctx gql.Context) (interface{}, error) {
+			return struct{}{}, nil
+		},
+	})
+	nullTestDataType.AddField("nestList", &gql.Field{
+		Type: gql.NewList(nullTestDataType),
+		Resolver: func(ctx gql.Context) (interface{}, error) {
+			return []interface{}{struct{}{}, struct{}{}}, nil
+		},
+	})
+}
+
+func Test_NullPropagation(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		query  string
+		data   string
+		errors []string
+	}{
+		{
+			name:   "nullableField",
+			query:  `{ data { sync } }`,
+			data:   `{"data":{"sync":null}}`,
+			errors: []string{},
+		},
+		{
+			name:   "nonNullFieldNullsParent",
+			query:  `{ data { syncNonNull } }`,
+			data:   `{"data":null}`,
+			errors: []string{"[data syncNonNull]"},
+		},
+		{
+			name:   "siblingsArePreserved",
+			query:  `{ data { sync nest { syncNonNull } } }`,
+			data:   `{"data":{"nest":null,"sync":null}}`,
+			errors: []string{"[data nest syncNonNull]"},
+		},
+		{
+			name:   "propagatesThroughNonNullParents",
+			query:  `{ data { nonNullNest { nonNullNest { syncNonNull } } } }`,
+			data:   `{"data":null}`,
+			errors: []string{"[data nonNullNest nonNullNest syncNonNull]"},
+		},
+		{
+			name:   "propagatesToRoot",
+			query:  `{ nonNullData { nonNullNest { syncNonNull } } }`,
+			data:   `null`,
+			errors: []string{"[nonNullData nonNullNest syncNonNull]"},
+		},
+		{
+			name:   "nullableErrorField",
+			query:  `{ data { syncError sync } }`,
+			data:   `{"data":{"sync":null,"syncError":null}}`,
+			errors: []string{"[data syncError]"},
+		},
+		{
+			name:   "nonNullErrorFieldReportsOnce",
+			query:  `{ data { syncNonNullError } }`,
+			data:   `{"data":null}`,
+			errors: []string{"[data syncNonNullError]"},
+		},
+		{
+			name:   "nullableListItems",
+			query:  `{ data { list nnList } }`,
+			data:   `{"data":{"list":["a",null,"c"],"nnList":["a",null,"c"]}}`,
+			errors: []string{},
+		},
+		{
+			name:   "nonNullListItemNullsList",
+			query:  `{ data { listNN } }`,
+			data:   `{"data":{"listNN":null}}`,
+			errors: []string{"[data listNN 1]"},
+		},
+		{
+			name:   "nonNullListItemInNonNullList",
+			query:  `{ data { nnListNN } }`,
+			data:   `{"data":null}`,
+			errors: []string{"[data nnListNN 1]"},
+		},
+		{
+			name:   "nullableListOfObjects",
+			query:  `{ data { nestList { syncNonNull } } }`,
+			data:   `{"data":{"nestList":[null,null]}}`,
+			errors: []string{"[data nestList 0 syncNonNull]", "[data nestList 1 syncNonNull]"},
+		},
+		{
+			name:   "aliasesInPath",
+			query:  `{ d: data { n: nest { s: syncNonNull } } }`,
+			data:   `{"d":{"n":null}}`,
+			errors: []string{"[d n s]"},
+		},
+	}
+	for _, tt := range tests {
+		for _, goroutines := range []bool{false, true} {
+			t.Run(fmt.Sprintf("%s/goroutines=%v", tt.name, goroutines), func(t *testing.T) {
+				e := gql.NewExecutor(gql.ExecutorConfig{
+					Schema:           nullTestSchema,
+					EnableGoroutines: goroutines,
+					GoroutineLimit:   10,
+				})
+				r := e.Execute(ctx, gql.Params{Query: tt.query})
+				bs, err := json.Marshal(r.Data)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(bs) != tt.data {
+					t.Fatalf("expected data %s, got %s", tt.data, string(bs))
+				}
+				paths := []string{}
+				for _, e := range r.Errors {
+					if len(e.Locations) != 1 {
+						t.Fatalf("expected a location for error '%s', got %v", e.Message, e.Locations)
+					}
+					paths = append(paths, fmt.Sprint(e.Path))
+				}
+				sort.Strings(paths)
+				if fmt.Sprint(paths) != fmt.Sprint(tt.errors) {
+					t.Fatalf("expected errors on %v, got %v", tt.errors, paths)
+				}
+			})
+		}
+	}
+}
+
+func Test_SubscriptionNullPropagation(t *testing.T) {
+	events := func(ctx gql.Context) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- struct{}{}
+		close(ch)
+		return ch, nil
+	}
+	e := gql.NewExecutor(gql.ExecutorConfig{
+		Schema: &gql.Schema{
+			Query: nullTestQuery,
+			Subscription: &gql.Object{
+				Name: "Subscription",
+				Fields: gql.Fields{
+					"data": &gql.Field{
+						Type:     nullTestDataType,
+						Resolver: events,
+					},
+					"nonNullData": &gql.Field{
+						Type:     gql.NewNonNull(nullTestDataType),
+						Resolver: events,
+					},
+				},
+			},
+		},
+	})
+	tests := []struct {
+		name  string
+		query string
+		data  string
+		path  string
+	}{
+		{
+			name:  "nullableField",
+			query: `subscription { data { syncNonNullError } }`,
+			data:  `{"data":null}`,
+			path:  "[data syncNonNullError]",
+		},
+		{
+			name:  "nonNullField",
+			query: `subscription { nonNullData { syncNonNullError } }`,
+			data:  `null`,
+			path:  "[nonNullData syncNonNullError]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, err := e.Subscribe(context.Background(), tt.query, "", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			r := (<-ch).(*gql.Result)
+			bs, err := json.Marshal(r.Data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(bs) != tt.data {
+				t.Fatalf("expected data %s, got %s", tt.data, string(bs))
+			}
+			if len(r.Errors) != 1 || fmt.Sprint(r.Errors[0].Path) != tt.path {
+				t.Fatalf("expected an error on %s, got %+v", tt.path, r.Errors)
+			}
+		})
+	}
+}
+
+func Test_Middlewares(t *testing.T) {
+	ctx := context.Background()
+	calls := []string{}
+	mu := sync.Mutex{}
+	record := func(name string) gql.Middleware {
+		return func(next gql.Resolver) gql.Resolver {
+			return func(c gql.Context) (interface{}, error) {
+				mu.Lock()
+				calls = append(calls, fmt.Sprintf("%s:%s.%v", name, c.ParentType().Name, c.Path()))
+				mu.Unlock()
+				return next(c)
+			}
+		}
+	}
+	upper := func(next gql.Resolver) gql.Resolver {
+		return func(c gql.Context) (interface{}, error) {
+			v, err := next(c)
+			if s, ok := v.(string); ok && c.Field().Type.String() == "String!" {
+				return s + "!", err
+			}
+			return v, err
+		}
+	}
+	e := gql.NewExecutor(gql.ExecutorConfig{
+		Schema:      testutil.Schema,
+		Middlewares: []gql.Middleware{record("first"), record("second"), upper},
+	})
+	for i := 0; i < 2; i++ {
+		calls = []string{}
+		r := e.Execute(ctx, gql.Params{Query: `{ dog { name } }`})
+		if len(r.Errors) != 0 {
+			t.Fatalf("unexpected errors: %v", r.Errors)
+		}
+		if name := r.Data["dog"].(map[string]interface{})["name"]; name != "Doggo!" {
+			t.Fatalf("expected the default resolver to be wrapped, got %v", name)
+		}
+		expected := "[first:Query.[dog] second:Query.[dog] first:Dog.[dog name] second:Dog.[dog name]]"
+		if fmt.Sprint(calls) != expected {
+			t.Fatalf("expected calls %s, got %v", expected, calls)
+		}
+	}
+}
+
+func Test_ResolveInfo(t *testing.T) {
+	var info gql.Context
+	query := &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"info": &gql.Field{
+				Type: gql.NewNonNull(gql.String),
+				Arguments: gql.Arguments{
+					"x": &gql.Argument{
+						Type: gql.Int,
+					},
+				},
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					info = ctx
+					return "info", nil
+				},
+			},
+		},
+	}
+	schema := &gql.Schema{Query: query}
+	r := gql.Execute(context.Background(), schema, gql.Params{
+		Query:     `query Named($v: Int) { a: info(x: $v) @include(if: true) }`,
+		Variables: map[string]interface{}{"v": 42},
+	})
+	if len(r.Errors) != 0 {
+		t.Fatalf("unexpected errors: %v", r.Errors)
+	}
+	switch {
+	case info.Field() != query.Fields["info"]:
+		t.Fatal("invalid field definition")
+	case info.ParentType() != query:
+		t.Fatal("invalid parent type")
+	case info.ReturnType().String() != "String!":
+		t.Fatalf("invalid return type: %v", info.ReturnType())
+	case info.FieldAST().Alias != "a" || info.FieldAST().Name != "info" || len(info.FieldAST().Directives) != 1:
+		t.Fatalf("invalid field ast: %+v", info.FieldAST())
+	case info.Operation().Name != "Named":
+		t.Fatalf("invalid operation: %+v", info.Operation())
+	case info.Variables()["v"] != 42:
+		t.Fatalf("invalid variables: %v", info.Variables())
+	case info.Schema() != schema:
+		t.Fatal("invalid schema")
+	}
+}
+
+func Test_ParserOptions(t *testing.T) {
+	ctx := context.Background()
+	e := gql.NewExecutor(gql.ExecutorConfig{
+		Schema: testutil.Schema,
+		ParserOptions: parser.Options{
+			MaxTokens: 20,
+			MaxDepth:  3,
+		},
+	})
+	tests := []struct {
+		name    string
+		query   string
+		message string
+	}{
+		{
+			name:  "WithinLimits",
+			query: `{ dog { owner { name } } }`,
+		},
+		{
+			name:    "TooDeep",
+			query:   `{ dog { owner { pets { name } } } }`,
+			message: "Syntax Error: Document exceeds the maximum depth of 3, parsing aborted.",
+		},
+		{
+			name:    "TooManyTokens",
+			query:   `{ dog { name nickname barkVolume name nickname barkVolume name nickname barkVolume name nickname barkVolume name nickname barkVolume name nickname barkVolume } }`,
+			message: "Syntax Error: Document contains more than 20 tokens, parsing aborted.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := e.Execute(ctx, gql.Params{Query: tt.query})
+			if tt.message == "" {
+				if len(r.Errors) != 0 {
+					t.Fatalf("unexpected errors: %v", r.Errors)
+				}
+				return
+			}
+			if len(r.Errors) != 1 || r.Errors[0].Message != tt.message {
+				t.Fatalf("expected error '%s', got %v", tt.message, r.Errors)
+			}
+		})
+	}
+}
+
+var oneOfTestSchema = &gql.Schema{
+	Query: &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"user": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"by": &gql.Argument{
+						Type: gql.NewNonNull(&gql.InputObject{
+							Name:       "UserBy",
+							Directives: gql.TypeSystemDirectives{gql.OneOfInput()},
+							Fields: gql.InputFields{
+								"id":    &gql.InputField{Type: gql.ID},
+								"email": &gql.InputField{Type: gql.String},
+							},
+						}),
+					},
+				},
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					return fmt.Sprint(ctx.Args()["by"]), nil
+				},
+			},
+		},
+	},
+}
+
+func Test_OneOfInput(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		expected  interface{}
+		message   string
+	}{
+		{
+			name:     "Literal",
+			query:    `{ user(by: { id: "1" }) }`,
+			expected: "map[id:1]",
+		},
+		{
+			name:      "FieldVariable",
+			query:     `query($email: String!) { user(by: { email: $email }) }`,
+			variables: map[string]interface{}{"email": "a@b.c"},
+			expected:  "map[email:a@b.c]",
+		},
+		{
+			name:      "ObjectVariable",
+			query:     `query($by: UserBy!) { user(by: $by) }`,
+			variables: map[string]interface{}{"by": map[string]interface{}{"email": "a@b.c"}},
+			expected:  "map[email:a@b.c]",
+		},
+		{
+			name:      "ObjectVariableWithMultipleFields",
+			query:     `query($by: UserBy!) { user(by: $by) }`,
+			variables: map[string]interface{}{"by": map[string]interface{}{"id": "1", "email": "a@b.c"}},
+			message:   "exactly one field must be provided for OneOf input object 'UserBy'",
+		},
+		{
+			name:      "ObjectVariableWithNullField",
+			query:     `query($by: UserBy!) { user(by: $by) }`,
+			variables: map[string]interface{}{"by": map[string]interface{}{"id": nil}},
+			message:   "field 'id' of OneOf input object 'UserBy' must not be null",
+		},
+		{
+			name:    "LiteralWithMultipleFields",
+			query:   `{ user(by: { id: "1", email: "a@b.c" }) }`,
+			message: "exactly one field must be provided for OneOf input object 'UserBy'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.Execute(context.Background(), oneOfTestSchema, gql.Params{Query: tt.query, Variables: tt.variables})
+			if tt.message == "" {
+				if len(r.Errors) != 0 || r.Data["user"] != tt.expected {
+					t.Fatalf("expected %v, got %v %v", tt.expected, r.Data, r.Errors)
+				}
+				return
+			}
+			if len(r.Errors) != 1 || !strings.Contains(r.Errors[0].Message, tt.message) {
+				t.Fatalf("expected error '%s', got %v", tt.message, r.Errors)
+			}
+		})
+	}
+}
+
+func Test_OneOfInputSchema(t *testing.T) {
+	if err := oneOfTestSchema.ValidateSchema(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sdl := oneOfTestSchema.SDL()
+	if !strings.Contains(sdl, "input UserBy @oneOf {") || strings.Contains(sdl, "directive @oneOf") {
+		t.Fatalf("expected the @oneOf directive on UserBy, got\n%s", sdl)
+	}
+
+	r := gql.Execute(context.Background(), oneOfTestSchema, gql.Params{
+		Query: `{ userBy: __type(name: "UserBy") { isOneOf } string: __type(name: "String") { isOneOf } }`,
+	})
+	expected := map[string]interface{}{
+		"userBy": map[string]interface{}{"isOneOf": true},
+		"string": map[string]interface{}{"isOneOf": nil},
+	}
+	if len(r.Errors) != 0 || fmt.Sprint(r.Data) != fmt.Sprint(expected) {
+		t.Fatalf("expected %v, got %v %v", expected, r.Data, r.Errors)
+	}
+
+	invalid := &gql.Schema{
+		Query: &gql.Object{
+			Name: "Query",
+			Fields: gql.Fields{
+				"user": &gql.Field{
+					Type: gql.String,
+					Arguments: gql.Arguments{
+						"by": &gql.Argument{
+							Type: &gql.InputObject{
+								Name:       "UserBy",
+								Directives: gql.TypeSystemDirectives{gql.OneOfInput()},
+								Fields: gql.InputFields{
+									"id":    &gql.InputField{Type: gql.NewNonNull(gql.ID)},
+									"email": &gql.InputField{Type: gql.String, DefaultValue: "a@b.c"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	err := invalid.ValidateSchema()
+	expectedErr := "field 'UserBy.email' of a OneOf input object must not have a default value; field 'UserBy.id' of a OneOf input object must be nullable"
+	if err == nil || err.Error() != expectedErr {
+		t.Fatalf("expected error '%s', got %v", expectedErr, err)
+	}
+
+	r = gql.NewExecutor(gql.ExecutorConfig{Schema: invalid}).Execute(context.Background(), gql.Params{Query: `{ user }`})
+	if r.Data != nil || gql.Errors(r.Errors).Error() != expectedErr || !errors.Is(r.Errors[0], gql.ErrInternalServerError) {
+		t.Fatalf("expected the schema errors for the request, got %v %v", r.Data, r.Errors)
+	}
+}
+
+var coercionTestItem = &gql.InputObject{
+	Name: "Item",
+	Fields: gql.InputFields{
+		"name": &gql.InputField{
+			Type: gql.NewNonNull(gql.String),
+		},
+		"price": &gql.InputField{
+			Type: gql.Int,
+		},
+		"amount": &gql.InputField{
+			Type:         gql.Int,
+			DefaultValue: 1,
+		},
+	},
+}
+
+var coercionTestSchema = &gql.Schema{
+	Query: &gql.Object{
+		Name: "Query",
+		Fields: gql.Fields{
+			"args": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"input": &gql.Argument{
+						Type: &gql.InputObject{
+							Name: "Order",
+							Fields: gql.InputFields{
+								"note": &gql.InputField{
+									Type: gql.String,
+								},
+								"items": &gql.InputField{
+									Type: gql.NewList(gql.NewNonNull(coercionTestItem)),
+								},
+							},
+						},
+					},
+					"tags": &gql.Argument{
+						Type: gql.NewList(gql.String),
+					},
+					"color": &gql.Argument{
+						Type: &gql.Enum{
+							Name: "Color",
+							Values: gql.EnumValues{
+								&gql.EnumValue{Name: "RED", Value: 0},
+								&gql.EnumValue{Name: "BLUE", Value: 1},
+							},
+						},
+					},
+				},
+				Resolver: func(ctx gql.Context) (interface{}, error) {
+					bs, err := json.Marshal(ctx.Args())
+					return string(bs), err
+				},
+			},
+		},
+	},
+}
+
+func Test_InputCoercion(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		expected  string
+		message   string
+		path      []interface{}
+	}{
+		{
+			name:     "AbsentAndNull",
+			query:    `{ args(input: { note: null }) }`,
+			expected: `{"input":{"note":null}}`,
+		},
+		{
+			name:      "AbsentVariable",
+			query:     `query($note: String, $tags: [String]) { args(input: { note: $note }, tags: $tags) }`,
+			variables: map[string]interface{}{"tags": nil},
+			expected:  `{"input":{},"tags":null}`,
+		},
+		{
+			name:     "ListOfInputObjects",
+			query:    `{ args(input: { items: [{ name: "a", price: 2 }, { name: "b", amount: 3 }] }) }`,
+			expected: `{"input":{"items":[{"amount":1,"name":"a","price":2},{"amount":3,"name":"b"}]}}`,
+		},
+		{
+			name:      "VariablesInList",
+			query:     `query($a: String, $b: String) { args(tags: [$a, $b, null]) }`,
+			variables: map[string]interface{}{"a": "x"},
+			expected:  `{"tags":["x",null,null]}`,
+		},
+		{
+			name:      "SingleValueAsList",
+			query:     `query($tags: [String]) { args(tags: $tags) }`,
+			variables: map[string]interface{}{"tags": "x"},
+			expected:  `{"tags":["x"]}`,
+		},
+		{
+			name:     "SingleLiteralAsList",
+			query:    `{ args(tags: "x", color: BLUE) }`,
+			expected: `{"color":1,"tags":["x"]}`,
+		},
+		{
+			name:  "NestedVariableError",
+			query: `query($input: Order) { args(input: $input) }`,
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{
+					"items": []interface{}{
+						map[string]interface{}{"name": "a"},
+						map[string]interface{}{"name": "b"},
+						map[string]interface{}{"name": "c", "price": "free"},
+					},
+				},
+			},
+			message: "invalid value for variable '$input.items[2].price': invalid int32 value",
+			path:    []interface{}{"input", "items", 2, "price"},
+		},
+		{
+			name:  "MissingNonNullField",
+			query: `query($input: Order) { args(input: $input) }`,
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{
+					"items": []interface{}{map[string]interface{}{"price": 1}},
+				},
+			},
+			message: "invalid value for variable '$input.items[0].name': no value provided for non null type 'String!'",
+			path:    []interface{}{"input", "items", 0, "name"},
+		},
+		{
+			name:  "NullListItem",
+			query: `query($input: Order) { args(input: $input) }`,
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{"items": []interface{}{nil}},
+			},
+			message: "invalid value for variable '$input.items[0]': null value for non null type 'Item!'",
+			path:    []interface{}{"input", "items", 0},
+		},
+		{
+			name:  "UnknownField",
+			query: `query($input: Order) { args(input: $input) }`,
+			variables: map[string]interface{}{
+				"input": map[string]interface{}{"notes": "x"},
+			},
+			message: "invalid value for variable '$input.notes': field 'notes' is not defined on 'Order'",
+			path:    []interface{}{"input", "notes"},
+		},
+		{
+			name:      "InvalidEnum",
+			query:     `query($color: Color) { args(color: $color) }`,
+			variables: map[string]interface{}{"color": "GREEN"},
+			message:   "invalid value for variable '$color': invalid value for enum 'Color'",
+			path:      []interface{}{"color"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.Execute(context.Background(), coercionTestSchema, gql.Params{Query: tt.query, Variables: tt.variables})
+			if tt.message == "" {
+				if len(r.Errors) != 0 || r.Data["args"] != tt.expected {
+					t.Fatalf("expected %v, got %v %v", tt.expected, r.Data["args"], r.Errors)
+				}
+				return
+			}
+			if len(r.Errors) != 1 || r.Errors[0].Message != tt.message {
+				t.Fatalf("expected error '%s', got %v", tt.message, r.Errors)
+			}
+			if !errors.Is(r.Errors[0], gql.ErrBadUserInput) {
+				t.Fatalf("expected a bad user input error, got %v", r.Errors[0].Extensions)
+			}
+			if fmt.Sprint(r.Errors[0].Extensions["variablePath"]) != fmt.Sprint(tt.path) {
+				t.Fatalf("expected variable path %v, got %v", tt.path, r.Errors[0].Extensions["variablePath"])
+			}
+		})
+	}
+}
+
+type introspectionTestKey struct{}
+
+func Test_IntrospectionPolicy(t *testing.T) {
+	employee := context.WithValue(context.Background(), introspectionTestKey{}, true)
+	tests := []struct {
+		name     string
+		policy   gql.IntrospectionPolicy
+		ctx      context.Context
+		query    string
+		messages []string
+	}{
+		{
+			name:  "AllowedByDefault",
+			ctx:   context.Background(),
+			query: `{ __schema { queryType { name } } __type(name: "Dog") { name } }`,
+		},
+		{
+			name:   "Always",
+			policy: gql.IntrospectionAlways,
+			ctx:    context.Background(),
+			query:  `{ __schema { queryType { name } } }`,
+		},
+		{
+			name:   "Never",
+			policy: gql.IntrospectionNever,
+			ctx:    context.Background(),
+			query:  `{ __schema { queryType { name } } __type(name: "Dog") { name } }`,
+			messages: []string{
+				"introspection is disabled, but the query contains the field '__schema'",
+				"introspection is disabled, but the query contains the field '__type'",
+			},
+		},
+		{
+			name:   "TypenameIsAllowed",
+			policy: gql.IntrospectionNever,
+			ctx:    context.Background(),
+			query:  `{ __typename }`,
+		},
+		{
+			name:     "NoSuggestions",
+			policy:   gql.IntrospectionNever,
+			ctx:      context.Background(),
+			query:    `{ dgo { name } }`,
+			messages: []string{"Field 'dgo' does not exist on type 'Query'"},
+		},
+		{
+			name: "FuncAllowed",
+			policy: func(ctx context.Context) bool {
+				return ctx.Value(introspectionTestKey{}) == true
+			},
+			ctx:   employee,
+			query: `{ __schema { queryType { name } } }`,
+		},
+		{
+			name: "FuncDenied",
+			policy: func(ctx context.Context) bool {
+				return ctx.Value(introspectionTestKey{}) == true
+			},
+			ctx:      context.Background(),
+			query:    `{ __schema { queryType { name } } }`,
+			messages: []string{"introspection is disabled, but the query contains the field '__schema'"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := gql.NewExecutor(gql.ExecutorConfig{
+				Schema:              testutil.Schema,
+				Introspection:       tt.policy,
+				ValidationCacheSize: 10,
+			})
+			// the second execution uses the cached validation
+			for i := 0; i < 2; i++ {
+				r := e.Execute(tt.ctx, gql.Params{Query: tt.query})
+				if len(r.Errors) != len(tt.messages) {
+					t.Fatalf("expected errors %v, got %v", tt.messages, r.Errors)
+				}
+				for i, err := range r.Errors {
+					if err.Message != tt.messages[i] || !errors.Is(err, gql.ErrValidationFailed) {
+						t.Fatalf("expected validation error '%s', got '%s' %v", tt.messages[i], err.Message, err.Extensions)
+					}
+				}
+			}
+		})
+	}
+}
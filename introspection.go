@@ -1,9 +1,67 @@
 package gql
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/visitor"
 )
 
+/*
+IntrospectionPolicy decides if the introspection (the __schema and __type fields) is allowed for
+a request, from the context of the request. It can be IntrospectionAlways, IntrospectionNever or
+a custom function, like one that only allows it for the authenticated employees.
+
+	e := gql.NewExecutor(gql.ExecutorConfig{
+		Schema: schema,
+		Introspection: func(ctx context.Context) bool {
+			u, ok := ctx.Value(userKey).(*User)
+			return ok && u.IsEmployee
+		},
+	})
+
+If it's denied, the document fails the validation and the errors have no suggestions, since
+they would reveal the names in the schema.
+*/
+type IntrospectionPolicy func(ctx context.Context) bool
+
+/*
+IntrospectionAlways allows the introspection for every request, it's the default policy
+*/
+func IntrospectionAlways(ctx context.Context) bool {
+	return true
+}
+
+/*
+IntrospectionNever denies the introspection for every request
+*/
+func IntrospectionNever(ctx context.Context) bool {
+	return false
+}
+
+// noIntrospectionRule reports the introspection fields, it's used when the introspection is
+// denied, regardless of the validation rules
+var noIntrospectionRule = &ValidationRule{
+	Name: "NoIntrospection",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.Field)
+						if f.Name == "__schema" || f.Name == "__type" {
+							ctx.ReportError(newValidationError(fmt.Sprintf("introspection is disabled, but the query contains the field '%s'", f.Name), f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
 func init() {
 	typeIntrospection.AddField(
 		"interfaces",
@@ -296,6 +354,15 @@ var (
 					return nil, nil
 				},
 			},
+			"isOneOf": &Field{
+				Type: Boolean,
+				Resolver: func(ctx Context) (interface{}, error) {
+					if o, ok := ctx.Parent().(*InputObject); ok {
+						return o.IsOneOf(), nil
+					}
+					return nil, nil
+				},
+			},
 			"inputFields": &Field{
 				Type: NewList(NewNonNull(inputValueIntrospection)),
 				Resolver: func(ctx Context) (interface{}, error) {
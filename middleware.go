@@ -0,0 +1,67 @@
+package gql
+
+/*
+Middleware wraps a field resolver, it can run code before and after the next resolver in the chain
+or skip it entirely. The definition of the field and its parent type are available through the Context.
+
+	func logger(next gql.Resolver) gql.Resolver {
+		return func(ctx gql.Context) (interface{}, error) {
+			start := time.Now()
+			v, err := next(ctx)
+			log.Printf("%s.%v took %v", ctx.ParentType().Name, ctx.Path(), time.Since(start))
+			return v, err
+		}
+	}
+*/
+type Middleware func(next Resolver) Resolver
+
+type fieldKey struct {
+	parent *Object
+	name   string
+}
+
+// fieldResolver returns the resolver of a field wrapped by the middlewares of the executor,
+// the composed chain is built only once for every field
+func (c *gqlCtx) fieldResolver(ot *Object, fn string) Resolver {
+	key := fieldKey{ot, fn}
+	if c.resolvers != nil {
+		if r, ok := c.resolvers.Load(key); ok {
+			return r.(Resolver)
+		}
+	}
+
+	f := ot.Fields[fn]
+	var r Resolver
+	if r = f.Resolver; r == nil {
+		r = defaultResolver(fn)
+	}
+
+	// field definition directives are visited on every call, since they get the context of the request
+	for _, d := range f.Directives {
+		if _, ok := d.(FieldDefinitionDirective); ok {
+			r = visitFieldDefinitionDirectives(f, r)
+			break
+		}
+	}
+
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		r = c.middlewares[i](r)
+	}
+
+	if c.resolvers != nil {
+		c.resolvers.Store(key, r)
+	}
+	return r
+}
+
+func visitFieldDefinitionDirectives(f *Field, base Resolver) Resolver {
+	return func(ctx Context) (interface{}, error) {
+		r := base
+		for _, d := range f.Directives {
+			if di, ok := d.(FieldDefinitionDirective); ok {
+				r = di.VisitFieldDefinition(ctx.Context(), *f, r)
+			}
+		}
+		return r(ctx)
+	}
+}
@@ -9,6 +9,9 @@ type Document struct {
 	Operations  []*Operation
 	Fragments   []*Fragment
 	Definitions []Definition
+	// Comments that are not the leading comments of a node, like the ones at the end of
+	// the document or a block, in the order of the source
+	Comments []*Comment
 }
 
 func NewDocument() *Document {
@@ -44,5 +47,5 @@ const (
 )
 
 func IsValidDirective(d string) bool {
-	return strings.Contains("QUERY MUTATION SUBSCRIPTION FIELD FRAGMENT_DEFINITION FRAGMENT_SPREAD INLINE_FRAGMENT SCHEMA SCALAR OBJECT FIELD_DEFINITION ARGUMENT_DEFINITION INTERFACE UNION ENUM ENUM_VALUE INPUT_OBJECT INPUT_FIELD_DEFINITION", fmt.Sprintf(" %s ", d))
+	return strings.Contains(" QUERY MUTATION SUBSCRIPTION FIELD FRAGMENT_DEFINITION FRAGMENT_SPREAD INLINE_FRAGMENT SCHEMA SCALAR OBJECT FIELD_DEFINITION ARGUMENT_DEFINITION INTERFACE UNION ENUM ENUM_VALUE INPUT_OBJECT INPUT_FIELD_DEFINITION ", fmt.Sprintf(" %s ", d))
 }
@@ -2,9 +2,21 @@ package ast
 
 import "fmt"
 
+// Location of a node in the source, Line and Column are 1-based and point to the start of the node,
+// Start and End are the byte offsets of the node (End is exclusive)
 type Location struct {
 	Column int
 	Line   int
+	Start  int
+	End    int
+}
+
+// Comment is a '#' comment in the source, Value is the text after the '#'. The comments are
+// only kept if the parser is asked to, as the leading comments of the definitions, fields and
+// selections, which are the comments between the node and the token before it.
+type Comment struct {
+	Value    string
+	Location Location
 }
 
 type Fragment struct {
@@ -13,6 +25,7 @@ type Fragment struct {
 	Directives    []*Directive
 	SelectionSet  []Selection
 	Location      Location
+	Comments      []*Comment
 }
 
 type OperationType int
@@ -23,6 +36,18 @@ const (
 	Subscription
 )
 
+func (ot OperationType) String() string {
+	switch ot {
+	case Query:
+		return "query"
+	case Mutation:
+		return "mutation"
+	case Subscription:
+		return "subscription"
+	}
+	return ""
+}
+
 type Operation struct {
 	OperationType OperationType
 	Name          string
@@ -30,6 +55,7 @@ type Operation struct {
 	Directives    []*Directive
 	SelectionSet  []Selection
 	Location      Location
+	Comments      []*Comment
 }
 
 func NewOperation(ot OperationType) *Operation {
@@ -166,6 +192,7 @@ type Field struct {
 	Directives   []*Directive
 	SelectionSet []Selection
 	Location     Location
+	Comments     []*Comment
 }
 
 func (f *Field) Kind() SelectionKind {
@@ -180,6 +207,7 @@ type FragmentSpread struct {
 	Name       string
 	Directives []*Directive
 	Location   Location
+	Comments   []*Comment
 }
 
 func (fs *FragmentSpread) Kind() SelectionKind {
@@ -195,6 +223,7 @@ type InlineFragment struct {
 	Directives    []*Directive
 	SelectionSet  []Selection
 	Location      Location
+	Comments      []*Comment
 }
 
 func (inf *InlineFragment) Kind() SelectionKind {
@@ -293,7 +322,9 @@ func (v *FloatValue) String() string {
 }
 
 type StringValue struct {
-	Value    string
+	Value string
+	// Block is true if the value was given as a block string ("""...""")
+	Block    bool
 	Location Location
 }
 
@@ -310,7 +341,7 @@ func (v *StringValue) GetLocation() Location {
 }
 
 func (v *StringValue) String() string {
-	return `"` + v.Value + `"`
+	return `"` + jsonEscape(v.Value) + `"`
 }
 
 type BooleanValue struct {
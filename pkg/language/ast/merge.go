@@ -0,0 +1,306 @@
+package ast
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MergeError is a conflict found while merging documents or type system extensions
+type MergeError struct {
+	// Name of the type (or 'schema') where the conflict is
+	Name    string
+	Message string
+}
+
+func (e *MergeError) Error() string {
+	return e.Message
+}
+
+// MergeErrors is a list of conflicts found while merging
+type MergeErrors []*MergeError
+
+func (es MergeErrors) Error() string {
+	msgs := make([]string, len(es))
+	for i, e := range es {
+		msgs[i] = e.Message
+	}
+	return strings.Join(msgs, "; ")
+}
+
+func newMergeError(name string, format string, args ...interface{}) *MergeError {
+	return &MergeError{
+		Name:    name,
+		Message: fmt.Sprintf(format, args...),
+	}
+}
+
+// IsExtension tells if the definition is a type system extension
+func IsExtension(def Definition) bool {
+	switch def.Kind() {
+	case SchemaExtensionKind, ScalarExtensionKind, ObjectExtensionKind, InterfaceExtensionKind,
+		UnionExtensionKind, EnumExtensionKind, InputObjectExtensionKind:
+		return true
+	}
+	return false
+}
+
+/*
+Merge appends the operations, fragments and definitions of the given documents to the document,
+which is useful when the SDL is split into multiple files. Defining a type, directive or fragment
+with the same name more than once, or having multiple schema definitions is a conflict.
+Extensions are not merged into their base definitions, use MergeExtensions for that.
+*/
+func (d *Document) Merge(docs ...*Document) error {
+	errs := MergeErrors{}
+	names := map[string]bool{}
+	fragments := map[string]bool{}
+	for _, def := range d.Definitions {
+		if name, ok := definitionName(def); ok {
+			names[name] = true
+		}
+	}
+	for _, f := range d.Fragments {
+		fragments[f.Name] = true
+	}
+
+	for _, doc := range docs {
+		d.Operations = append(d.Operations, doc.Operations...)
+		for _, f := range doc.Fragments {
+			if fragments[f.Name] {
+				errs = append(errs, newMergeError(f.Name, "fragment '%s' is defined more than once", f.Name))
+				continue
+			}
+			fragments[f.Name] = true
+			d.Fragments = append(d.Fragments, f)
+		}
+		for _, def := range doc.Definitions {
+			if name, ok := definitionName(def); ok {
+				if names[name] {
+					errs = append(errs, newMergeError(name, "'%s' is defined more than once", name))
+					continue
+				}
+				names[name] = true
+			}
+			d.Definitions = append(d.Definitions, def)
+		}
+	}
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// definitionName returns the unique name of a definition, extensions do not have one
+func definitionName(def Definition) (string, bool) {
+	switch def := def.(type) {
+	case *SchemaDefinition:
+		return "schema", true
+	case *ScalarDefinition:
+		return def.Name, true
+	case *ObjectDefinition:
+		return def.Name, true
+	case *InterfaceDefinition:
+		return def.Name, true
+	case *UnionDefinition:
+		return def.Name, true
+	case *EnumDefinition:
+		return def.Name, true
+	case *InputObjectDefinition:
+		return def.Name, true
+	case *DirectiveDefinition:
+		return "@" + def.Name, true
+	}
+	return "", false
+}
+
+/*
+MergeExtensions merges all the type system extensions of the document into their base definitions,
+which are modified in place, and removes the extensions from the document. The base definition can
+be anywhere in the document, even after the extension. Extending a type that is not defined, or a type
+of a different kind, and redefining a field, enum value, union member, interface or root operation type
+are conflicts. The extensions with a conflict stay in the document.
+*/
+func (d *Document) MergeExtensions() error {
+	errs := MergeErrors{}
+	bases := map[string]Definition{}
+	for _, def := range d.Definitions {
+		if name, ok := definitionName(def); ok {
+			bases[name] = def
+		}
+	}
+
+	defs := make([]Definition, 0, len(d.Definitions))
+	for _, def := range d.Definitions {
+		if !IsExtension(def) {
+			defs = append(defs, def)
+			continue
+		}
+		if err := mergeExtension(bases, def); err != nil {
+			errs = append(errs, err)
+			defs = append(defs, def)
+		}
+	}
+	d.Definitions = defs
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+func mergeExtension(bases map[string]Definition, ext Definition) *MergeError {
+	var name string
+	switch ext := ext.(type) {
+	case *SchemaExtension:
+		name = "schema"
+	case *ScalarExtension:
+		name = ext.Name
+	case *ObjectExtension:
+		name = ext.Name
+	case *InterfaceExtension:
+		name = ext.Name
+	case *UnionExtension:
+		name = ext.Name
+	case *EnumExtension:
+		name = ext.Name
+	case *InputObjectExtension:
+		name = ext.Name
+	}
+
+	base, ok := bases[name]
+	if !ok {
+		return newMergeError(name, "cannot extend '%s', it is not defined", name)
+	}
+
+	switch ext := ext.(type) {
+	case *SchemaExtension:
+		if base, ok := base.(*SchemaDefinition); ok {
+			return mergeSchemaExtension(base, ext)
+		}
+	case *ScalarExtension:
+		if base, ok := base.(*ScalarDefinition); ok {
+			base.Directives = append(base.Directives, ext.Directives...)
+			return nil
+		}
+	case *ObjectExtension:
+		if base, ok := base.(*ObjectDefinition); ok {
+			return mergeObjectExtension(base, ext)
+		}
+	case *InterfaceExtension:
+		if base, ok := base.(*InterfaceDefinition); ok {
+			if err := checkFields(name, base.Fields, ext.Fields); err != nil {
+				return err
+			}
+			base.Directives = append(base.Directives, ext.Directives...)
+			base.Fields = append(base.Fields, ext.Fields...)
+			return nil
+		}
+	case *UnionExtension:
+		if base, ok := base.(*UnionDefinition); ok {
+			if err := checkNamedTypes(name, "member", base.Members, ext.Members); err != nil {
+				return err
+			}
+			base.Directives = append(base.Directives, ext.Directives...)
+			base.Members = append(base.Members, ext.Members...)
+			return nil
+		}
+	case *EnumExtension:
+		if base, ok := base.(*EnumDefinition); ok {
+			return mergeEnumExtension(base, ext)
+		}
+	case *InputObjectExtension:
+		if base, ok := base.(*InputObjectDefinition); ok {
+			return mergeInputObjectExtension(base, ext)
+		}
+	}
+	return newMergeError(name, "cannot extend '%s', it is defined with a different kind", name)
+}
+
+func mergeSchemaExtension(base *SchemaDefinition, ext *SchemaExtension) *MergeError {
+	for ot := range ext.RootOperations {
+		if _, ok := base.RootOperations[ot]; ok {
+			return newMergeError("schema", "root operation type '%s' is already defined in the schema", ot)
+		}
+	}
+	if base.RootOperations == nil {
+		base.RootOperations = map[OperationType]*NamedType{}
+	}
+	for ot, nt := range ext.RootOperations {
+		base.RootOperations[ot] = nt
+	}
+	base.Directives = append(base.Directives, ext.Directives...)
+	return nil
+}
+
+func mergeObjectExtension(base *ObjectDefinition, ext *ObjectExtension) *MergeError {
+	if err := checkNamedTypes(base.Name, "interface", base.Implements, ext.Implements); err != nil {
+		return err
+	}
+	if err := checkFields(base.Name, base.Fields, ext.Fields); err != nil {
+		return err
+	}
+	base.Implements = append(base.Implements, ext.Implements...)
+	base.Directives = append(base.Directives, ext.Directives...)
+	base.Fields = append(base.Fields, ext.Fields...)
+	return nil
+}
+
+func mergeEnumExtension(base *EnumDefinition, ext *EnumExtension) *MergeError {
+	values := map[string]bool{}
+	for _, v := range base.Values {
+		values[v.Value.Value] = true
+	}
+	for _, v := range ext.Values {
+		if values[v.Value.Value] {
+			return newMergeError(base.Name, "enum value '%s.%s' is already defined", base.Name, v.Value.Value)
+		}
+		values[v.Value.Value] = true
+	}
+	base.Directives = append(base.Directives, ext.Directives...)
+	base.Values = append(base.Values, ext.Values...)
+	return nil
+}
+
+func mergeInputObjectExtension(base *InputObjectDefinition, ext *InputObjectExtension) *MergeError {
+	fields := map[string]bool{}
+	for _, f := range base.Fields {
+		fields[f.Name] = true
+	}
+	for _, f := range ext.Fields {
+		if fields[f.Name] {
+			return newMergeError(base.Name, "field '%s.%s' is already defined", base.Name, f.Name)
+		}
+		fields[f.Name] = true
+	}
+	base.Directives = append(base.Directives, ext.Directives...)
+	base.Fields = append(base.Fields, ext.Fields...)
+	return nil
+}
+
+func checkFields(name string, base []*FieldDefinition, ext []*FieldDefinition) *MergeError {
+	fields := map[string]bool{}
+	for _, f := range base {
+		fields[f.Name] = true
+	}
+	for _, f := range ext {
+		if fields[f.Name] {
+			return newMergeError(name, "field '%s.%s' is already defined", name, f.Name)
+		}
+		fields[f.Name] = true
+	}
+	return nil
+}
+
+func checkNamedTypes(name string, what string, base []*NamedType, ext []*NamedType) *MergeError {
+	types := map[string]bool{}
+	for _, t := range base {
+		types[t.Name] = true
+	}
+	for _, t := range ext {
+		if types[t.Name] {
+			return newMergeError(name, "%s '%s' is already added to '%s'", what, t.Name, name)
+		}
+		types[t.Name] = true
+	}
+	return nil
+}
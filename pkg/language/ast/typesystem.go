@@ -16,6 +16,13 @@ const (
 	EnumKind
 	InputObjectKind
 	DirectiveKind
+	SchemaExtensionKind
+	ScalarExtensionKind
+	ObjectExtensionKind
+	InterfaceExtensionKind
+	UnionExtensionKind
+	EnumExtensionKind
+	InputObjectExtensionKind
 )
 
 type Definition interface {
@@ -27,6 +34,8 @@ type SchemaDefinition struct {
 	Name           string
 	Directives     []*Directive
 	RootOperations map[OperationType]*NamedType
+	Location       Location
+	Comments       []*Comment
 }
 
 func (d *SchemaDefinition) Kind() DefinitionKind {
@@ -56,6 +65,8 @@ type ScalarDefinition struct {
 	Description string
 	Name        string
 	Directives  []*Directive
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *ScalarDefinition) Kind() DefinitionKind {
@@ -81,6 +92,8 @@ type ObjectDefinition struct {
 	Implements  []*NamedType
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *ObjectDefinition) Kind() DefinitionKind {
@@ -113,6 +126,8 @@ type FieldDefinition struct {
 	Arguments   []*InputValueDefinition
 	Type        Type
 	Directives  []*Directive
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *FieldDefinition) String() string {
@@ -146,10 +161,16 @@ type InputValueDefinition struct {
 	Type         Type
 	DefaultValue Value
 	Directives   []*Directive
+	Location     Location
+	Comments     []*Comment
 }
 
 func (d *InputValueDefinition) String() string {
-	return d.Name + ": " + d.Type.String()
+	out := d.Name + ": " + d.Type.String()
+	for _, dir := range d.Directives {
+		out += " " + dir.String()
+	}
+	return out
 }
 
 type InterfaceDefinition struct {
@@ -157,6 +178,8 @@ type InterfaceDefinition struct {
 	Name        string
 	Directives  []*Directive
 	Fields      []*FieldDefinition
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *InterfaceDefinition) Kind() DefinitionKind {
@@ -188,6 +211,8 @@ type UnionDefinition struct {
 	Name        string
 	Directives  []*Directive
 	Members     []*NamedType
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *UnionDefinition) Kind() DefinitionKind {
@@ -218,6 +243,8 @@ type EnumDefinition struct {
 	Name        string
 	Directives  []*Directive
 	Values      []*EnumValueDefinition
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *EnumDefinition) Kind() DefinitionKind {
@@ -241,6 +268,8 @@ type EnumValueDefinition struct {
 	Description string
 	Value       *EnumValue
 	Directives  []*Directive
+	Location    Location
+	Comments    []*Comment
 }
 
 type InputObjectDefinition struct {
@@ -248,6 +277,8 @@ type InputObjectDefinition struct {
 	Name        string
 	Directives  []*Directive
 	Fields      []*InputValueDefinition
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *InputObjectDefinition) Kind() DefinitionKind {
@@ -279,6 +310,8 @@ type DirectiveDefinition struct {
 	Name        string
 	Locations   []string
 	Arguments   []*InputValueDefinition
+	Location    Location
+	Comments    []*Comment
 }
 
 func (d *DirectiveDefinition) Kind() DefinitionKind {
@@ -290,10 +323,172 @@ func (d *DirectiveDefinition) String() string {
 	if d.Description != "" {
 		out += `"""` + jsonEscape(d.Description) + "\"\"\"\n"
 	}
-	out += "directive @" + d.Name + " on " + strings.Join(d.Locations, " | ") + "\n"
+	out += "directive @" + d.Name
+	if len(d.Arguments) != 0 {
+		out += "("
+		for i, a := range d.Arguments {
+			if i != 0 {
+				out += ", "
+			}
+			out += a.String()
+		}
+		out += ")"
+	}
+	out += " on " + strings.Join(d.Locations, " | ") + "\n"
 	return out
 }
 
+type SchemaExtension struct {
+	Directives     []*Directive
+	RootOperations map[OperationType]*NamedType
+	Location       Location
+	Comments       []*Comment
+}
+
+func (d *SchemaExtension) Kind() DefinitionKind {
+	return SchemaExtensionKind
+}
+
+func (d *SchemaExtension) String() string {
+	out := "extend schema "
+	for _, dir := range d.Directives {
+		out += dir.String() + " "
+	}
+	if len(d.RootOperations) == 0 {
+		return out + "\n"
+	}
+	def := &SchemaDefinition{
+		RootOperations: d.RootOperations,
+	}
+	return out + strings.TrimPrefix(def.String(), "schema ")
+}
+
+type ScalarExtension struct {
+	Name       string
+	Directives []*Directive
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *ScalarExtension) Kind() DefinitionKind {
+	return ScalarExtensionKind
+}
+
+func (d *ScalarExtension) String() string {
+	def := &ScalarDefinition{
+		Name:       d.Name,
+		Directives: d.Directives,
+	}
+	return "extend " + def.String()
+}
+
+type ObjectExtension struct {
+	Name       string
+	Implements []*NamedType
+	Directives []*Directive
+	Fields     []*FieldDefinition
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *ObjectExtension) Kind() DefinitionKind {
+	return ObjectExtensionKind
+}
+
+func (d *ObjectExtension) String() string {
+	def := &ObjectDefinition{
+		Name:       d.Name,
+		Implements: d.Implements,
+		Directives: d.Directives,
+		Fields:     d.Fields,
+	}
+	return "extend " + def.String()
+}
+
+type InterfaceExtension struct {
+	Name       string
+	Directives []*Directive
+	Fields     []*FieldDefinition
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *InterfaceExtension) Kind() DefinitionKind {
+	return InterfaceExtensionKind
+}
+
+func (d *InterfaceExtension) String() string {
+	def := &InterfaceDefinition{
+		Name:       d.Name,
+		Directives: d.Directives,
+		Fields:     d.Fields,
+	}
+	return "extend " + def.String()
+}
+
+type UnionExtension struct {
+	Name       string
+	Directives []*Directive
+	Members    []*NamedType
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *UnionExtension) Kind() DefinitionKind {
+	return UnionExtensionKind
+}
+
+func (d *UnionExtension) String() string {
+	def := &UnionDefinition{
+		Name:       d.Name,
+		Directives: d.Directives,
+		Members:    d.Members,
+	}
+	return "extend " + def.String()
+}
+
+type EnumExtension struct {
+	Name       string
+	Directives []*Directive
+	Values     []*EnumValueDefinition
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *EnumExtension) Kind() DefinitionKind {
+	return EnumExtensionKind
+}
+
+func (d *EnumExtension) String() string {
+	def := &EnumDefinition{
+		Name:       d.Name,
+		Directives: d.Directives,
+		Values:     d.Values,
+	}
+	return "extend " + def.String()
+}
+
+type InputObjectExtension struct {
+	Name       string
+	Directives []*Directive
+	Fields     []*InputValueDefinition
+	Location   Location
+	Comments   []*Comment
+}
+
+func (d *InputObjectExtension) Kind() DefinitionKind {
+	return InputObjectExtensionKind
+}
+
+func (d *InputObjectExtension) String() string {
+	def := &InputObjectDefinition{
+		Name:       d.Name,
+		Directives: d.Directives,
+		Fields:     d.Fields,
+	}
+	return "extend " + def.String()
+}
+
 func jsonEscape(i string) string {
 	b, err := json.Marshal(i)
 	if err != nil {
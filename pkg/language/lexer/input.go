@@ -1,14 +1,17 @@
 package lexer
 
 import (
+	"bytes"
+	"strings"
 	"unicode/utf8"
 )
 
 type Input struct {
-	raw    []byte
-	Pos    int
-	Line   int
-	Column int
+	raw       []byte
+	Pos       int
+	Line      int
+	Column    int
+	lineStart int
 }
 
 func NewInput(bs []byte) *Input {
@@ -21,12 +24,34 @@ func (i *Input) Reset() {
 	i.Pos = 0
 	i.Line = 0
 	i.Column = 0
+	i.lineStart = 0
 }
 
+// Value returns the source of the token, without copying it
 func (i *Input) Value(t *Token) []byte {
 	return i.raw[t.Start:t.End]
 }
 
+// StringValue returns the value of a string token, with the escape sequences of the
+// strings and the indentation of the block strings resolved
+func (i *Input) StringValue(t *Token) string {
+	if t.Block {
+		content := i.raw[t.Start+3 : t.End-3]
+		if bytes.Contains(content, []byte(`\"""`)) {
+			content = bytes.Replace(content, []byte(`\"""`), []byte(`"""`), -1)
+		}
+		return string(BlockStringValue(content))
+	}
+	content := i.raw[t.Start+1 : t.End-1]
+	if bytes.IndexByte(content, runeBackSlash) < 0 {
+		return string(content)
+	}
+	var value strings.Builder
+	l := Lexer{input: &Input{raw: i.raw, Pos: t.Start}}
+	l.readSingleLineString(&Token{}, &value)
+	return value.String()
+}
+
 func (i *Input) PeekOneRune(n int) (rune, int) {
 	return utf8.DecodeRune(i.raw[i.Pos+n:])
 }
@@ -37,3 +62,25 @@ func (i *Input) PeekOne(n int) rune {
 	}
 	return rune(i.raw[i.Pos+n])
 }
+
+// newLine registers a line terminator ending at the given position
+func (i *Input) newLine(end int) {
+	i.Line++
+	i.lineStart = end
+}
+
+// countLines registers all the line terminators in raw[start:end], used for tokens
+// that can contain line terminators, like block strings
+func (i *Input) countLines(start, end int) {
+	for p := start; p < end; p++ {
+		switch i.raw[p] {
+		case '\n':
+			i.newLine(p + 1)
+		case '\r':
+			if p+1 < end && i.raw[p+1] == '\n' {
+				continue
+			}
+			i.newLine(p + 1)
+		}
+	}
+}
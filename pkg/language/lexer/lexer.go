@@ -1,7 +1,19 @@
 package lexer
 
+import (
+	"fmt"
+	"unicode/utf8"
+)
+
 type Lexer struct {
 	input *Input
+	depth int
+	level int
+	// end offsets of the last and the previous read tokens
+	end     int
+	prevEnd int
+	// comments makes the lexer return the comments as tokens instead of ignoring them
+	comments bool
 }
 
 func NewLexer(in *Input) *Lexer {
@@ -10,15 +22,35 @@ func NewLexer(in *Input) *Lexer {
 	}
 }
 
-func (l *Lexer) Read() (t Token) {
-	defer func() {
-		if t.Kind != EOFToken && t.Value == "" {
-			t.Value = string(l.input.raw[t.Start:t.End])
-		}
-	}()
+// Input returns the input of the lexer, to read the values of the tokens
+func (l *Lexer) Input() *Input {
+	return l.input
+}
+
+/*
+EmitComments makes the lexer return the comments as CommentTokens, which are ignored by default.
+The comment tokens do not change the end offsets returned by PrevEnd.
+*/
+func (l *Lexer) EmitComments(emit bool) {
+	l.comments = emit
+}
+
+// Read returns the next token, it never allocates, the value of the token is read through the
+// input with Value or StringValue
+func (l *Lexer) Read() Token {
+	t := l.next()
+	if t.Kind != CommentToken {
+		l.trackDepth(&t)
+		l.prevEnd, l.end = l.end, t.End
+	}
+	return t
+}
 
+func (l *Lexer) next() (t Token) {
 	l.ignore()
 	t.Start = l.input.Pos
+	t.Line = l.input.Line + 1
+	t.Col = l.input.Pos - l.input.lineStart + 1
 	if l.isSingleCharacterToken(&t) {
 		return
 	}
@@ -30,13 +62,11 @@ func (l *Lexer) Read() (t Token) {
 	case runeQuotation == r:
 		l.readStringValue(&t)
 		return
+	case runeHashtag == r:
+		l.readComment(&t)
+		return
 	case isDigit(r) || runeNegativeSign == r:
-		if l.readIntValue(&t) {
-			return
-		} else if l.readFloatValue(&t) {
-			return
-		}
-		// TODO: undefined token
+		l.readNumber(&t)
 		return
 	}
 
@@ -44,30 +74,87 @@ func (l *Lexer) Read() (t Token) {
 	return
 }
 
+// Depth returns the brace nesting level of the last read token
+func (l *Lexer) Depth() int {
+	return l.level
+}
+
+// PrevEnd returns the end offset of the token before the last read one, which is the end
+// of a node in the parser, since it has always read one token ahead when a node is finished
+func (l *Lexer) PrevEnd() int {
+	return l.prevEnd
+}
+
+func (l *Lexer) trackDepth(t *Token) {
+	if t.Kind != PunctuatorToken {
+		l.level = l.depth
+		return
+	}
+	switch l.input.raw[t.Start] {
+	case runeLeftBrace:
+		l.level = l.depth
+		l.depth++
+	case runeRightBrace:
+		if l.depth > 0 {
+			l.depth--
+		}
+		l.level = l.depth
+	default:
+		l.level = l.depth
+	}
+}
+
 func (l *Lexer) ignore() {
-	if canIgnore(l.input.PeekOne(0)) {
-		l.input.Pos++
-		l.ignore()
-	} else if l.input.PeekOne(0) == runeHashtag {
-		for isCommentCharacter(l.input.PeekOne(0)) {
+	for {
+		r := l.input.PeekOne(0)
+		switch {
+		case r == runeNewLine:
+			l.input.Pos++
+			l.input.newLine(l.input.Pos)
+		case r == runeCarriageReturn:
+			l.input.Pos++
+			if l.input.PeekOne(0) != runeNewLine {
+				l.input.newLine(l.input.Pos)
+			}
+		case canIgnore(r):
 			l.input.Pos++
+		case l.input.Pos+2 < len(l.input.raw) && l.input.raw[l.input.Pos] == 0xEF && l.input.raw[l.input.Pos+1] == 0xBB && l.input.raw[l.input.Pos+2] == 0xBF:
+			// UTF-8 encoded byte order mark
+			l.input.Pos += 3
+		case r == runeHashtag && l.comments:
+			return
+		case r == runeHashtag:
+			for l.input.Pos < len(l.input.raw) && isCommentCharacter(l.input.PeekOne(0)) {
+				l.input.Pos++
+			}
+		default:
+			return
 		}
-		l.ignore()
 	}
 }
 
-func (l *Lexer) peekEqual(bs ...rune) bool {
-	for i := 0; i < len(bs); i++ {
-		if l.input.PeekOne(i+1) != bs[i] {
-			return false
-		}
+// undefined makes t an Undefined token with the given error, consuming at least one character
+func (l *Lexer) undefined(t *Token, format string, args ...interface{}) {
+	t.Kind = Undefined
+	t.Err = fmt.Errorf(format, args...)
+	if l.input.Pos <= t.Start && l.input.Pos < len(l.input.raw) {
+		_, size := utf8.DecodeRune(l.input.raw[l.input.Pos:])
+		l.input.Pos += size
 	}
-	return true
+	t.End = l.input.Pos
+}
+
+// errorAt makes t an Undefined token with the error reported at the given position of the
+// current line, instead of the start of the token
+func (l *Lexer) errorAt(t *Token, pos int, format string, args ...interface{}) {
+	t.Line = l.input.Line + 1
+	t.Col = pos - l.input.lineStart + 1
+	l.undefined(t, format, args...)
 }
 
-func (l *Lexer) peekRules(bs ...func(rune) bool) bool {
+func (l *Lexer) peekEqual(bs ...rune) bool {
 	for i := 0; i < len(bs); i++ {
-		if !bs[i](l.input.PeekOne(i)) {
+		if l.input.PeekOne(i+1) != bs[i] {
 			return false
 		}
 	}
@@ -79,7 +166,6 @@ func (l *Lexer) isSingleCharacterToken(t *Token) bool {
 		t.Kind = EOFToken
 	} else if isPunctuator(l.input.PeekOne(0)) {
 		t.Kind = PunctuatorToken
-		t.Value = string(l.input.raw[l.input.Pos : l.input.Pos+1])
 	} else {
 		return false
 	}
@@ -89,22 +175,33 @@ func (l *Lexer) isSingleCharacterToken(t *Token) bool {
 }
 
 func (l *Lexer) readName(t *Token) {
+	if !isNameStart(l.input.PeekOne(0)) {
+		r, _ := utf8.DecodeRune(l.input.raw[l.input.Pos:])
+		l.undefined(t, "Unexpected character %q", r)
+		return
+	}
 	t.Kind = NameToken
-	defer func() {
-		t.End = l.input.Pos
-	}()
-	for {
-		if l.input.Pos > t.Start && isName(l.input.PeekOne(0)) {
-			l.input.Pos++
-		} else if l.input.Pos == t.Start && isNameStart(l.input.PeekOne(0)) {
-			l.input.Pos++
-		} else {
-			return
-		}
+	l.input.Pos++
+	for isName(l.input.PeekOne(0)) {
+		l.input.Pos++
+	}
+	t.End = l.input.Pos
+}
+
+func (l *Lexer) readComment(t *Token) {
+	t.Kind = CommentToken
+	l.input.Pos++
+	for l.input.Pos < len(l.input.raw) && isCommentCharacter(l.input.PeekOne(0)) {
+		l.input.Pos++
 	}
+	t.End = l.input.Pos
 }
 
 func (l *Lexer) readDot(t *Token) {
+	if !l.peekEqual(runeDot, runeDot) {
+		l.undefined(t, "Unexpected character '.', did you mean '...'?")
+		return
+	}
 	t.Kind = PunctuatorToken
 	l.input.Pos += 3
 	t.End = l.input.Pos
@@ -19,18 +19,31 @@ func TestStringValue(t *testing.T) {
 	}
 	token1 := l.Read()
 	token2 := l.Read()
-	if token1.Value != token2.Value {
-		t.Fatalf("expected '%s' == '%s'", token1.Value, token2.Value)
+	if value(l, token1) != value(l, token2) {
+		t.Fatalf("expected '%s' == '%s'", value(l, token1), value(l, token2))
 	}
 }
 
+// value returns the value of the token like the parser reads it
+func value(l *Lexer, t Token) string {
+	switch t.Kind {
+	case EOFToken:
+		return ""
+	case StringValueToken:
+		return l.Input().StringValue(&t)
+	case CommentToken:
+		return string(l.Input().Value(&t)[1:])
+	}
+	return string(l.Input().Value(&t))
+}
+
 func TestFloatAsInt(t *testing.T) {
 	l := &Lexer{
 		input: NewInput([]byte(`123`)),
 	}
 	token := l.Read()
-	if token.Value != "123" || token.Kind != IntValueToken {
-		t.Fatalf("expected '%s' == '%s'", token.Value, "123")
+	if value(l, token) != "123" || token.Kind != IntValueToken {
+		t.Fatalf("expected '%s' == '%s'", value(l, token), "123")
 	}
 }
 func TestFloatAsSimpleFloat(t *testing.T) {
@@ -38,20 +51,20 @@ func TestFloatAsSimpleFloat(t *testing.T) {
 		input: NewInput([]byte(`123.123`)),
 	}
 	token := l.Read()
-	if token.Value != "123.123" || token.Kind != FloatValueToken {
-		t.Fatalf("expected '%s' == '%s' & '%v' == '%v'", token.Value, "123.123", token.Kind.String(), FloatValueToken.String())
+	if value(l, token) != "123.123" || token.Kind != FloatValueToken {
+		t.Fatalf("expected '%s' == '%s' & '%v' == '%v'", value(l, token), "123.123", token.Kind.String(), FloatValueToken.String())
 	}
-	t.Logf("got '%s' == '%s' & '%v' == '%v'", token.Value, "123.123", token.Kind.String(), FloatValueToken.String())
+	t.Logf("got '%s' == '%s' & '%v' == '%v'", value(l, token), "123.123", token.Kind.String(), FloatValueToken.String())
 }
 func TestFloatAsComplexFloat(t *testing.T) {
 	l := &Lexer{
 		input: NewInput([]byte(`123.123e+20`)),
 	}
 	token := l.Read()
-	if token.Value != "123.123e+20" || token.Kind != FloatValueToken {
-		t.Fatalf("expected '%s' == '%s' & '%v' == '%v'", token.Value, "123.123e+20", token.Kind.String(), FloatValueToken.String())
+	if value(l, token) != "123.123e+20" || token.Kind != FloatValueToken {
+		t.Fatalf("expected '%s' == '%s' & '%v' == '%v'", value(l, token), "123.123e+20", token.Kind.String(), FloatValueToken.String())
 	}
-	t.Logf("got '%s' == '%s' & '%v' == '%v'", token.Value, "123.123e+20", token.Kind.String(), FloatValueToken.String())
+	t.Logf("got '%s' == '%s' & '%v' == '%v'", value(l, token), "123.123e+20", token.Kind.String(), FloatValueToken.String())
 }
 
 func TestBlockStringValue(t *testing.T) {
@@ -89,7 +102,7 @@ func TestLexer(t *testing.T) {
 	}
 	token := l.Read()
 	for {
-		t.Log(token.Kind, token.Value)
+		t.Log(token.Kind, value(l, token))
 		if token.Kind == EOFToken {
 			return
 		}
@@ -223,3 +236,218 @@ func BenchmarkLexer(b *testing.B) {
 		}
 	}
 }
+
+func TestTokenPositions(t *testing.T) {
+	l := NewLexer(NewInput([]byte("{\r\n  a(b: \"\"\"x\n\"\"\", c: -5)\r  # comment\n\tfoo\n}")))
+	expected := []struct {
+		value     string
+		line, col int
+	}{
+		{"{", 1, 1},
+		{"a", 2, 3},
+		{"(", 2, 4},
+		{"b", 2, 5},
+		{":", 2, 6},
+		{"x", 2, 8},
+		{"c", 3, 6},
+		{":", 3, 7},
+		{"-5", 3, 9},
+		{")", 3, 11},
+		{"foo", 5, 2},
+		{"}", 6, 1},
+	}
+	for _, e := range expected {
+		token := l.Read()
+		if value(l, token) != e.value || token.Line != e.line || token.Col != e.col {
+			t.Fatalf("expected '%s' at %d:%d, got '%s' at %d:%d", e.value, e.line, e.col, value(l, token), token.Line, token.Col)
+		}
+	}
+	if token := l.Read(); token.Kind != EOFToken {
+		t.Fatalf("expected EOF, got %v", token.Kind)
+	}
+}
+
+func TestInvalidTokens(t *testing.T) {
+	tests := []struct {
+		input string
+		err   string
+	}{
+		{`^`, `Unexpected character '^'`},
+		{`"foo`, `Unterminated string`},
+		{"\"foo\nbar\"", `Unterminated string`},
+		{`"""foo`, `Unterminated string`},
+		{`..`, `Unexpected character '.', did you mean '...'?`},
+		{`01`, `Invalid number, unexpected digit after 0: '1'`},
+		{`1.`, `Invalid number, expected digit but got: <EOF>`},
+		{`1.2e`, `Invalid number, expected digit but got: <EOF>`},
+		{`-`, `Invalid number, expected digit but got: <EOF>`},
+		{`123abc`, `Invalid number, expected digit but got: 'a'`},
+	}
+	for _, tt := range tests {
+		token := NewLexer(NewInput([]byte(tt.input))).Read()
+		if token.Kind != Undefined || token.Err == nil || token.Err.Error() != tt.err {
+			t.Errorf("input '%s': expected Undefined token with error '%s', got %v: %v", tt.input, tt.err, token.Kind, token.Err)
+		}
+	}
+	for _, input := range []string{"0", "-5", "-0.5", "1e10", "0.1E-3"} {
+		l := NewLexer(NewInput([]byte(input)))
+		token := l.Read()
+		if value(l, token) != input || token.Err != nil {
+			t.Errorf("expected number '%s', got '%s' (%v)", input, value(l, token), token.Err)
+		}
+	}
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+	}{
+		{`""`, ""},
+		{`"simple"`, "simple"},
+		{`" white space "`, " white space "},
+		{`"quote \""`, `quote "`},
+		{`"escaped \n\r\b\t\f"`, "escaped \n\r\b\t\f"},
+		{`"slashes \\ \/"`, `slashes \ /`},
+		{`"unescaped unicode outside BMP 😀"`, "unescaped unicode outside BMP 😀"},
+		{`"unescaped maximal unicode outside BMP 􏿿"`, "unescaped maximal unicode outside BMP \U0010FFFF"},
+		{`"unicode ሴ噸邫췯"`, "unicode ሴ噸邫췯"},
+		{`"unicode \u1234\u5678\u90AB\uCDEF"`, "unicode ሴ噸邫췯"},
+		{`"unicode \u{1234}\u{5678}\u{90AB}\u{CDEF}"`, "unicode ሴ噸邫췯"},
+		{`"string with unicode escape outside BMP \u{1F600}"`, "string with unicode escape outside BMP 😀"},
+		{`"string with minimal unicode escape \u{0}"`, "string with minimal unicode escape \u0000"},
+		{`"string with maximal unicode escape \u{10FFFF}"`, "string with maximal unicode escape \U0010FFFF"},
+		{`"string with maximal minimal unicode escape \u{00000000}"`, "string with maximal minimal unicode escape \u0000"},
+		{`"string with unicode surrogate pair escape \uD83D\uDE00"`, "string with unicode surrogate pair escape 😀"},
+		{`"string with minimal surrogate pair escape \uD800\uDC00"`, "string with minimal surrogate pair escape \U00010000"},
+		{`"string with maximal surrogate pair escape \uDBFF\uDFFF"`, "string with maximal surrogate pair escape \U0010FFFF"},
+	}
+	for _, tt := range tests {
+		l := NewLexer(NewInput([]byte(tt.input)))
+		token := l.Read()
+		if token.Kind != StringValueToken || token.Err != nil || value(l, token) != tt.value {
+			t.Errorf("input '%s': expected string %q, got %v %q (%v)", tt.input, tt.value, token.Kind, value(l, token), token.Err)
+		}
+		if token.Start != 0 || token.End != len(tt.input) {
+			t.Errorf("input '%s': expected token at 0-%d, got %d-%d", tt.input, len(tt.input), token.Start, token.End)
+		}
+	}
+}
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input  string
+		err    string
+		line   int
+		column int
+	}{
+		{`"`, `Unterminated string`, 1, 1},
+		{`"""`, `Unterminated string`, 1, 1},
+		{`""""`, `Unterminated string`, 1, 1},
+		{`"no end quote`, `Unterminated string`, 1, 1},
+		{"\"contains unescaped \u0007 control char\"", `Invalid character within String: U+0007`, 1, 21},
+		{"\"null-byte is not \u0000 end of file\"", `Invalid character within String: U+0000`, 1, 19},
+		{"\"multi\nline\"", `Unterminated string`, 1, 1},
+		{"\"multi\rline\"", `Unterminated string`, 1, 1},
+		{`"bad \z esc"`, `Invalid character escape sequence: "\z"`, 1, 6},
+		{`"bad \x esc"`, `Invalid character escape sequence: "\x"`, 1, 6},
+		{`"bad \u1 esc"`, `Invalid Unicode escape sequence: "\u1 es"`, 1, 6},
+		{`"bad \u0XX1 esc"`, `Invalid Unicode escape sequence: "\u0XX1"`, 1, 6},
+		{`"bad \uXXXX esc"`, `Invalid Unicode escape sequence: "\uXXXX"`, 1, 6},
+		{`"bad \uFXXX esc"`, `Invalid Unicode escape sequence: "\uFXXX"`, 1, 6},
+		{`"bad \uXXXF esc"`, `Invalid Unicode escape sequence: "\uXXXF"`, 1, 6},
+		{`"bad \u{} esc"`, `Invalid Unicode escape sequence: "\u{}"`, 1, 6},
+		{`"bad \u{FXXX} esc"`, `Invalid Unicode escape sequence: "\u{FX"`, 1, 6},
+		{`"bad \u{FFFF esc"`, `Invalid Unicode escape sequence: "\u{FFFF "`, 1, 6},
+		{`"bad \u{FFFF"`, `Invalid Unicode escape sequence: "\u{FFFF""`, 1, 6},
+		{`"too high \u{110000} esc"`, `Invalid Unicode escape sequence: "\u{110000}"`, 1, 11},
+		{`"way too high \u{12345678} esc"`, `Invalid Unicode escape sequence: "\u{12345678}"`, 1, 15},
+		{`"too long \u{000000000} esc"`, `Invalid Unicode escape sequence: "\u{000000000"`, 1, 11},
+		{`"bad surrogate \uDEAD esc"`, `Invalid Unicode escape sequence: "\uDEAD"`, 1, 16},
+		{`"bad surrogate \u{DEAD} esc"`, `Invalid Unicode escape sequence: "\u{DEAD}"`, 1, 16},
+		{`"cannot use braces for surrogate pair \u{D83D}\u{DE00} esc"`, `Invalid Unicode escape sequence: "\u{D83D}"`, 1, 39},
+		{`"bad high surrogate pair \uDEAD\uDEAD esc"`, `Invalid Unicode escape sequence: "\uDEAD"`, 1, 26},
+		{`"bad low surrogate pair \uD800\uD800 esc"`, `Invalid Unicode escape sequence: "\uD800"`, 1, 25},
+		{`"cannot escape half a pair \uD83D\u{DE00} esc"`, `Invalid Unicode escape sequence: "\uD83D"`, 1, 28},
+		{`"cannot escape half a pair \uD83D esc"`, `Invalid Unicode escape sequence: "\uD83D"`, 1, 28},
+		{"\"unescaped unpaired surrogate \xED\xBA\xAD\"", `Invalid character within String: U+DEAD`, 1, 31},
+		{"\"invalid UTF-8 \xFF\"", `Invalid character within String: invalid UTF-8 byte 0xFF`, 1, 16},
+		{"\"\"\"contains unescaped \u0007 control char\"\"\"", `Invalid character within String: U+0007`, 1, 23},
+		{"\"\"\"\n  null-byte is not \u0000 end of file\"\"\"", `Invalid character within String: U+0000`, 2, 20},
+	}
+	for _, tt := range tests {
+		token := NewLexer(NewInput([]byte(tt.input))).Read()
+		if token.Kind != Undefined || token.Err == nil || token.Err.Error() != tt.err {
+			t.Errorf("input %q: expected Undefined token with error '%s', got %v: %v", tt.input, tt.err, token.Kind, token.Err)
+		} else if token.Line != tt.line || token.Col != tt.column {
+			t.Errorf("input %q: expected error at %d:%d, got %d:%d", tt.input, tt.line, tt.column, token.Line, token.Col)
+		}
+	}
+}
+
+func TestBlockStrings(t *testing.T) {
+	tests := []struct {
+		input string
+		value string
+	}{
+		{`""""""`, ""},
+		{`"""simple"""`, "simple"},
+		{`""" white space """`, " white space "},
+		{`"""contains " quote"""`, `contains " quote`},
+		{`"""contains \""" triple quote"""`, `contains """ triple quote`},
+		{`"""\""""""`, `"""`},
+		{`"""\"""\""""""`, `""""""`},
+		{"\"\"\"multi\nline\"\"\"", "multi\nline"},
+		{"\"\"\"multi\rline\r\nnormalized\"\"\"", "multi\nline\nnormalized"},
+		{`"""unescaped \n\r\b\t\fሴ"""`, `unescaped \n\r\b\t\fሴ`},
+		{`"""unescaped unicode outside BMP 😀"""`, "unescaped unicode outside BMP 😀"},
+		{`"""slashes \\ \/"""`, `slashes \\ \/`},
+		{"\"\"\"\n\n        spans\n          multiple\n            lines\n\n        \"\"\"", "spans\n  multiple\n    lines"},
+		{"\"\"\"\n    \\\"\"\"\n      indented\n    \"\"\"", "\"\"\"\n  indented"},
+	}
+	for _, tt := range tests {
+		l := NewLexer(NewInput([]byte(tt.input)))
+		token := l.Read()
+		if token.Kind != StringValueToken || !token.Block || token.Err != nil || value(l, token) != tt.value {
+			t.Errorf("input %q: expected block string %q, got %v %q (%v)", tt.input, tt.value, token.Kind, value(l, token), token.Err)
+		}
+		if token.End != len(tt.input) {
+			t.Errorf("input %q: expected token to end at %d, got %d", tt.input, len(tt.input), token.End)
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	input := "# first\n{ a #second\n#\n}"
+	l := NewLexer(NewInput([]byte(input)))
+	l.EmitComments(true)
+	expected := []struct {
+		kind  TokenKind
+		value string
+	}{
+		{CommentToken, " first"},
+		{PunctuatorToken, "{"},
+		{NameToken, "a"},
+		{CommentToken, "second"},
+		{CommentToken, ""},
+		{PunctuatorToken, "}"},
+		{EOFToken, ""},
+	}
+	for i, e := range expected {
+		token := l.Read()
+		if token.Kind != e.kind || value(l, token) != e.value {
+			t.Fatalf("token %d: expected %v %q, got %v %q", i, e.kind, e.value, token.Kind, value(l, token))
+		}
+		// the comments are skipped by PrevEnd
+		if e.value == "}" && l.PrevEnd() != 11 {
+			t.Errorf("expected the previous token to end at 11, got %d", l.PrevEnd())
+		}
+	}
+
+	l = NewLexer(NewInput([]byte(input)))
+	for _, kind := range []TokenKind{PunctuatorToken, NameToken, PunctuatorToken, EOFToken} {
+		if token := l.Read(); token.Kind != kind {
+			t.Fatalf("expected %v, got %v %q", kind, token.Kind, value(l, token))
+		}
+	}
+}
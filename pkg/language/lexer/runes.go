@@ -67,6 +67,24 @@ func isSourceCharacter(r rune) bool {
 		('\u0020' <= r && r <= '\uFFFF')
 }
 
+// isUnicodeScalarValue tells if the code point can be encoded, which is not true for the surrogates
+func isUnicodeScalarValue(r rune) bool {
+	return (0 <= r && r <= 0xD7FF) || (0xE000 <= r && r <= 0x10FFFF)
+}
+
+// hexDigit returns the value of the hex digit, or -1
+func hexDigit(r rune) rune {
+	switch {
+	case '0' <= r && r <= '9':
+		return r - '0'
+	case 'A' <= r && r <= 'F':
+		return r - 'A' + 10
+	case 'a' <= r && r <= 'f':
+		return r - 'a' + 10
+	}
+	return -1
+}
+
 func isLineTerminator(r rune) bool {
 	return r == runeNewLine || r == runeCarriageReturn
 }
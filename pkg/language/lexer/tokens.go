@@ -1,13 +1,16 @@
 package lexer
 
+// Token is a lexical token of the input, Start and End are its byte offsets, so Input.Value returns
+// the source of the token without copying it, and Input.StringValue the value of a string.
 type Token struct {
 	Kind TokenKind
 
-	Value string
-
 	Err        error
 	Start, End int
 	Line, Col  int
+
+	// Block is true for block strings
+	Block bool
 }
 
 // TokenKind tells what kind of value is in the Token
@@ -28,6 +31,8 @@ const (
 	FloatValueToken // Sign (opt) | IntegerPart | FractionalPart (ExponentPart)
 	// StringValueToken has string values
 	StringValueToken // "something which is a string" | """this is also valid"""
+	// CommentToken has the text of a comment after the '#', it's only read if the lexer emits comments
+	CommentToken
 )
 
 var tokenNames = []string{
@@ -38,6 +43,7 @@ var tokenNames = []string{
 	"IntValue",
 	"FloatValue",
 	"StringValue",
+	"Comment",
 }
 
 func (tk TokenKind) String() string {
@@ -2,7 +2,10 @@ package lexer
 
 import (
 	"bytes"
-	"log"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 )
 
 // String value
@@ -11,228 +14,318 @@ func (l *Lexer) readStringValue(t *Token) {
 	if l.peekEqual(runeQuotation, runeQuotation) {
 		l.readStringBlock(t)
 	} else {
-		l.readSingleLineString(t)
+		l.readSingleLineString(t, nil)
 	}
 }
 
 func (l *Lexer) readStringBlock(t *Token) {
+	t.Block = true
+	raw := l.input.raw
 	l.input.Pos += 3
-	t.Start = l.input.Pos
-	for {
-		if isSourceCharacter(l.input.PeekOne(0)) &&
-			!(l.input.PeekOne(0) == runeQuotation && l.peekEqual(runeQuotation, runeQuotation)) &&
-			!(l.input.PeekOne(0) == runeBackSlash && l.peekEqual(runeQuotation, runeQuotation, runeQuotation)) {
-			l.input.Pos++
-		} else if l.input.PeekOne(0) == runeQuotation && l.peekEqual(runeQuotation, runeQuotation) {
-			t.End = l.input.Pos
-			t.Value = string(BlockStringValue(l.input.raw[t.Start:t.End])) // string(l.input.raw[t.Start:t.End]) //
+	start := l.input.Pos
+	for l.input.Pos < len(raw) {
+		c := raw[l.input.Pos]
+		switch {
+		case c == runeQuotation && l.peekEqual(runeQuotation, runeQuotation):
+			l.input.countLines(start, l.input.Pos)
 			l.input.Pos += 3
+			t.End = l.input.Pos
 			return
-		} else {
-
-			//log.Printf("%v", l.input.PeekOne(0))
-			//log.Printf("got to else in block string: '%s'", string([]rune{l.input.PeekOne(0)}))
-			panic("this should not happen, undefined token")
-			// TODO: return undefined token
+		case c == runeBackSlash && l.peekEqual(runeQuotation, runeQuotation, runeQuotation):
+			l.input.Pos += 4
+		case c < runeSpace && c != runeHorizontalTab && !isLineTerminator(rune(c)):
+			l.input.countLines(start, l.input.Pos)
+			l.errorAt(t, l.input.Pos, "Invalid character within String: U+%04X", c)
+			return
+		case c < utf8.RuneSelf:
+			l.input.Pos++
+		default:
+			if !l.readSourceCharacter(t) {
+				l.input.countLines(start, l.input.Pos)
+				l.errorAt(t, l.input.Pos, "Invalid character within String: %s", invalidCharacter(raw[l.input.Pos:]))
+				return
+			}
 		}
 	}
+	l.input.countLines(start, l.input.Pos)
+	l.undefined(t, "Unterminated string")
 }
 
-func (l *Lexer) readSingleLineString(t *Token) {
+// readSingleLineString reads a string and writes its value to the builder if it's not nil,
+// the lexer only validates the strings, the values are built by Input.StringValue
+func (l *Lexer) readSingleLineString(t *Token, value *strings.Builder) {
+	raw := l.input.raw
 	l.input.Pos++
-	t.Start = l.input.Pos
-	for {
-		if isSourceCharacter(l.input.PeekOne(0)) && l.input.PeekOne(0) != runeQuotation && l.input.PeekOne(0) != runeBackSlash && !isLineTerminator(l.input.PeekOne(0)) {
-			t.Value += string(l.input.PeekOne(0))
-			l.input.Pos++
-		} else if l.input.PeekOne(0) == runeBackSlash &&
-			l.peekRules(
-				func(r rune) bool {
-					return r == runeU
-				},
-				func(r rune) bool {
-					return ('0' <= r && r <= '9') || ('A' <= r && r <= 'F') || ('a' <= r && r <= 'f')
-				},
-				func(r rune) bool {
-					return ('0' <= r && r <= '9') || ('A' <= r && r <= 'F') || ('a' <= r && r <= 'f')
-				},
-				func(r rune) bool {
-					return ('0' <= r && r <= '9') || ('A' <= r && r <= 'F') || ('a' <= r && r <= 'f')
-				},
-				func(r rune) bool {
-					return ('0' <= r && r <= '9') || ('A' <= r && r <= 'F') || ('a' <= r && r <= 'f')
-				},
-			) {
-			t.Value += string(l.input.PeekOne(0))
-			l.input.Pos += 6
-		} else if l.input.PeekOne(0) == runeBackSlash &&
-			l.peekRules(
-				func(r rune) bool {
-					return r == runeQuotation ||
-						r == runeBackSlash ||
-						r == '/' ||
-						r == 'b' ||
-						r == 'f' ||
-						r == 'n' ||
-						r == 'r' ||
-						r == 't'
-				},
-			) {
-			switch l.input.PeekOne(1) {
-			case '\\':
-				t.Value += "\\"
-			case '/':
-				t.Value += "/"
-			case 'b':
-				t.Value += "\b"
-			case 'f':
-				t.Value += "\f"
-			case 'n':
-				t.Value += "\n"
-			case 'r':
-				t.Value += "\r"
-			case 't':
-				t.Value += "\t"
+	chunk := l.input.Pos
+	for l.input.Pos < len(raw) {
+		c := raw[l.input.Pos]
+		switch {
+		case c == runeQuotation:
+			if value != nil {
+				value.Write(raw[chunk:l.input.Pos])
 			}
-			l.input.Pos += 2
-		} else if l.input.PeekOne(0) == runeQuotation {
-			t.End = l.input.Pos
 			l.input.Pos++
+			t.End = l.input.Pos
 			return
-		} else {
-			// TODO: return undefined token
+		case isLineTerminator(rune(c)):
+			l.undefined(t, "Unterminated string")
+			return
+		case c == runeBackSlash:
+			if value != nil {
+				value.Write(raw[chunk:l.input.Pos])
+			}
+			if !l.readEscapeSequence(t, value) {
+				return
+			}
+			chunk = l.input.Pos
+		case c < runeSpace && c != runeHorizontalTab:
+			l.errorAt(t, l.input.Pos, "Invalid character within String: U+%04X", c)
+			return
+		case c < utf8.RuneSelf:
+			l.input.Pos++
+		default:
+			if !l.readSourceCharacter(t) {
+				l.errorAt(t, l.input.Pos, "Invalid character within String: %s", invalidCharacter(raw[l.input.Pos:]))
+				return
+			}
 		}
 	}
+	l.undefined(t, "Unterminated string")
 }
 
-func BlockStringValue(s []byte) (formatted []byte) {
-	formatted = []byte{}
-	lines := bytes.FieldsFunc(s, isLineTerminator)
-	commonIndent := 0
-	for i, line := range lines {
-		if i == 0 {
-			continue
-		}
-		indent := len(line) - len(bytes.TrimLeftFunc(line, isWhitespace))
-		if indent < len(line) {
-			if commonIndent == 0 || indent < commonIndent {
-				commonIndent = indent
+// readSourceCharacter reads a multi-byte character, it returns false if the input
+// is not a valid UTF-8 encoded Unicode scalar value at the current position
+func (l *Lexer) readSourceCharacter(t *Token) bool {
+	r, size := utf8.DecodeRune(l.input.raw[l.input.Pos:])
+	if r == utf8.RuneError && size == 1 {
+		return false
+	}
+	l.input.Pos += size
+	return true
+}
+
+// invalidCharacter describes the invalid UTF-8 sequence at the start of bs, the encoded
+// surrogates are printed as code points, like U+DEAD, and other bytes as hex numbers
+func invalidCharacter(bs []byte) string {
+	if len(bs) >= 3 && bs[0] == 0xED && bs[1]&0xE0 == 0xA0 && bs[2]&0xC0 == 0x80 {
+		return fmt.Sprintf("U+%04X", 0xD000|rune(bs[1]&0x3F)<<6|rune(bs[2]&0x3F))
+	}
+	return fmt.Sprintf("invalid UTF-8 byte 0x%02X", bs[0])
+}
+
+// readEscapeSequence reads the escape sequence starting with the backslash at the current
+// position and writes the escaped character to the value if it's not nil, or makes t an Undefined token
+func (l *Lexer) readEscapeSequence(t *Token, value *strings.Builder) bool {
+	start := l.input.Pos
+	var c byte
+	switch l.input.PeekOne(1) {
+	case runeQuotation:
+		c = '"'
+	case runeBackSlash:
+		c = '\\'
+	case '/':
+		c = '/'
+	case 'b':
+		c = '\b'
+	case 'f':
+		c = '\f'
+	case 'n':
+		c = '\n'
+	case 'r':
+		c = '\r'
+	case 't':
+		c = '\t'
+	case runeU:
+		if l.input.PeekOne(2) == runeLeftBrace {
+			return l.readVariableWidthUnicode(t, value)
+		}
+		return l.readFixedWidthUnicode(t, value)
+	default:
+		end := start + 2
+		if end > len(l.input.raw) {
+			end = len(l.input.raw)
+		}
+		l.input.Pos = end
+		l.errorAt(t, start, "Invalid character escape sequence: \"%s\"", l.input.raw[start:end])
+		return false
+	}
+	if value != nil {
+		value.WriteByte(c)
+	}
+	l.input.Pos += 2
+	return true
+}
+
+// readVariableWidthUnicode reads an escape sequence like \u{1F600}, which must be
+// a Unicode scalar value with at most 8 hex digits
+func (l *Lexer) readVariableWidthUnicode(t *Token, value *strings.Builder) bool {
+	start := l.input.Pos
+	point := rune(0)
+	size := 3
+	for size < 12 {
+		r := l.input.PeekOne(size)
+		size++
+		if r == runeRightBrace {
+			if size < 5 || !isUnicodeScalarValue(point) {
+				break
+			}
+			if value != nil {
+				value.WriteRune(point)
 			}
+			l.input.Pos += size
+			return true
 		}
+		d := hexDigit(r)
+		if d < 0 {
+			break
+		}
+		point = point<<4 | d
 	}
-	log.Println("commonIndent", commonIndent)
-	if len(lines) != 0 && len(bytes.TrimLeftFunc(lines[0], isWhitespace)) == 0 {
-		if len(lines) > 1 {
-			lines = lines[1:]
-		} else {
-			lines = [][]byte{}
+	return l.invalidUnicodeEscape(t, start, size)
+}
+
+// readFixedWidthUnicode reads an escape sequence like \u00E9, the surrogates are only allowed
+// as a leading surrogate followed by an escaped trailing one, like \uD83D\uDE00
+func (l *Lexer) readFixedWidthUnicode(t *Token, value *strings.Builder) bool {
+	start := l.input.Pos
+	point := l.hexCode(2)
+	if isUnicodeScalarValue(point) {
+		if value != nil {
+			value.WriteRune(point)
 		}
+		l.input.Pos += 6
+		return true
 	}
-	if commonIndent != 0 {
-		for i, line := range lines {
-			//log.Printf("line: %v, indent: %v", len(line), commonIndent)
-			if len(line) >= commonIndent {
-				lines[i] = []byte(line)[commonIndent:]
+	if utf16.IsSurrogate(point) && point < 0xDC00 &&
+		l.input.PeekOne(6) == runeBackSlash && l.input.PeekOne(7) == runeU {
+		if r := utf16.DecodeRune(point, l.hexCode(8)); r != utf8.RuneError {
+			if value != nil {
+				value.WriteRune(r)
 			}
-			log.Printf("line: '%s'", lines[i])
+			l.input.Pos += 12
+			return true
 		}
 	}
-	if len(lines) != 0 && len(bytes.TrimLeftFunc(lines[len(lines)-1], isWhitespace)) == 0 {
-		if len(lines) > 1 {
-			lines = lines[:len(lines)-1]
-		} else {
-			lines = [][]byte{}
-		}
+	return l.invalidUnicodeEscape(t, start, 6)
+}
+
+func (l *Lexer) invalidUnicodeEscape(t *Token, start int, size int) bool {
+	end := start + size
+	if end > len(l.input.raw) {
+		end = len(l.input.raw)
 	}
-	for i, l := range lines {
-		if i == 0 {
-			formatted = append(formatted, l...)
-			continue
+	l.input.Pos = end
+	l.errorAt(t, start, "Invalid Unicode escape sequence: \"%s\"", l.input.raw[start:end])
+	return false
+}
+
+// hexCode returns the 16-bit value of the 4 hex digits at the offset, or -1
+func (l *Lexer) hexCode(offset int) rune {
+	code := rune(0)
+	for i := offset; i < offset+4; i++ {
+		d := hexDigit(l.input.PeekOne(i))
+		if d < 0 {
+			return -1
 		}
-		formatted = append(formatted, '\n')
-		formatted = append(formatted, l...)
+		code = code<<4 | d
 	}
-	return
+	return code
 }
 
-// Numeric value
-func (l *Lexer) readIntValue(t *Token) bool {
-	defer func() {
-		t.End = l.input.Pos
-	}()
-	if l.peekEqual(runeNegativeSign) {
-		l.input.Pos++
+// BlockStringValue returns the value of a block string, with the common indentation
+// and the leading and trailing blank lines removed
+func BlockStringValue(s []byte) []byte {
+	lines := [][]byte{}
+	for start, i := 0, 0; i <= len(s); i++ {
+		if i == len(s) || s[i] == runeNewLine || s[i] == runeCarriageReturn {
+			lines = append(lines, s[start:i])
+			if i+1 < len(s) && s[i] == runeCarriageReturn && s[i+1] == runeNewLine {
+				i++
+			}
+			start = i + 1
+		}
 	}
-	if l.input.PeekOne(0) == '0' {
-		if l.peekRules(func(r rune) bool {
-			return isDigit(r) || r == runeDot || isNameStart(r)
-		}) {
-			// TODO: return undefined token error
-			l.input.Pos = t.Start
-			return false
-		}
-		l.input.Pos += 2
-	} else if isNonZeroDigit(l.input.PeekOne(0)) {
-		l.input.Pos++
-		for isDigit(l.input.PeekOne(0)) {
-			l.input.Pos++
+
+	commonIndent := -1
+	for _, line := range lines[1:] {
+		indent := len(line) - len(bytes.TrimLeft(line, " \t"))
+		if indent < len(line) && (commonIndent == -1 || indent < commonIndent) {
+			commonIndent = indent
 		}
-		if l.input.PeekOne(0) == runeDot || isNameStart(l.input.PeekOne(0)) {
-			// TODO: return undefined token error
-			l.input.Pos = t.Start
-			return l.readFloatValue(t)
+	}
+	if commonIndent > 0 {
+		for i, line := range lines[1:] {
+			if len(line) >= commonIndent {
+				lines[i+1] = line[commonIndent:]
+			} else {
+				lines[i+1] = line[len(line):]
+			}
 		}
 	}
-	t.Kind = IntValueToken
-	t.End = l.input.Pos
-	return true
+
+	isBlank := func(line []byte) bool {
+		return len(bytes.TrimLeft(line, " \t")) == 0
+	}
+	for len(lines) != 0 && isBlank(lines[0]) {
+		lines = lines[1:]
+	}
+	for len(lines) != 0 && isBlank(lines[len(lines)-1]) {
+		lines = lines[:len(lines)-1]
+	}
+	return bytes.Join(lines, []byte{runeNewLine})
 }
 
 // Numeric value
-func (l *Lexer) readFloatValue(t *Token) bool {
-	defer func() {
-		t.End = l.input.Pos
-	}()
-	if l.peekEqual(runeNegativeSign) {
+func (l *Lexer) readNumber(t *Token) {
+	t.Kind = IntValueToken
+	if l.input.PeekOne(0) == runeNegativeSign {
 		l.input.Pos++
 	}
 	if l.input.PeekOne(0) == '0' {
-		if l.peekRules(func(r rune) bool {
-			return isDigit(r) || r == runeDot || isNameStart(r)
-		}) {
-			// TODO: return undefined token error
-			l.input.Pos = t.Start
-			return false
-		}
-		l.input.Pos += 2
-	} else if isNonZeroDigit(l.input.PeekOne(0)) {
 		l.input.Pos++
-		for isDigit(l.input.PeekOne(0)) {
-			l.input.Pos++
+		if isDigit(l.input.PeekOne(0)) {
+			l.undefined(t, "Invalid number, unexpected digit after 0: %q", l.input.PeekOne(0))
+			return
 		}
+	} else if !l.readDigits(t) {
+		return
 	}
-	if l.peekRules(
-		func(r rune) bool { return r == runeDot },
-		isDigit,
-	) {
+	if l.input.PeekOne(0) == runeDot {
+		t.Kind = FloatValueToken
 		l.input.Pos++
-		for isDigit(l.input.PeekOne(0)) {
-			l.input.Pos++
+		if !l.readDigits(t) {
+			return
 		}
 	}
-	if l.peekRules(
-		isExponentIndicator,
-		func(r rune) bool { return isDigit(r) || (r == runeNegativeSign || r == runePlusSign) },
-	) {
+	if isExponentIndicator(l.input.PeekOne(0)) {
+		t.Kind = FloatValueToken
 		l.input.Pos++
 		if l.input.PeekOne(0) == runeNegativeSign || l.input.PeekOne(0) == runePlusSign {
 			l.input.Pos++
 		}
-		for isDigit(l.input.PeekOne(0)) {
-			l.input.Pos++
+		if !l.readDigits(t) {
+			return
 		}
 	}
-	t.Kind = FloatValueToken
+	if r := l.input.PeekOne(0); r == runeDot || isNameStart(r) {
+		l.undefined(t, "Invalid number, expected digit but got: %q", r)
+		return
+	}
 	t.End = l.input.Pos
+}
+
+// readDigits reads at least one digit, or makes t an Undefined token
+func (l *Lexer) readDigits(t *Token) bool {
+	if !isDigit(l.input.PeekOne(0)) {
+		if l.input.Pos >= len(l.input.raw) {
+			l.undefined(t, "Invalid number, expected digit but got: <EOF>")
+		} else {
+			l.undefined(t, "Invalid number, expected digit but got: %q", l.input.PeekOne(0))
+		}
+		return false
+	}
+	for isDigit(l.input.PeekOne(0)) {
+		l.input.Pos++
+	}
 	return true
 }
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"sort"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+func (r *reader) comment(token tok) *ast.Comment {
+	c := &ast.Comment{
+		Value: token.Value,
+	}
+	if !r.opts.NoLocation {
+		c.Location = start(token)
+		c.Location.End = token.End
+	}
+	return c
+}
+
+// leadingComments returns the comments right before the token, each comment is returned only once
+func (r *reader) leadingComments(token tok) []*ast.Comment {
+	cs, ok := r.comments[token.Start]
+	if !ok {
+		return nil
+	}
+	delete(r.comments, token.Start)
+	return cs
+}
+
+// remainingComments returns the comments that are not the leading comments of any node,
+// in the order of the source
+func (r *reader) remainingComments() []*ast.Comment {
+	if len(r.comments) == 0 {
+		return nil
+	}
+	starts := make([]int, 0, len(r.comments))
+	for start := range r.comments {
+		starts = append(starts, start)
+	}
+	sort.Ints(starts)
+	cs := []*ast.Comment{}
+	for _, start := range starts {
+		cs = append(cs, r.comments[start]...)
+	}
+	r.comments = nil
+	return cs
+}
+
+// setComments sets the leading comments of a type system definition or extension
+func setComments(def ast.Definition, cs []*ast.Comment) {
+	switch def := def.(type) {
+	case *ast.SchemaDefinition:
+		def.Comments = cs
+	case *ast.ScalarDefinition:
+		def.Comments = cs
+	case *ast.ObjectDefinition:
+		def.Comments = cs
+	case *ast.InterfaceDefinition:
+		def.Comments = cs
+	case *ast.UnionDefinition:
+		def.Comments = cs
+	case *ast.EnumDefinition:
+		def.Comments = cs
+	case *ast.InputObjectDefinition:
+		def.Comments = cs
+	case *ast.DirectiveDefinition:
+		def.Comments = cs
+	case *ast.SchemaExtension:
+		def.Comments = cs
+	case *ast.ScalarExtension:
+		def.Comments = cs
+	case *ast.ObjectExtension:
+		def.Comments = cs
+	case *ast.InterfaceExtension:
+		def.Comments = cs
+	case *ast.UnionExtension:
+		def.Comments = cs
+	case *ast.EnumExtension:
+		def.Comments = cs
+	case *ast.InputObjectExtension:
+		def.Comments = cs
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rigglo/gql/pkg/language/lexer"
+)
+
+/*
+ParserError is a syntax error in a gql document. Besides the position of the error,
+it tells what token was expected and what was found instead, and holds an excerpt
+of the source with the position of the error marked, like
+
+	2 |   name(: String
+	  |        ^
+*/
+type ParserError struct {
+	Message  string
+	Line     int
+	Column   int
+	Token    lexer.Token
+	Expected string
+	Found    string
+	Excerpt  string
+}
+
+func (e *ParserError) Error() string {
+	return e.Message
+}
+
+func newError(token tok, format string, args ...interface{}) *ParserError {
+	return &ParserError{
+		Message: fmt.Sprintf(format, args...),
+		Line:    token.Line,
+		Column:  token.Col,
+		Token:   token.Token,
+		Found:   describe(token),
+	}
+}
+
+// expected returns an error for when something else was expected instead of the given token
+func expected(token tok, what string) *ParserError {
+	if token.Kind == lexer.Undefined && token.Err != nil {
+		err := newError(token, "Syntax Error: %s.", token.Err)
+		err.Expected = what
+		return err
+	}
+	err := newError(token, "Syntax Error: Expected %s, found %s.", what, describe(token))
+	err.Expected = what
+	return err
+}
+
+// unexpected returns an error for a token that is not allowed at its position
+func unexpected(token tok) *ParserError {
+	if token.Kind == lexer.Undefined && token.Err != nil {
+		return newError(token, "Syntax Error: %s.", token.Err)
+	}
+	return newError(token, "Syntax Error: Unexpected %s.", describe(token))
+}
+
+func asParserError(err error) *ParserError {
+	var perr *ParserError
+	if errors.As(err, &perr) {
+		return perr
+	}
+	return &ParserError{Message: err.Error()}
+}
+
+// describe returns a human readable form of the token for the error messages
+func describe(token tok) string {
+	switch token.Kind {
+	case lexer.EOFToken:
+		return "<EOF>"
+	case lexer.PunctuatorToken:
+		return strconv.Quote(token.Value)
+	case lexer.NameToken:
+		return "Name " + strconv.Quote(token.Value)
+	case lexer.IntValueToken:
+		return "Int " + strconv.Quote(token.Value)
+	case lexer.FloatValueToken:
+		return "Float " + strconv.Quote(token.Value)
+	case lexer.StringValueToken:
+		return "String " + strconv.Quote(token.Value)
+	}
+	return strconv.Quote(token.Value)
+}
+
+// withExcerpt sets the excerpt of the error using the parsed source
+func (e *ParserError) withExcerpt(source []byte) *ParserError {
+	if e.Line < 1 {
+		return e
+	}
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(string(source)), "\n")
+	if e.Line > len(lines) {
+		return e
+	}
+
+	gutter := len(strconv.Itoa(e.Line))
+	sb := strings.Builder{}
+	if e.Line > 1 && strings.TrimSpace(lines[e.Line-2]) != "" {
+		fmt.Fprintf(&sb, "%*d | %s\n", gutter, e.Line-1, lines[e.Line-2])
+	}
+	fmt.Fprintf(&sb, "%*d | %s\n", gutter, e.Line, lines[e.Line-1])
+	// keep the tabs in the padding, so the caret lines up with the token
+	pad := []byte(lines[e.Line-1])
+	if e.Column-1 < len(pad) {
+		pad = pad[:e.Column-1]
+	}
+	for i := range pad {
+		if pad[i] != '\t' {
+			pad[i] = ' '
+		}
+	}
+	fmt.Fprintf(&sb, "%*s | %s^", gutter, "", pad)
+	e.Excerpt = sb.String()
+	return e
+}
@@ -0,0 +1,168 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/lexer"
+)
+
+/*
+Options to limit the resources used for parsing a document, which is useful when the documents
+come from untrusted sources. The zero value of a limit means there's no limit.
+*/
+type Options struct {
+	// MaxTokens is the maximum number of tokens in the document
+	MaxTokens int
+	// MaxDepth is the maximum nesting depth of the selection sets, lists, input objects
+	// and list types, the number of the open braces and brackets at any point of the document
+	MaxDepth int
+	// NoLocation disables recording the locations of the AST nodes, the syntax errors
+	// still have their locations
+	NoLocation bool
+	// Comments keeps the comments of the document in the AST, as the leading comments of
+	// the nodes or in the Comments of the document, for tools that print the document again
+	Comments bool
+}
+
+/*
+ParseWithOptions parses a gql document like Parse, but stops at the first token that exceeds
+a limit of the options with a *ParserError, so a hostile document can not use much CPU time
+or stack before it's rejected
+*/
+func ParseWithOptions(document []byte, opts Options) (*ast.Document, error) {
+	lex := newReader(lexer.NewLexer(lexer.NewInput(document)), opts)
+	doc, errs := parseDocument(lex, false)
+	if len(errs) != 0 {
+		return nil, errs[0].withExcerpt(document)
+	}
+	return doc, nil
+}
+
+// tok is a token of the lexer with its value, the lexer only returns the spans of the tokens
+type tok struct {
+	lexer.Token
+	Value string
+}
+
+// punctuators has the values of the single character punctuators
+var punctuators = [...]string{
+	'!': "!", '$': "$", '&': "&", '(': "(", ')': ")", ':': ":", '=': "=", '@': "@",
+	'[': "[", ']': "]", '{': "{", '|': "|", '}': "}",
+}
+
+// reader reads the tokens for the parser from the lexer and enforces the limits of the options
+type reader struct {
+	*lexer.Lexer
+	opts   Options
+	tokens int
+	depth  int
+	// aborted is set after a limit is exceeded, the rest of the document is not read,
+	// and eof is returned for all the tokens after that
+	aborted bool
+	eof     tok
+	// comments before the tokens by their start offsets, with the Comments option
+	comments map[int][]*ast.Comment
+	pending  []*ast.Comment
+	// selections is the stack of the selections of the selection sets being parsed
+	selections []ast.Selection
+	// names caches the values of the recent names by their hashes, so the repeated names,
+	// like the common field and type names, are mostly allocated only once
+	names [256]string
+}
+
+func newReader(lex *lexer.Lexer, opts Options) *reader {
+	lex.EmitComments(opts.Comments)
+	return &reader{
+		Lexer: lex,
+		opts:  opts,
+	}
+}
+
+func (r *reader) Read() (token tok) {
+	if r.aborted {
+		return r.eof
+	}
+	r.next(&token)
+	for token.Kind == lexer.CommentToken {
+		r.tokens++
+		r.pending = append(r.pending, r.comment(token))
+		r.next(&token)
+	}
+	if len(r.pending) != 0 {
+		if r.comments == nil {
+			r.comments = map[int][]*ast.Comment{}
+		}
+		r.comments[token.Start] = r.pending
+		r.pending = nil
+	}
+
+	if token.Kind != lexer.EOFToken {
+		r.tokens++
+	}
+	if token.Kind == lexer.PunctuatorToken {
+		switch token.Value {
+		case "{", "[":
+			r.depth++
+		case "}", "]":
+			r.depth--
+		}
+	}
+
+	if r.opts.MaxTokens > 0 && r.tokens > r.opts.MaxTokens {
+		return r.abort(token, "Document contains more than %d tokens, parsing aborted", r.opts.MaxTokens)
+	} else if r.opts.MaxDepth > 0 && r.depth > r.opts.MaxDepth {
+		return r.abort(token, "Document exceeds the maximum depth of %d, parsing aborted", r.opts.MaxDepth)
+	}
+	return token
+}
+
+// next reads the next token of the lexer with its value into t
+func (r *reader) next(t *tok) {
+	t.Token = r.Lexer.Read()
+	in := r.Input()
+	switch t.Kind {
+	case lexer.EOFToken:
+		t.Value = ""
+	case lexer.NameToken:
+		t.Value = r.intern(in.Value(&t.Token))
+	case lexer.PunctuatorToken:
+		if t.End-t.Start == 1 {
+			t.Value = punctuators[in.Value(&t.Token)[0]]
+		} else {
+			t.Value = "..."
+		}
+	case lexer.StringValueToken:
+		t.Value = in.StringValue(&t.Token)
+	case lexer.CommentToken:
+		t.Value = string(in.Value(&t.Token)[1:])
+	default:
+		t.Value = string(in.Value(&t.Token))
+	}
+}
+
+// intern returns the bytes as a string, the same string for the bytes of a cached name, the names
+// are hashed by their lengths and their first and last bytes, a new name replaces the cached one
+func (r *reader) intern(bs []byte) string {
+	h := len(bs)*31 + int(bs[0])*7 + int(bs[len(bs)-1])
+	s := &r.names[h%len(r.names)]
+	if *s != string(bs) {
+		*s = string(bs)
+	}
+	return *s
+}
+
+// abort returns the token as an undefined one with the error, so the parser stops at it
+func (r *reader) abort(token tok, format string, args ...interface{}) tok {
+	r.aborted = true
+	r.eof = tok{Token: lexer.Token{
+		Kind:  lexer.EOFToken,
+		Start: token.Start,
+		End:   token.Start,
+		Line:  token.Line,
+		Col:   token.Col,
+	}}
+	token.Kind = lexer.Undefined
+	token.Err = fmt.Errorf(format, args...)
+	return token
+}
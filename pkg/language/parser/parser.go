@@ -1,196 +1,309 @@
 package parser
 
 import (
-	"fmt"
-	"log"
-	"strings"
-
 	"github.com/rigglo/gql/pkg/language/ast"
 	"github.com/rigglo/gql/pkg/language/lexer"
 )
 
-type ParserError struct {
-	Message string
-	Line    int
-	Column  int
-	Token   lexer.Token
+// Parse parses a gql document and returns the first syntax error as a *ParserError
+func Parse(document []byte) (*ast.Document, error) {
+	return ParseWithOptions(document, Options{})
 }
 
-func (e *ParserError) Error() string {
-	return e.Message
+/*
+ParseWithRecovery parses a gql document, but instead of stopping at the first syntax error,
+it skips to the next definition and continues parsing, so all the diagnostics from the document
+can be reported at once (useful for editor tooling). The returned document contains all the
+definitions that could be parsed successfully.
+*/
+func ParseWithRecovery(document []byte) (*ast.Document, []*ParserError) {
+	lex := newReader(lexer.NewLexer(lexer.NewInput(document)), Options{})
+	doc, errs := parseDocument(lex, true)
+	for _, err := range errs {
+		err.withExcerpt(document)
+	}
+	return doc, errs
 }
 
-// Parse parses a gql document
-func Parse(document []byte) (*ast.Document, error) {
-	lex := lexer.NewLexer(lexer.NewInput(document))
-	t, doc, err := parseDocument(lex)
-	if err != nil && t.Value == "" {
-		return nil, &ParserError{
-			Message: err.Error(),
-			Line:    t.Line,
-			Column:  t.Col,
-			Token:   t,
-		}
+// ParseDefinition parses a single schema, type, directive definition or a type system extension
+func ParseDefinition(definition []byte) (ast.Definition, error) {
+	def, err := parseSingleDefinition(newReader(lexer.NewLexer(lexer.NewInput(definition)), Options{}))
+	if err != nil {
+		return nil, asParserError(err).withExcerpt(definition)
 	}
-	return doc, nil
+	return def, nil
 }
 
-// ParseDefinition parses a single schema, type, directive definition
-func ParseDefinition(definition []byte) (ast.Definition, error) {
-	lex := lexer.NewLexer(lexer.NewInput(definition))
+// ParseValue parses a value literal on its own, like '{a: [1, 2], b: ENUM}', which can contain variables
+func ParseValue(value []byte) (ast.Value, error) {
+	var v ast.Value
+	err := parseStandalone(value, func(token tok, lex *reader) (tok, error) {
+		var err error
+		token, v, err = parseValue(token, lex, false)
+		return token, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ParseConstValue parses a value literal like ParseValue, but returns an error if it contains variables
+func ParseConstValue(value []byte) (ast.Value, error) {
+	var v ast.Value
+	err := parseStandalone(value, func(token tok, lex *reader) (tok, error) {
+		var err error
+		token, v, err = parseValue(token, lex, true)
+		return token, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// ParseType parses a type reference on its own, like '[String!]!'
+func ParseType(typ []byte) (ast.Type, error) {
+	var t ast.Type
+	err := parseStandalone(typ, func(token tok, lex *reader) (tok, error) {
+		var err error
+		token, t, err = parseType(token, lex)
+		return token, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// parseStandalone parses the source with the function, and checks that the whole source was parsed
+func parseStandalone(source []byte, parse func(token tok, lex *reader) (tok, error)) error {
+	lex := newReader(lexer.NewLexer(lexer.NewInput(source)), Options{})
+	token, err := parse(lex.Read(), lex)
+	if err == nil && token.Kind != lexer.EOFToken {
+		err = expected(token, "<EOF>")
+	}
+	if err != nil {
+		return asParserError(err).withExcerpt(source)
+	}
+	return nil
+}
+
+// start returns the location of a node that starts with the token
+func start(token tok) ast.Location {
+	return ast.Location{
+		Line:   token.Line,
+		Column: token.Col,
+		Start:  token.Start,
+	}
+}
+
+// finish sets the end of the location to the end of the last token of the node, or clears
+// the location if the locations are disabled, it must be called when the token after the
+// node is the last one read
+func finish(loc *ast.Location, lex *reader) {
+	if lex.opts.NoLocation {
+		*loc = ast.Location{}
+		return
+	}
+	loc.End = lex.PrevEnd()
+}
+
+func parseSingleDefinition(lex *reader) (ast.Definition, error) {
 	token := lex.Read()
+	loc := start(token)
 
 	desc := ""
 	if token.Kind == lexer.StringValueToken {
-		desc = strings.Trim(token.Value, `"`)
+		desc = token.Value
 		token = lex.Read()
 		if token.Kind == lexer.NameToken && token.Value == "schema" {
-			return nil, &ParserError{
-				Message: "expected everything but 'schema'.. a Schema does NOT have description",
-				Line:    token.Line,
-				Column:  token.Col,
-				Token:   token,
-			}
+			return nil, newError(token, "Syntax Error: a schema definition does not have a description.")
 		}
 	}
+
+	var (
+		def ast.Definition
+		err error
+	)
 	if token.Kind == lexer.NameToken && token.Value == "schema" {
-		t, def, err := parseSchema(lex.Read(), lex)
+		token, def, err = parseSchema(token, lex, loc)
+	} else if token.Kind == lexer.NameToken && token.Value == "extend" && desc == "" {
+		token, def, err = parseExtension(lex.Read(), lex, loc)
+	} else {
+		token, def, err = parseDefinition(token, lex, desc, loc)
+	}
+	if err != nil {
+		return nil, err
+	} else if token.Kind != lexer.EOFToken {
+		return nil, expected(token, "<EOF>")
+	}
+	return def, nil
+}
+
+func parseDocument(lex *reader, recovery bool) (*ast.Document, []*ParserError) {
+	doc := ast.NewDocument()
+	errs := []*ParserError{}
+	token := lex.Read()
+	for token.Kind != lexer.EOFToken {
+		start := token
+		var err error
+		token, err = parseDocumentDefinition(token, lex, doc)
 		if err != nil {
-			return nil, err
-		} else if t.Kind != lexer.EOFToken && err == nil {
-			return nil, &ParserError{
-				Message: fmt.Sprintf("invalid token after schema definition: '%s'", t.Value),
-				Line:    token.Line,
-				Column:  token.Col,
-				Token:   token,
+			errs = append(errs, asParserError(err))
+			if !recovery {
+				return doc, errs
 			}
+			token = synchronize(token, start, lex)
 		}
-		return def, nil
 	}
-	t, def, err := parseDefinition(token, lex, desc)
-	if err != nil {
-		return nil, &ParserError{
-			Message: err.Error(),
-			Line:    token.Line,
-			Column:  token.Col,
-			Token:   token,
+	doc.Comments = lex.remainingComments()
+	return doc, errs
+}
+
+// synchronize skips tokens after a syntax error until one that could start a new definition:
+// either one outside of all the braces, or one in a later line that is not indented more than
+// the definition that failed
+func synchronize(token tok, start tok, lex *reader) tok {
+	for token.Kind != lexer.EOFToken {
+		if token.Start > start.Start && startsDefinition(token) &&
+			(lex.Depth() == 0 || (token.Line > start.Line && token.Col <= start.Col)) {
+			return token
 		}
-	} else if t.Kind != lexer.EOFToken && err == nil {
-		return nil, &ParserError{
-			Message: fmt.Sprintf("invalid token after definition: '%s'", t.Value),
-			Line:    token.Line,
-			Column:  token.Col,
-			Token:   token,
+		token = lex.Read()
+	}
+	return token
+}
+
+func startsDefinition(token tok) bool {
+	switch token.Kind {
+	case lexer.StringValueToken:
+		return true
+	case lexer.PunctuatorToken:
+		return token.Value == "{"
+	case lexer.NameToken:
+		switch token.Value {
+		case "query", "mutation", "subscription", "fragment", "schema", "extend",
+			"scalar", "type", "interface", "union", "enum", "input", "directive":
+			return true
 		}
 	}
-	return def, nil
+	return false
 }
 
-func parseDocument(lex *lexer.Lexer) (lexer.Token, *ast.Document, error) {
-	doc := ast.NewDocument()
+func parseDocumentDefinition(token tok, lex *reader, doc *ast.Document) (tok, error) {
 	var err error
-	token := lex.Read()
-	for {
-		switch {
-		case token.Kind == lexer.NameToken:
-			switch token.Value {
-			case "fragment":
-				{
-					f := new(ast.Fragment)
-					token, f, err = parseFragment(lex)
-					if err != nil {
-						return token, nil, err
-					}
-					doc.Fragments = append(doc.Fragments, f)
-				}
-			case "query", "mutation", "subscription":
-				{
-					op := new(ast.Operation)
-					token, op, err = parseOperation(token, lex)
-					if err != nil {
-						return token, nil, err
-					}
-					doc.Operations = append(doc.Operations, op)
-				}
-			case "scalar", "type", "interface", "union", "enum", "input", "directive":
-				var def ast.Definition
-				token, def, err = parseDefinition(token, lex, "")
-				if err != nil {
-					return token, nil, err
-				}
-				doc.Definitions = append(doc.Definitions, def)
-			case "schema":
-				var def ast.Definition
-				token, def, err = parseSchema(token, lex)
-				if err != nil {
-					return token, nil, err
-				}
-				doc.Definitions = append(doc.Definitions, def)
-			default:
-				return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+	comments := lex.leadingComments(token)
+	switch {
+	case token.Kind == lexer.NameToken:
+		switch token.Value {
+		case "fragment":
+			var f *ast.Fragment
+			token, f, err = parseFragment(token, lex)
+			if err != nil {
+				return token, err
 			}
-			break
-		case token.Kind == lexer.StringValueToken:
-			desc := strings.Trim(token.Value, `"`)
-			token = lex.Read()
-			switch token.Value {
-			case "scalar", "type", "interface", "union", "enum", "input", "directive":
-				var def ast.Definition
-				token, def, err = parseDefinition(token, lex, desc)
-				if err != nil {
-					return token, nil, err
-				}
-				doc.Definitions = append(doc.Definitions, def)
-			default:
-				return token, nil, fmt.Errorf("unexpected token: '%s', kind: %v", token.Value, token.Kind)
+			f.Comments = comments
+			doc.Fragments = append(doc.Fragments, f)
+		case "query", "mutation", "subscription":
+			var op *ast.Operation
+			token, op, err = parseOperation(token, lex)
+			if err != nil {
+				return token, err
 			}
-			break
-		case token.Kind == lexer.PunctuatorToken && token.Value == "{":
-			set := []ast.Selection{}
-			token, set, err = parseSelectionSet(lex)
+			op.Comments = comments
+			doc.Operations = append(doc.Operations, op)
+		case "scalar", "type", "interface", "union", "enum", "input", "directive":
+			var def ast.Definition
+			token, def, err = parseDefinition(token, lex, "", start(token))
 			if err != nil {
-				return token, nil, err
+				return token, err
 			}
-			doc.Operations = append(doc.Operations, &ast.Operation{
-				OperationType: ast.Query,
-				SelectionSet:  set,
-			})
-			break
-		case token.Kind == lexer.EOFToken && token.Err == nil:
-			return token, doc, nil
+			setComments(def, comments)
+			doc.Definitions = append(doc.Definitions, def)
+		case "schema":
+			var def ast.Definition
+			token, def, err = parseSchema(token, lex, start(token))
+			if err != nil {
+				return token, err
+			}
+			setComments(def, comments)
+			doc.Definitions = append(doc.Definitions, def)
+		case "extend":
+			var def ast.Definition
+			loc := start(token)
+			token, def, err = parseExtension(lex.Read(), lex, loc)
+			if err != nil {
+				return token, err
+			}
+			setComments(def, comments)
+			doc.Definitions = append(doc.Definitions, def)
+		default:
+			return token, unexpected(token)
+		}
+	case token.Kind == lexer.StringValueToken:
+		desc, loc := token.Value, start(token)
+		token = lex.Read()
+		switch token.Value {
+		case "scalar", "type", "interface", "union", "enum", "input", "directive":
+			var def ast.Definition
+			token, def, err = parseDefinition(token, lex, desc, loc)
+			if err != nil {
+				return token, err
+			}
+			setComments(def, comments)
+			doc.Definitions = append(doc.Definitions, def)
 		default:
-			return token, nil, fmt.Errorf("unexpected token: %s, kind: %v", token.Value, token.Kind)
+			return token, expected(token, "a definition")
+		}
+	case token.Kind == lexer.PunctuatorToken && token.Value == "{":
+		op := &ast.Operation{
+			OperationType: ast.Query,
+			Location:      start(token),
+			Comments:      comments,
+		}
+		token, op.SelectionSet, err = parseSelectionSet(lex)
+		if err != nil {
+			return token, err
 		}
+		finish(&op.Location, lex)
+		doc.Operations = append(doc.Operations, op)
+	default:
+		return token, unexpected(token)
 	}
+	return token, nil
 }
 
-func parseDefinition(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+// parseDefinition parses a type or directive definition, the location is the start of the definition,
+// including its description
+func parseDefinition(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	switch token.Value {
 	case "scalar":
-		return parseScalar(lex.Read(), lex, desc)
+		return parseScalar(lex.Read(), lex, desc, loc)
 	case "type":
-		return parseObject(lex.Read(), lex, desc)
+		return parseObject(lex.Read(), lex, desc, loc)
 	case "interface":
-		return parseInterface(lex.Read(), lex, desc)
+		return parseInterface(lex.Read(), lex, desc, loc)
 	case "union":
-		return parseUnion(lex.Read(), lex, desc)
+		return parseUnion(lex.Read(), lex, desc, loc)
 	case "enum":
-		return parseEnum(lex.Read(), lex, desc)
+		return parseEnum(lex.Read(), lex, desc, loc)
 	case "input":
-		return parseInputObject(lex.Read(), lex, desc)
+		return parseInputObject(lex.Read(), lex, desc, loc)
 	case "directive":
-		return parseDirectiveDefinition(lex.Read(), lex, desc)
+		return parseDirectiveDefinition(lex.Read(), lex, desc, loc)
 	}
-	return token, nil, fmt.Errorf("expected a schema, type or directive definition, got: '%s', err: '%v'", token.Value, token.Err)
+	return token, nil, expected(token, "a schema, type or directive definition")
 }
 
-func parseSchema(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Definition, error) {
-	def := new(ast.SchemaDefinition)
+func parseSchema(token tok, lex *reader, loc ast.Location) (tok, ast.Definition, error) {
+	def := &ast.SchemaDefinition{
+		Location: loc,
+	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -200,7 +313,7 @@ func parseSchema(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Definiti
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
 			return token, nil, err
 		}
@@ -208,95 +321,215 @@ func parseSchema(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Definiti
 	}
 
 	if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
-		def.RootOperations = map[ast.OperationType]*ast.NamedType{}
+		var err error
+		token, def.RootOperations, err = parseRootOperations(lex)
+		if err != nil {
+			return token, nil, err
+		}
+		finish(&def.Location, lex)
+		return token, def, nil
+	}
+	return token, nil, expected(token, "\"{\"")
+}
 
-		token = lex.Read()
-		for {
-			// quit if it's the end of the field definition list
-			if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
-				return lex.Read(), def, nil
-			}
+func parseRootOperations(lex *reader) (tok, map[ast.OperationType]*ast.NamedType, error) {
+	ops := map[ast.OperationType]*ast.NamedType{}
 
-			var ot ast.OperationType
+	token := lex.Read()
+	for {
+		// quit if it's the end of the root operation type list
+		if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
+			return lex.Read(), ops, nil
+		}
 
-			// parse operation type
-			if token.Kind == lexer.NameToken {
-				switch token.Value {
-				case "query":
-					ot = ast.Query
-				case "mutation":
-					ot = ast.Mutation
-				case "subscription":
-					ot = ast.Subscription
-				default:
-					return token, nil, fmt.Errorf("expected root operation type, one of 'query', 'mutation' or 'subscription', got '%s'", token.Value)
-				}
-				if _, ok := def.RootOperations[ot]; ok {
-					return token, nil, fmt.Errorf("the given operation type '%s' is already defined in the schema definition", token.Value)
-				}
-				token = lex.Read()
-			} else {
-				return token, nil, fmt.Errorf("expected NameToken, got '%s'", token.Value)
-			}
+		var ot ast.OperationType
 
-			if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
-				token = lex.Read()
-			} else {
-				return token, nil, fmt.Errorf("expected token ':', got '%s'", token.Value)
+		// parse operation type
+		if token.Kind == lexer.NameToken {
+			switch token.Value {
+			case "query":
+				ot = ast.Query
+			case "mutation":
+				ot = ast.Mutation
+			case "subscription":
+				ot = ast.Subscription
+			default:
+				return token, nil, expected(token, "one of \"query\", \"mutation\" or \"subscription\"")
 			}
-
-			if token.Kind == lexer.NameToken {
-				def.RootOperations[ot] = &ast.NamedType{
-					Name: token.Value,
-					Location: ast.Location{
-						Column: token.Col,
-						Line:   token.Line,
-					},
-				}
-				token = lex.Read()
-			} else {
-				return token, nil, fmt.Errorf("expected NameToken, got '%s'", token.Value)
+			if _, ok := ops[ot]; ok {
+				return token, nil, newError(token, "Syntax Error: the operation type %q is already defined in the schema definition.", token.Value)
 			}
+			token = lex.Read()
+		} else {
+			return token, nil, expected(token, "Name")
+		}
+
+		if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
+			token = lex.Read()
+		} else {
+			return token, nil, expected(token, "\":\"")
+		}
+
+		if token.Kind == lexer.NameToken {
+			token, ops[ot] = parseNamedType(token, lex)
+		} else {
+			return token, nil, expected(token, "Name")
+		}
+	}
+}
+
+// parseExtension parses a type system extension, the token is the one after 'extend',
+// and the location is the start of 'extend'
+func parseExtension(token tok, lex *reader, loc ast.Location) (tok, ast.Definition, error) {
+	if token.Kind != lexer.NameToken {
+		return token, nil, expected(token, "a type system extension")
+	}
+
+	var (
+		def ast.Definition
+		err error
+	)
+	switch token.Value {
+	case "schema":
+		return parseSchemaExtension(lex, loc)
+	case "scalar", "type", "interface", "union", "enum", "input":
+		token, def, err = parseDefinition(token, lex, "", loc)
+		if err != nil {
+			return token, nil, err
+		}
+	default:
+		return token, nil, expected(token, "a type system extension")
+	}
+
+	// an extension must add something to the type
+	var ext ast.Definition
+	empty := false
+	switch def := def.(type) {
+	case *ast.ScalarDefinition:
+		ext = &ast.ScalarExtension{
+			Name:       def.Name,
+			Directives: def.Directives,
+			Location:   def.Location,
+		}
+		empty = len(def.Directives) == 0
+	case *ast.ObjectDefinition:
+		ext = &ast.ObjectExtension{
+			Name:       def.Name,
+			Implements: def.Implements,
+			Directives: def.Directives,
+			Fields:     def.Fields,
+			Location:   def.Location,
+		}
+		empty = len(def.Implements) == 0 && len(def.Directives) == 0 && len(def.Fields) == 0
+	case *ast.InterfaceDefinition:
+		ext = &ast.InterfaceExtension{
+			Name:       def.Name,
+			Directives: def.Directives,
+			Fields:     def.Fields,
+			Location:   def.Location,
+		}
+		empty = len(def.Directives) == 0 && len(def.Fields) == 0
+	case *ast.UnionDefinition:
+		ext = &ast.UnionExtension{
+			Name:       def.Name,
+			Directives: def.Directives,
+			Members:    def.Members,
+			Location:   def.Location,
+		}
+		empty = len(def.Directives) == 0 && len(def.Members) == 0
+	case *ast.EnumDefinition:
+		ext = &ast.EnumExtension{
+			Name:       def.Name,
+			Directives: def.Directives,
+			Values:     def.Values,
+			Location:   def.Location,
+		}
+		empty = len(def.Directives) == 0 && len(def.Values) == 0
+	case *ast.InputObjectDefinition:
+		ext = &ast.InputObjectExtension{
+			Name:       def.Name,
+			Directives: def.Directives,
+			Fields:     def.Fields,
+			Location:   def.Location,
+		}
+		empty = len(def.Directives) == 0 && len(def.Fields) == 0
+	}
+	if empty {
+		return token, nil, unexpected(token)
+	}
+	return token, ext, nil
+}
+
+func parseSchemaExtension(lex *reader, loc ast.Location) (tok, ast.Definition, error) {
+	ext := &ast.SchemaExtension{
+		Location: loc,
+	}
+	token := lex.Read()
+
+	if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
+		var (
+			ds  []*ast.Directive
+			err error
+		)
+		token, ds, err = parseDirectives(token, lex)
+		if err != nil {
+			return token, nil, err
+		}
+		ext.Directives = ds
+	}
+
+	if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
+		var err error
+		token, ext.RootOperations, err = parseRootOperations(lex)
+		if err != nil {
+			return token, nil, err
 		}
+	} else if len(ext.Directives) == 0 {
+		return token, nil, unexpected(token)
 	}
-	return token, nil, fmt.Errorf("expected '{', and a list of root operation types, got '%s'", token.Value)
+	finish(&ext.Location, lex)
+	return token, ext, nil
 }
 
-func parseScalar(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseScalar(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.ScalarDefinition{
 		Description: desc,
+		Location:    loc,
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
 
 	if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
-		token, ds, err := parseDirectives(lex)
+		var (
+			ds  []*ast.Directive
+			err error
+		)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
 			return token, nil, err
 		}
 		def.Directives = ds
-		return token, def, nil
 	}
 
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseObject(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.ObjectDefinition{
 		Description: desc,
+		Location:    loc,
 		Fields:      []*ast.FieldDefinition{},
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -306,14 +539,9 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 		ints := []*ast.NamedType{}
 		for {
 			if token.Kind == lexer.NameToken {
-				ints = append(ints, &ast.NamedType{
-					Name: token.Value,
-					Location: ast.Location{
-						Column: token.Col,
-						Line:   token.Line,
-					},
-				})
-				token = lex.Read()
+				var nt *ast.NamedType
+				token, nt = parseNamedType(token, lex)
+				ints = append(ints, nt)
 			} else if token.Kind == lexer.PunctuatorToken && token.Value == "&" {
 				token = lex.Read()
 			} else {
@@ -321,7 +549,7 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 			}
 		}
 		if len(ints) == 0 {
-			return token, nil, fmt.Errorf("language error..")
+			return token, nil, expected(token, "Name")
 		}
 		def.Implements = ints
 	}
@@ -331,9 +559,9 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
-			return token, nil, fmt.Errorf("couldn't parse directives: %v", err)
+			return token, nil, err
 		}
 		def.Directives = ds
 	}
@@ -341,11 +569,19 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 	if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
 		token = lex.Read()
 		for {
-			// quit if it's the end of the field definition list
+			// quit if it's the end of the field definition list, which can't be empty
 			if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
-				return lex.Read(), def, nil
+				if len(def.Fields) == 0 {
+					return token, nil, expected(token, "Name")
+				}
+				token = lex.Read()
+				finish(&def.Location, lex)
+				return token, def, nil
+			}
+			field := &ast.FieldDefinition{
+				Location: start(token),
+				Comments: lex.leadingComments(token),
 			}
-			field := &ast.FieldDefinition{}
 
 			// parse optional description
 			if token.Kind == lexer.StringValueToken {
@@ -356,7 +592,7 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 				field.Name = token.Value
 				token = lex.Read()
 			} else {
-				return token, nil, fmt.Errorf("expected NameToken, got token kind '%v', with value '%s'", token.Kind, token.Value)
+				return token, nil, expected(token, "Name")
 			}
 
 			// parse arguments definition
@@ -383,7 +619,7 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 			if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
 				token = lex.Read()
 			} else {
-				return token, nil, fmt.Errorf("expected ':', got token kind '%v', with value '%s'", token.Kind, token.Value)
+				return token, nil, expected(token, "\":\"")
 			}
 
 			// parse the type of the field
@@ -403,30 +639,32 @@ func parseObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 					ds  []*ast.Directive
 					err error
 				)
-				token, ds, err = parseDirectives(lex)
+				token, ds, err = parseDirectives(token, lex)
 				if err != nil {
 					return token, nil, err
 				}
 				field.Directives = ds
 			}
 
+			finish(&field.Location, lex)
 			def.Fields = append(def.Fields, field)
 		}
 	}
 
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseInterface(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseInterface(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.InterfaceDefinition{
 		Description: desc,
+		Location:    loc,
 		Fields:      []*ast.FieldDefinition{},
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -436,9 +674,9 @@ func parseInterface(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Tok
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
-			return token, nil, fmt.Errorf("couldn't parse directives: %v", err)
+			return token, nil, err
 		}
 		def.Directives = ds
 	}
@@ -446,22 +684,30 @@ func parseInterface(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Tok
 	if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
 		token = lex.Read()
 		for {
-			// quit if it's the end of the field definition list
+			// quit if it's the end of the field definition list, which can't be empty
 			if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
-				return lex.Read(), def, nil
+				if len(def.Fields) == 0 {
+					return token, nil, expected(token, "Name")
+				}
+				token = lex.Read()
+				finish(&def.Location, lex)
+				return token, def, nil
+			}
+			field := &ast.FieldDefinition{
+				Location: start(token),
+				Comments: lex.leadingComments(token),
 			}
-			field := &ast.FieldDefinition{}
 
 			// parse optional description
 			if token.Kind == lexer.StringValueToken {
-				field.Description = strings.Trim(token.Value, `"`)
+				field.Description = token.Value
 				token = lex.Read()
 			}
 			if token.Kind == lexer.NameToken {
 				field.Name = token.Value
 				token = lex.Read()
 			} else {
-				return token, nil, fmt.Errorf("expected NameToken, got token kind '%v', with value '%s'", token.Kind, token.Value)
+				return token, nil, expected(token, "Name")
 			}
 
 			// parse arguments definition
@@ -488,7 +734,7 @@ func parseInterface(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Tok
 			if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
 				token = lex.Read()
 			} else {
-				return token, nil, fmt.Errorf("expected ':', got token kind '%v', with value '%s'", token.Kind, token.Value)
+				return token, nil, expected(token, "\":\"")
 			}
 
 			// parse the type of the field
@@ -508,29 +754,31 @@ func parseInterface(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Tok
 					ds  []*ast.Directive
 					err error
 				)
-				token, ds, err = parseDirectives(lex)
+				token, ds, err = parseDirectives(token, lex)
 				if err != nil {
 					return token, nil, err
 				}
 				field.Directives = ds
 			}
 
+			finish(&field.Location, lex)
 			def.Fields = append(def.Fields, field)
 		}
 	}
 
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseUnion(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseUnion(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.UnionDefinition{
 		Description: desc,
+		Location:    loc,
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -540,9 +788,9 @@ func parseUnion(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
-			return token, nil, fmt.Errorf("couldn't parse directives: %v", err)
+			return token, nil, err
 		}
 		def.Directives = ds
 	}
@@ -554,49 +802,41 @@ func parseUnion(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token,
 			token = lex.Read()
 		}
 		if token.Kind == lexer.NameToken {
-			def.Members = append(def.Members, &ast.NamedType{
-				Location: ast.Location{
-					Column: token.Col,
-					Line:   token.Line,
-				},
-				Name: token.Value,
-			})
-			token = lex.Read()
+			var nt *ast.NamedType
+			token, nt = parseNamedType(token, lex)
+			def.Members = append(def.Members, nt)
 		} else {
-			return token, nil, fmt.Errorf("expected Name token, got '%s'", token.Value)
+			return token, nil, expected(token, "Name")
 		}
 		for {
 			if token.Kind == lexer.PunctuatorToken && token.Value == "|" {
 				token = lex.Read()
 			} else {
+				finish(&def.Location, lex)
 				return token, def, nil
 			}
 			if token.Kind == lexer.NameToken {
-				def.Members = append(def.Members, &ast.NamedType{
-					Location: ast.Location{
-						Column: token.Col,
-						Line:   token.Line,
-					},
-					Name: token.Value,
-				})
-				token = lex.Read()
+				var nt *ast.NamedType
+				token, nt = parseNamedType(token, lex)
+				def.Members = append(def.Members, nt)
 			} else {
-				return token, nil, fmt.Errorf("expected Name token, got '%s'", token.Value)
+				return token, nil, expected(token, "Name")
 			}
 		}
 	}
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseEnum(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseEnum(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.EnumDefinition{
 		Description: desc,
+		Location:    loc,
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -607,9 +847,9 @@ func parseEnum(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, a
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
-			return token, nil, fmt.Errorf("couldn't parse directives: %v", err)
+			return token, nil, err
 		}
 		def.Directives = ds
 	}
@@ -621,60 +861,87 @@ func parseEnum(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, a
 		// parse all the enum value definitions
 		for {
 			if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
-				return lex.Read(), def, nil
+				token = lex.Read()
+				finish(&def.Location, lex)
+				return token, def, nil
 			}
 
-			enumV := &ast.EnumValueDefinition{}
+			enumV := &ast.EnumValueDefinition{
+				Location: start(token),
+				Comments: lex.leadingComments(token),
+			}
 			if token.Kind == lexer.StringValueToken {
-				enumV.Description = strings.Trim(token.Value, `"`)
+				enumV.Description = token.Value
 				token = lex.Read()
 			}
 			if token.Kind == lexer.NameToken {
 				enumV.Value = &ast.EnumValue{
-					Location: ast.Location{
-						Column: token.Col,
-						Line:   token.Line,
-					},
-					Value: token.Value,
+					Location: start(token),
+					Value:    token.Value,
 				}
 				token = lex.Read()
+				finish(&enumV.Value.Location, lex)
 			} else {
-				return token, nil, fmt.Errorf("expected Name token, got '%v'", token.Value)
+				return token, nil, expected(token, "Name")
 			}
 			if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
 				var (
 					ds  []*ast.Directive
 					err error
 				)
-				token, ds, err = parseDirectives(lex)
+				token, ds, err = parseDirectives(token, lex)
 				if err != nil {
-					return token, nil, fmt.Errorf("couldn't parse directives on enum value: %v", err)
+					return token, nil, err
 				}
 				enumV.Directives = ds
 			}
+			finish(&enumV.Location, lex)
 			def.Values = append(def.Values, enumV)
 		}
 	}
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseDirectiveDefinition(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseDirectiveDefinition(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.DirectiveDefinition{
 		Description: desc,
+		Location:    loc,
 	}
 
 	// parse Name
 	if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
 		token = lex.Read()
 	} else {
-		return token, nil, fmt.Errorf("expected token '@', got: '%s'", token.Value)
+		return token, nil, expected(token, "\"@\"")
 	}
 	if token.Kind != lexer.NameToken {
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
 
+	// parse arguments definition
+	if token.Kind == lexer.PunctuatorToken && token.Value == "(" {
+		token = lex.Read()
+		def.Arguments = []*ast.InputValueDefinition{}
+		for {
+			if token.Kind == lexer.PunctuatorToken && token.Value == ")" {
+				token = lex.Read()
+				break
+			}
+			var (
+				inputDef *ast.InputValueDefinition
+				err      error
+			)
+			token, inputDef, err = parseInputValueDefinition(token, lex)
+			if err != nil {
+				return token, nil, err
+			}
+			def.Arguments = append(def.Arguments, inputDef)
+		}
+	}
+
 	if token.Kind == lexer.NameToken && token.Value == "on" {
 		def.Locations = []string{}
 		token = lex.Read()
@@ -682,38 +949,39 @@ func parseDirectiveDefinition(token lexer.Token, lex *lexer.Lexer, desc string)
 		if token.Kind == lexer.PunctuatorToken && token.Value == "|" {
 			token = lex.Read()
 		}
-		if token.Kind == lexer.NameToken {
+		if token.Kind == lexer.NameToken && ast.IsValidDirective(token.Value) {
 			def.Locations = append(def.Locations, token.Value)
 			token = lex.Read()
 		} else {
-			return token, nil, fmt.Errorf("expected Name token, got '%s'", token.Value)
+			return token, nil, expected(token, "a valid directive location")
 		}
 		for {
 			if token.Kind == lexer.PunctuatorToken && token.Value == "|" {
 				token = lex.Read()
 			} else {
+				finish(&def.Location, lex)
 				return token, def, nil
 			}
 			if token.Kind == lexer.NameToken && ast.IsValidDirective(token.Value) {
 				def.Locations = append(def.Locations, token.Value)
 				token = lex.Read()
 			} else {
-				return token, nil, fmt.Errorf("expected Name token with a valid directive locaton, got '%s'", token.Value)
+				return token, nil, expected(token, "a valid directive location")
 			}
 		}
 	}
-	return token, def, nil
+	return token, nil, expected(token, "\"on\"")
 }
 
-func parseInputObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.Token, ast.Definition, error) {
+func parseInputObject(token tok, lex *reader, desc string, loc ast.Location) (tok, ast.Definition, error) {
 	def := &ast.InputObjectDefinition{
 		Description: desc,
+		Location:    loc,
 	}
 
 	// parse Name
 	if token.Kind != lexer.NameToken {
-		log.Println(token.Kind)
-		return token, nil, fmt.Errorf("expected NameToken, got: '%s', err: '%v'", token.Value, token.Err)
+		return token, nil, expected(token, "Name")
 	}
 	def.Name = token.Value
 	token = lex.Read()
@@ -724,9 +992,9 @@ func parseInputObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.T
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
-			return token, nil, fmt.Errorf("couldn't parse directives: %v", err)
+			return token, nil, err
 		}
 		def.Directives = ds
 	}
@@ -737,6 +1005,9 @@ func parseInputObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.T
 		def.Fields = []*ast.InputValueDefinition{}
 		for {
 			if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
+				if len(def.Fields) == 0 {
+					return token, nil, expected(token, "Name")
+				}
 				token = lex.Read()
 				break
 			}
@@ -751,11 +1022,15 @@ func parseInputObject(token lexer.Token, lex *lexer.Lexer, desc string) (lexer.T
 			def.Fields = append(def.Fields, inputDef)
 		}
 	}
+	finish(&def.Location, lex)
 	return token, def, nil
 }
 
-func parseInputValueDefinition(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.InputValueDefinition, error) {
-	val := &ast.InputValueDefinition{}
+func parseInputValueDefinition(token tok, lex *reader) (tok, *ast.InputValueDefinition, error) {
+	val := &ast.InputValueDefinition{
+		Location: start(token),
+		Comments: lex.leadingComments(token),
+	}
 
 	// parse description for input
 	if token.Kind == lexer.StringValueToken {
@@ -768,7 +1043,7 @@ func parseInputValueDefinition(token lexer.Token, lex *lexer.Lexer) (lexer.Token
 		val.Name = token.Value
 		token = lex.Read()
 	} else {
-		return token, nil, fmt.Errorf("expected NameToken, got token kind '%v', with value '%s'", token.Kind, token.Value)
+		return token, nil, expected(token, "Name")
 	}
 
 	// parse type for the input
@@ -785,7 +1060,7 @@ func parseInputValueDefinition(token lexer.Token, lex *lexer.Lexer) (lexer.Token
 		val.Type = t
 	} else {
 		// raise error since ':' and type are required in SDL
-		return token, nil, fmt.Errorf("expected ':', got token kind '%v', with value '%s'", token.Kind, token.Value)
+		return token, nil, expected(token, "\":\"")
 	}
 
 	// parse default value ()
@@ -795,7 +1070,7 @@ func parseInputValueDefinition(token lexer.Token, lex *lexer.Lexer) (lexer.Token
 			v   ast.Value
 			err error
 		)
-		token, v, err = parseValue(token, lex)
+		token, v, err = parseValue(token, lex, true)
 		if err != nil {
 			return token, nil, err
 		}
@@ -808,25 +1083,29 @@ func parseInputValueDefinition(token lexer.Token, lex *lexer.Lexer) (lexer.Token
 			ds  []*ast.Directive
 			err error
 		)
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
 			return token, nil, err
 		}
 		val.Directives = ds
 	}
 
+	finish(&val.Location, lex)
 	return token, val, nil
 }
 
-func parseFragment(lex *lexer.Lexer) (lexer.Token, *ast.Fragment, error) {
-	f := new(ast.Fragment)
+// parseFragment parses a fragment definition, the token is 'fragment'
+func parseFragment(token tok, lex *reader) (tok, *ast.Fragment, error) {
+	f := &ast.Fragment{
+		Location: start(token),
+	}
 	var err error
 
-	token := lex.Read()
+	token = lex.Read()
 	if token.Kind == lexer.NameToken && token.Value != "on" {
 		f.Name = token.Value
 	} else {
-		return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		return token, nil, unexpected(token)
 	}
 
 	token = lex.Read()
@@ -835,16 +1114,16 @@ func parseFragment(lex *lexer.Lexer) (lexer.Token, *ast.Fragment, error) {
 		if token.Kind == lexer.NameToken {
 			f.TypeCondition = token.Value
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 	} else {
-		return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		return token, nil, unexpected(token)
 	}
 
 	token = lex.Read()
 	if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
 		ds := []*ast.Directive{}
-		token, ds, err = parseDirectives(lex)
+		token, ds, err = parseDirectives(token, lex)
 		if err != nil {
 			return token, nil, err
 		}
@@ -859,16 +1138,17 @@ func parseFragment(lex *lexer.Lexer) (lexer.Token, *ast.Fragment, error) {
 		}
 		f.SelectionSet = sSet
 	} else {
-		return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		return token, nil, unexpected(token)
 	}
 
+	finish(&f.Location, lex)
 	return token, f, nil
 }
 
-func parseOperation(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.Operation, error) {
+func parseOperation(token tok, lex *reader) (tok, *ast.Operation, error) {
 	var err error
 	if token.Kind != lexer.NameToken {
-		return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		return token, nil, unexpected(token)
 	}
 	var ot ast.OperationType
 	if token.Value == "query" {
@@ -878,10 +1158,11 @@ func parseOperation(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.Oper
 	} else if token.Value == "subscription" {
 		ot = ast.Subscription
 	} else {
-		return token, nil, fmt.Errorf("invalid token value: %s, wanted one of 'query', 'mutation' or 'subscription'", token.Value)
+		return token, nil, expected(token, "one of \"query\", \"mutation\" or \"subscription\"")
 	}
 
 	op := ast.NewOperation(ot)
+	op.Location = start(token)
 
 	token = lex.Read()
 	for {
@@ -901,7 +1182,7 @@ func parseOperation(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.Oper
 		case token.Kind == lexer.PunctuatorToken && token.Value == "@":
 			ds := []*ast.Directive{}
 			var err error
-			token, ds, err = parseDirectives(lex)
+			token, ds, err = parseDirectives(token, lex)
 			if err != nil {
 				return token, nil, err
 			}
@@ -914,14 +1195,15 @@ func parseOperation(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.Oper
 				return token, nil, err
 			}
 			op.SelectionSet = sSet
+			finish(&op.Location, lex)
 			return token, op, nil
 		default:
-			return token, nil, fmt.Errorf("invalid token value: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 	}
 }
 
-func parseVariables(lex *lexer.Lexer) (lexer.Token, []*ast.Variable, error) {
+func parseVariables(lex *reader) (tok, []*ast.Variable, error) {
 	token := lex.Read()
 	vs := []*ast.Variable{}
 	var err error
@@ -931,25 +1213,25 @@ func parseVariables(lex *lexer.Lexer) (lexer.Token, []*ast.Variable, error) {
 			return lex.Read(), vs, nil
 		}
 
-		v := new(ast.Variable)
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
+		v := &ast.Variable{
+			Location: start(token),
+		}
 		if token.Kind == lexer.PunctuatorToken && token.Value == "$" {
 			token = lex.Read()
 			if token.Kind == lexer.NameToken {
 				v.Name = token.Value
 			} else {
-				return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+				return token, nil, unexpected(token)
 			}
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 
 		token = lex.Read()
 		if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
 			token = lex.Read()
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 
 		var t ast.Type
@@ -961,51 +1243,41 @@ func parseVariables(lex *lexer.Lexer) (lexer.Token, []*ast.Variable, error) {
 
 		if token.Kind == lexer.PunctuatorToken && token.Value == "=" {
 			var dv ast.Value
-			token, dv, err = parseValue(lex.Read(), lex)
+			token, dv, err = parseValue(lex.Read(), lex, true)
 			if err != nil {
 				return token, nil, err
 			}
 			v.DefaultValue = dv
 		}
 
-		if token.Kind == lexer.PunctuatorToken && token.Value == "$" {
-			vs = append(vs, v)
-			continue
-		} else if token.Kind == lexer.PunctuatorToken && token.Value == ")" {
-			vs = append(vs, v)
-			return lex.Read(), vs, nil
-		}
+		finish(&v.Location, lex)
+		vs = append(vs, v)
+	}
+}
+
+// parseNamedType parses a named type, the token must be a Name
+func parseNamedType(token tok, lex *reader) (tok, *ast.NamedType) {
+	nt := &ast.NamedType{
+		Name:     token.Value,
+		Location: start(token),
 	}
+	token = lex.Read()
+	finish(&nt.Location, lex)
+	return token, nt
 }
 
-func parseType(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Type, error) {
+func parseType(token tok, lex *reader) (tok, ast.Type, error) {
+	var t ast.Type
+	loc := start(token)
 	switch {
 	case token.Kind == lexer.NameToken:
-		nt := new(ast.NamedType)
-		nt.Name = token.Value
-		nt.Location.Column = token.Col
-		nt.Location.Line = token.Line - 1
-
-		token = lex.Read()
-		if token.Kind == lexer.PunctuatorToken && token.Value == "!" {
-			nnt := new(ast.NonNullType)
-			nnt.Type = nt
-			nnt.Location.Column = token.Col
-			nnt.Location.Line = token.Line - 1
-			return lex.Read(), nnt, nil
-		}
-		return token, nt, nil
+		token, t = parseNamedType(token, lex)
 	case token.Kind == lexer.PunctuatorToken && token.Value == "[":
-		token = lex.Read()
-		var (
-			t   ast.Type
-			err error
-		)
-		loc := ast.Location{
-			Column: token.Col,
-			Line:   token.Line,
+		lt := &ast.ListType{
+			Location: loc,
 		}
-		token, t, err = parseType(token, lex)
+		var err error
+		token, lt.Type, err = parseType(lex.Read(), lex)
 		if err != nil {
 			return token, nil, err
 		}
@@ -1013,225 +1285,220 @@ func parseType(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Type, erro
 		if token.Kind == lexer.PunctuatorToken && token.Value == "]" {
 			token = lex.Read()
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
+		finish(&lt.Location, lex)
+		t = lt
+	default:
+		return token, nil, unexpected(token)
+	}
 
-		lt := &ast.ListType{
+	if token.Kind == lexer.PunctuatorToken && token.Value == "!" {
+		nnt := &ast.NonNullType{
 			Type:     t,
 			Location: loc,
 		}
-
-		if token.Kind == lexer.PunctuatorToken && token.Value == "!" {
-			return lex.Read(), &ast.NonNullType{
-				Type: lt,
-			}, nil
-		}
-		return token, lt, nil
-	default:
-		return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		token = lex.Read()
+		finish(&nnt.Location, lex)
+		return token, nnt, nil
 	}
+	return token, t, nil
 }
 
-func parseSelectionSet(lex *lexer.Lexer) (token lexer.Token, set []ast.Selection, err error) {
+func parseSelectionSet(lex *reader) (token tok, set []ast.Selection, err error) {
+	// the selections are collected on the stack of the reader, and copied
+	// to a slice of the exact size at the end
+	base := len(lex.selections)
 	end := false
 	token = lex.Read()
 	for {
 		switch {
 		case token.Kind == lexer.PunctuatorToken && token.Value == "...":
 			var sel ast.Selection
-			token, sel, err = parseFragments(lex)
+			token, sel, err = parseFragments(token, lex)
 			if err != nil {
 				return token, nil, err
 			}
-			set = append(set, sel)
+			lex.selections = append(lex.selections, sel)
 			break
 		case token.Kind == lexer.NameToken:
-			f := new(ast.Field)
+			var f *ast.Field
 			token, f, err = parseField(token, lex)
 			if err != nil {
 				return token, nil, err
 			}
-			set = append(set, f)
+			lex.selections = append(lex.selections, f)
 			break
 		case token.Kind == lexer.PunctuatorToken && token.Value == "}":
 			end = true
 			break
 		default:
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 		if end {
 			break
 		}
 	}
+	if n := len(lex.selections) - base; n != 0 {
+		set = make([]ast.Selection, n)
+		copy(set, lex.selections[base:])
+		lex.selections = lex.selections[:base]
+	}
 	return lex.Read(), set, nil
 }
 
-func parseField(token lexer.Token, lex *lexer.Lexer) (lexer.Token, *ast.Field, error) {
+func parseField(token tok, lex *reader) (tok, *ast.Field, error) {
 	var err error
-	f := new(ast.Field)
-	f.Alias = token.Value
-	f.Location.Column = token.Col
-	f.Location.Line = token.Line - 1
+	f := &ast.Field{
+		Alias:    token.Value,
+		Location: start(token),
+		Comments: lex.leadingComments(token),
+	}
 	defer func() {
 		if f.Name == "" {
 			f.Name = f.Alias
 		}
+		finish(&f.Location, lex)
 	}()
 
-	end := false
+	// the parts of a field are in a fixed order: alias, arguments, directives, selection set
 	token = lex.Read()
-	for {
-		switch {
-		case token.Kind == lexer.PunctuatorToken && token.Value == ":" && f.Name == "":
-			token = lex.Read()
-			if token.Kind == lexer.NameToken {
-				f.Name = token.Value
-			} else {
-				return token, nil, fmt.Errorf("unexpected token, expected name token, got: %s", token.Value)
-			}
-			token = lex.Read()
-			break
-		case token.Kind == lexer.PunctuatorToken && token.Value == "(":
-			args, err := parseArguments(lex)
-			if err != nil {
-				return lex.Read(), nil, err
-			}
-			f.Arguments = args
-			token = lex.Read()
-			break
-		case token.Kind == lexer.PunctuatorToken && token.Value == "@":
-			ds := []*ast.Directive{}
-			var err error
-			token, ds, err = parseDirectives(lex)
-			if err != nil {
-				return token, nil, err
-			}
-			f.Directives = ds
-		case token.Kind == lexer.PunctuatorToken && token.Value == "{":
-			sSet := []ast.Selection{}
-			token, sSet, err = parseSelectionSet(lex)
-			if err != nil {
-				return token, nil, err
-			}
-			f.SelectionSet = sSet
-			return token, f, nil
-		case token.Kind == lexer.NameToken:
-			return token, f, nil
-		case token.Kind == lexer.PunctuatorToken && token.Value == "}":
-			return token, f, nil
-		default:
-			if f.Alias != "" {
-				return token, f, nil
-			}
-			return token, nil, fmt.Errorf("invalid token: '%s'", token.Value)
+	if token.Kind == lexer.PunctuatorToken && token.Value == ":" {
+		token = lex.Read()
+		if token.Kind != lexer.NameToken {
+			return token, nil, expected(token, "Name")
 		}
-
-		if end {
-			break
+		f.Name = token.Value
+		token = lex.Read()
+	}
+	if token.Kind == lexer.PunctuatorToken && token.Value == "(" {
+		token, f.Arguments, err = parseArguments(lex)
+		if err != nil {
+			return token, nil, err
+		}
+	}
+	if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
+		token, f.Directives, err = parseDirectives(token, lex)
+		if err != nil {
+			return token, nil, err
+		}
+	}
+	if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
+		token, f.SelectionSet, err = parseSelectionSet(lex)
+		if err != nil {
+			return token, nil, err
 		}
 	}
 	return token, f, nil
 }
 
-func parseArguments(lex *lexer.Lexer) (args []*ast.Argument, err error) {
-	token := lex.Read()
+func parseArguments(lex *reader) (token tok, args []*ast.Argument, err error) {
+	token = lex.Read()
 	for {
-		arg := new(ast.Argument)
+		arg := &ast.Argument{
+			Location: start(token),
+		}
 		if token.Kind == lexer.NameToken {
 			arg.Name = token.Value
 		} else {
-			return nil, fmt.Errorf("unexpected token args: %+v", token.Value)
+			return token, nil, expected(token, "Name")
 		}
 
 		token = lex.Read()
-		if token.Kind != lexer.PunctuatorToken && token.Value != ":" {
-			return nil, fmt.Errorf("unexpected token '%s' '%s', expected ':'", token.Value, token.Kind.String())
+		if token.Kind != lexer.PunctuatorToken || token.Value != ":" {
+			return token, nil, expected(token, "\":\"")
 		}
 
 		token = lex.Read()
 		var val ast.Value
-		token, val, err = parseValue(token, lex)
+		token, val, err = parseValue(token, lex, false)
 		if err != nil {
-			return nil, err
+			return token, nil, err
 		}
 		arg.Value = val
+		finish(&arg.Location, lex)
 		args = append(args, arg)
 
 		if token.Kind == lexer.PunctuatorToken && token.Value == ")" {
-			return args, nil
+			return lex.Read(), args, nil
 		}
 	}
 }
 
-func parseValue(token lexer.Token, lex *lexer.Lexer) (lexer.Token, ast.Value, error) {
+// parseValue parses a value, which can not contain variables if it must be constant,
+// like the default values
+func parseValue(token tok, lex *reader, constant bool) (tok, ast.Value, error) {
+	var v ast.Value
+	loc := start(token)
 	switch {
 	case token.Kind == lexer.PunctuatorToken && token.Value == "$":
-		v := new(ast.VariableValue)
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
+		dollar := token
 		token = lex.Read()
+		if constant && token.Kind == lexer.NameToken {
+			return token, nil, newError(dollar, "Syntax Error: Unexpected variable \"$%s\" in constant value.", token.Value)
+		}
 		if token.Kind != lexer.NameToken {
-			return token, nil, fmt.Errorf("invalid token")
+			return token, nil, expected(token, "Name")
 		}
-		v.Name = token.Value
-		return lex.Read(), v, nil
+		v = &ast.VariableValue{Name: token.Value}
 	case token.Kind == lexer.IntValueToken:
-		v := new(ast.IntValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.IntValue{Value: token.Value}
 	case token.Kind == lexer.FloatValueToken:
-		v := new(ast.FloatValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.FloatValue{Value: token.Value}
 	case token.Kind == lexer.StringValueToken:
-		v := new(ast.StringValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.StringValue{Value: token.Value, Block: token.Block}
 	case token.Kind == lexer.NameToken && (token.Value == "false" || token.Value == "true"):
-		v := new(ast.BooleanValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.BooleanValue{Value: token.Value}
 	case token.Kind == lexer.NameToken && token.Value == "null":
-		v := new(ast.NullValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.NullValue{Value: token.Value}
 	case token.Kind == lexer.NameToken:
-		v := new(ast.EnumValue)
-		v.Value = token.Value
-		v.Location.Column = token.Col
-		v.Location.Line = token.Line - 1
-		return lex.Read(), v, nil
+		v = &ast.EnumValue{Value: token.Value}
 	case token.Kind == lexer.PunctuatorToken && token.Value == "[":
-		return parseListValue(lex)
+		return parseListValue(token, lex, constant)
 	case token.Kind == lexer.PunctuatorToken && token.Value == "{":
-		return parseObjectValue(lex)
+		return parseObjectValue(token, lex, constant)
+	default:
+		return token, nil, unexpected(token)
+	}
+
+	token = lex.Read()
+	finish(&loc, lex)
+	switch v := v.(type) {
+	case *ast.VariableValue:
+		v.Location = loc
+	case *ast.IntValue:
+		v.Location = loc
+	case *ast.FloatValue:
+		v.Location = loc
+	case *ast.StringValue:
+		v.Location = loc
+	case *ast.BooleanValue:
+		v.Location = loc
+	case *ast.NullValue:
+		v.Location = loc
+	case *ast.EnumValue:
+		v.Location = loc
 	}
-	return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+	return token, v, nil
 }
 
-func parseListValue(lex *lexer.Lexer) (lexer.Token, *ast.ListValue, error) {
-	list := new(ast.ListValue)
-	token := lex.Read()
+func parseListValue(token tok, lex *reader, constant bool) (tok, *ast.ListValue, error) {
+	list := &ast.ListValue{
+		Location: start(token),
+	}
+	token = lex.Read()
 	for {
 		if token.Kind == lexer.PunctuatorToken && token.Value == "]" {
-			return lex.Read(), list, nil
+			token = lex.Read()
+			finish(&list.Location, lex)
+			return token, list, nil
 		}
 
 		var (
 			err error
 			v   ast.Value
 		)
-		token, v, err = parseValue(token, lex)
+		token, v, err = parseValue(token, lex, constant)
 		if err != nil {
 			return token, nil, err
 		}
@@ -1239,46 +1506,57 @@ func parseListValue(lex *lexer.Lexer) (lexer.Token, *ast.ListValue, error) {
 	}
 }
 
-func parseObjectValue(lex *lexer.Lexer) (lexer.Token, *ast.ObjectValue, error) {
-	token := lex.Read()
+func parseObjectValue(token tok, lex *reader, constant bool) (tok, *ast.ObjectValue, error) {
 	var err error
-	o := new(ast.ObjectValue)
-	o.Fields = []*ast.ObjectFieldValue{}
+	o := &ast.ObjectValue{
+		Fields:   []*ast.ObjectFieldValue{},
+		Location: start(token),
+	}
+	token = lex.Read()
 	for {
-		field := new(ast.ObjectFieldValue)
-		field.Location.Column = token.Col
-		field.Location.Line = token.Line - 1
+		if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
+			token = lex.Read()
+			finish(&o.Location, lex)
+			return token, o, nil
+		}
+
+		field := &ast.ObjectFieldValue{
+			Location: start(token),
+		}
 
 		if token.Kind == lexer.NameToken {
 			field.Name = token.Value
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, expected(token, "Name")
 		}
 
 		token = lex.Read()
-		if token.Kind != lexer.PunctuatorToken && token.Value != ":" {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+		if token.Kind != lexer.PunctuatorToken || token.Value != ":" {
+			return token, nil, expected(token, "\":\"")
 		}
 
 		token = lex.Read()
 		var val ast.Value
-		token, val, err = parseValue(token, lex)
+		token, val, err = parseValue(token, lex, constant)
 		if err != nil {
 			return token, nil, err
 		}
 		field.Value = val
+		finish(&field.Location, lex)
 		o.Fields = append(o.Fields, field)
-
-		if token.Kind == lexer.PunctuatorToken && token.Value == "}" {
-			return lex.Read(), o, nil
-		}
 	}
 }
 
-func parseFragments(lex *lexer.Lexer) (token lexer.Token, sel ast.Selection, err error) {
+// parseFragments parses a fragment spread or an inline fragment, the token is '...'
+func parseFragments(token tok, lex *reader) (tok, ast.Selection, error) {
+	var err error
+	loc, comments := start(token), lex.leadingComments(token)
 	token = lex.Read()
 	if token.Kind == lexer.NameToken && token.Value == "on" {
-		inf := new(ast.InlineFragment)
+		inf := &ast.InlineFragment{
+			Location: loc,
+			Comments: comments,
+		}
 
 		token = lex.Read()
 		if token.Kind == lexer.NameToken {
@@ -1288,7 +1566,7 @@ func parseFragments(lex *lexer.Lexer) (token lexer.Token, sel ast.Selection, err
 
 		if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
 			ds := []*ast.Directive{}
-			token, ds, err = parseDirectives(lex)
+			token, ds, err = parseDirectives(token, lex)
 			if err != nil {
 				return token, nil, err
 			}
@@ -1303,75 +1581,89 @@ func parseFragments(lex *lexer.Lexer) (token lexer.Token, sel ast.Selection, err
 			}
 			inf.SelectionSet = sSet
 		} else {
-			return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+			return token, nil, unexpected(token)
 		}
 
+		finish(&inf.Location, lex)
 		return token, inf, nil
-	} else if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
-		sSet := []ast.Selection{}
-		loc := ast.Location{
-			Line:   token.Line,
-			Column: token.Col,
+	} else if token.Kind == lexer.PunctuatorToken && (token.Value == "{" || token.Value == "@") {
+		inf := &ast.InlineFragment{
+			Location: loc,
+			Comments: comments,
 		}
-		token, sSet, err = parseSelectionSet(lex)
-		if err != nil {
-			return token, nil, err
+
+		if token.Value == "@" {
+			ds := []*ast.Directive{}
+			token, ds, err = parseDirectives(token, lex)
+			if err != nil {
+				return token, nil, err
+			}
+			inf.Directives = ds
 		}
-		inf := &ast.InlineFragment{
-			SelectionSet: sSet,
-			Location:     loc,
+
+		if token.Kind == lexer.PunctuatorToken && token.Value == "{" {
+			sSet := []ast.Selection{}
+			token, sSet, err = parseSelectionSet(lex)
+			if err != nil {
+				return token, nil, err
+			}
+			inf.SelectionSet = sSet
+		} else {
+			return token, nil, expected(token, "\"{\"")
 		}
 
+		finish(&inf.Location, lex)
 		return token, inf, nil
 	} else if token.Kind == lexer.NameToken && token.Value != "on" {
-		fs := new(ast.FragmentSpread)
-		fs.Name = token.Value
-		fs.Location.Column = token.Col
-		fs.Location.Line = token.Line - 1
+		fs := &ast.FragmentSpread{
+			Name:     token.Value,
+			Location: loc,
+			Comments: comments,
+		}
 		token = lex.Read()
 
 		if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
 			ds := []*ast.Directive{}
-			token, ds, err = parseDirectives(lex)
+			token, ds, err = parseDirectives(token, lex)
 			if err != nil {
 				return token, nil, err
 			}
 			fs.Directives = ds
 		}
 
+		finish(&fs.Location, lex)
 		return token, fs, nil
 	}
-	return token, nil, fmt.Errorf("unexpected token: %s", token.Value)
+	return token, nil, unexpected(token)
 }
 
-func parseDirectives(lex *lexer.Lexer) (lexer.Token, []*ast.Directive, error) {
-	token := lex.Read()
+// parseDirectives parses a list of directives, the token is the first '@'
+func parseDirectives(token tok, lex *reader) (tok, []*ast.Directive, error) {
 	ds := []*ast.Directive{}
-	for {
-		if token.Kind == lexer.NameToken {
-			d := new(ast.Directive)
-			d.Name = token.Value
-			d.Location.Column = token.Col
-			d.Location.Line = token.Line - 1
-			token = lex.Read()
-
-			if token.Kind == lexer.PunctuatorToken && token.Value == "(" {
-				args, err := parseArguments(lex)
-				if err != nil {
-					return token, nil, err
-				}
-				d.Arguments = args
-				token = lex.Read()
-			}
-			ds = append(ds, d)
+	for token.Kind == lexer.PunctuatorToken && token.Value == "@" {
+		d := &ast.Directive{
+			Location: start(token),
+		}
+		token = lex.Read()
+		if token.Kind != lexer.NameToken {
+			return token, nil, expected(token, "Name")
+		}
+		d.Name = token.Value
+		token = lex.Read()
 
-			if token.Kind == lexer.PunctuatorToken && token.Value == "@" {
-				token = lex.Read()
-			} else {
-				return token, ds, nil
+		if token.Kind == lexer.PunctuatorToken && token.Value == "(" {
+			var (
+				args []*ast.Argument
+				err  error
+			)
+			token, args, err = parseArguments(lex)
+			if err != nil {
+				return token, nil, err
 			}
-		} else {
-			return token, ds, nil
+			d.Arguments = args
 		}
+		finish(&d.Location, lex)
+		ds = append(ds, d)
 	}
+	return token, ds, nil
 }
@@ -1,12 +1,17 @@
 package parser_test
 
 import (
+	"io/ioutil"
 	"log"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/rigglo/gql/pkg/language/ast"
 
 	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/language/printer"
+	"github.com/rigglo/gql/pkg/language/visitor"
 )
 
 func TestParse(t *testing.T) {
@@ -169,19 +174,781 @@ func TestParseDirective(t *testing.T) {
 	// spew.Dump(doc)
 }
 
+func TestParseDirectiveLocations(t *testing.T) {
+	def, err := parser.ParseDefinition([]byte(`directive @foo on QUERY | FIELD | INPUT_FIELD_DEFINITION`))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	locs := def.(*ast.DirectiveDefinition).Locations
+	if len(locs) != 3 || locs[0] != "QUERY" || locs[2] != "INPUT_FIELD_DEFINITION" {
+		t.Errorf("unexpected locations: %v", locs)
+	}
+
+	for _, query := range []string{
+		`directive @foo on QUERY | FOO`,
+		`directive @foo on QUERY | ERY`,
+	} {
+		if _, err := parser.ParseDefinition([]byte(query)); err == nil {
+			t.Errorf("expected an error for '%s'", query)
+		}
+	}
+}
+
 func TestParseSchema(t *testing.T) {
-	query := `
-	schema MySchema @somedirective {
+	for _, query := range []string{
+		`schema { query: MyRootQuery }`,
+		`
+	schema @somedirective {
 		query: MyRootQuery
 		mutation: MyRootMutation
 	}
-	`
-	def, err := parser.ParseDefinition([]byte(query))
+	`,
+	} {
+		def, err := parser.ParseDefinition([]byte(query))
+		if err != nil {
+			t.Errorf("error: %v", err)
+			return
+		}
+		schema, ok := def.(*ast.SchemaDefinition)
+		if !ok {
+			t.Fatalf("expected a schema definition, got %#v", def)
+		}
+		if q := schema.RootOperations[ast.Query]; q == nil || q.Name != "MyRootQuery" {
+			t.Errorf("expected the query type MyRootQuery, got %#v", q)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		message string
+		line    int
+		column  int
+	}{
+		{
+			name:    "MissingClosingBrace",
+			query:   "{\n  a\n  b",
+			message: "Syntax Error: Unexpected <EOF>.",
+			line:    3,
+			column:  4,
+		},
+		{
+			name:    "InvalidCharacter",
+			query:   "{ a ^ }",
+			message: `Syntax Error: Unexpected character '^'.`,
+			line:    1,
+			column:  5,
+		},
+		{
+			name:    "UnterminatedString",
+			query:   "{ a(b: \"foo) }",
+			message: "Syntax Error: Unterminated string.",
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "MissingArgumentColon",
+			query:   "query {\n  a(b 1)\n}",
+			message: `Syntax Error: Expected ":", found Int "1".`,
+			line:    2,
+			column:  7,
+		},
+		{
+			name:    "MissingFieldType",
+			query:   "type Person {\n\tname:\n}",
+			message: `Syntax Error: Unexpected "}".`,
+			line:    3,
+			column:  1,
+		},
+		{
+			name:    "MissingTypeName",
+			query:   "scalar 1",
+			message: `Syntax Error: Expected Name, found Int "1".`,
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "InvalidNumber",
+			query:   "{ a(b: 012) }",
+			message: `Syntax Error: Invalid number, unexpected digit after 0: '1'.`,
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "MissingDirectiveName",
+			query:   "{ a @ }",
+			message: `Syntax Error: Expected Name, found "}".`,
+			line:    1,
+			column:  7,
+		},
+		{
+			name:    "RepeatedArguments",
+			query:   "{ a(x: 1)(y: 2) }",
+			message: `Syntax Error: Unexpected "(".`,
+			line:    1,
+			column:  10,
+		},
+		{
+			name:    "ArgumentsAfterDirectives",
+			query:   "{ a @d(y: 2)(x: 1) }",
+			message: `Syntax Error: Unexpected "(".`,
+			line:    1,
+			column:  13,
+		},
+		{
+			name:    "DirectivesAfterSelectionSet",
+			query:   "{ a { b } @d }",
+			message: `Syntax Error: Unexpected "@".`,
+			line:    1,
+			column:  11,
+		},
+		{
+			name:    "EmptyObjectFields",
+			query:   "type A {}",
+			message: `Syntax Error: Expected Name, found "}".`,
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "EmptyInterfaceFields",
+			query:   "interface A {}",
+			message: `Syntax Error: Expected Name, found "}".`,
+			line:    1,
+			column:  14,
+		},
+		{
+			name:    "EmptyInputObjectFields",
+			query:   "input A {}",
+			message: `Syntax Error: Expected Name, found "}".`,
+			line:    1,
+			column:  10,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err == nil {
+				t.Fatalf("expected an error, got document: %#v", doc)
+			}
+			perr, ok := err.(*parser.ParserError)
+			if !ok {
+				t.Fatalf("expected a *parser.ParserError, got %T", err)
+			}
+			if perr.Message != tt.message {
+				t.Errorf("expected message '%s', got '%s'", tt.message, perr.Message)
+			}
+			if perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("expected position %d:%d, got %d:%d", tt.line, tt.column, perr.Line, perr.Column)
+			}
+			if perr.Excerpt == "" {
+				t.Errorf("expected an excerpt")
+			}
+		})
+	}
+}
+
+func TestParseErrorExcerpt(t *testing.T) {
+	_, err := parser.Parse([]byte("query {\n  a(b 1)\n}"))
+	expect := "1 | query {\n2 |   a(b 1)\n  |       ^"
+	if err == nil || err.(*parser.ParserError).Excerpt != expect {
+		t.Fatalf("expected excerpt\n%s\ngot\n%v", expect, err)
+	}
+}
+
+func TestParseWithRecovery(t *testing.T) {
+	query := `
+query A {
+	a(b 1)
+}
+
+query B {
+	b
+}
+
+type Person {
+	name: 
+}
+
+fragment F on Person {
+	name
+}
+
+{ c ^ }
+`
+	doc, errs := parser.ParseWithRecovery([]byte(query))
+	expected := [][2]int{{3, 6}, {12, 1}, {18, 5}}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d: %v", len(expected), len(errs), errs)
+	}
+	for i, pos := range expected {
+		if errs[i].Line != pos[0] || errs[i].Column != pos[1] {
+			t.Errorf("expected error %d at %d:%d, got %d:%d (%s)", i, pos[0], pos[1], errs[i].Line, errs[i].Column, errs[i].Message)
+		}
+	}
+	if len(doc.Operations) != 1 || doc.Operations[0].Name != "B" {
+		t.Errorf("expected operation B to be parsed, got %#v", doc.Operations)
+	}
+	if len(doc.Fragments) != 1 || doc.Fragments[0].Name != "F" {
+		t.Errorf("expected fragment F to be parsed, got %#v", doc.Fragments)
+	}
+}
+
+func TestParseExtensions(t *testing.T) {
+	query := `
+extend schema @foo {
+	mutation: Mutation
+}
+extend scalar Time @specifiedBy(url: "https://example.com")
+extend type Person implements Node @key(fields: "id") {
+	id: ID!
+}
+extend interface Node {
+	createdAt: Time
+}
+extend union Entity = Company | Animal
+extend enum Direction {
+	UP
+}
+extend input Point2D {
+	z: Float
+}
+`
+	doc, err := parser.Parse([]byte(query))
 	if err != nil {
-		t.Errorf("error: %v", err)
-		return
+		t.Fatalf("error: %v", err)
 	}
-	log.Printf("%#v", def.(*ast.SchemaDefinition))
-	//log.Printf("%#v", doc.Definitions[1])
-	// spew.Dump(doc)
+	kinds := []ast.DefinitionKind{
+		ast.SchemaExtensionKind,
+		ast.ScalarExtensionKind,
+		ast.ObjectExtensionKind,
+		ast.InterfaceExtensionKind,
+		ast.UnionExtensionKind,
+		ast.EnumExtensionKind,
+		ast.InputObjectExtensionKind,
+	}
+	if len(doc.Definitions) != len(kinds) {
+		t.Fatalf("expected %d definitions, got %d", len(kinds), len(doc.Definitions))
+	}
+	for i, k := range kinds {
+		if doc.Definitions[i].Kind() != k {
+			t.Errorf("expected definition %d to be of kind %v, got %v", i, k, doc.Definitions[i].Kind())
+		}
+	}
+	ext := doc.Definitions[2].(*ast.ObjectExtension)
+	if ext.Name != "Person" || len(ext.Implements) != 1 || len(ext.Directives) != 1 || len(ext.Fields) != 1 {
+		t.Errorf("invalid object extension: %#v", ext)
+	}
+
+	for _, invalid := range []string{"extend type Person", "extend schema", "extend directive @foo on FIELD", `"desc" extend scalar Foo @bar`} {
+		if _, err := parser.Parse([]byte(invalid)); err == nil {
+			t.Errorf("expected an error for '%s'", invalid)
+		}
+	}
+}
+
+func TestMergeExtensions(t *testing.T) {
+	base, err := parser.Parse([]byte(`
+schema {
+	query: Query
+}
+type Query {
+	person: Person
+}
+type Person {
+	name: String
+}
+enum Direction {
+	UP
+}
+`))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	exts, err := parser.Parse([]byte(`
+extend schema {
+	mutation: Mutation
+}
+extend type Person @key(fields: "id") {
+	id: ID!
+}
+extend enum Direction {
+	DOWN
+}
+type Mutation {
+	ok: Boolean
+}
+`))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	if err := base.Merge(exts); err != nil {
+		t.Fatalf("merge error: %v", err)
+	}
+	if err := base.MergeExtensions(); err != nil {
+		t.Fatalf("merge extensions error: %v", err)
+	}
+	if len(base.Definitions) != 5 {
+		t.Fatalf("expected 5 definitions after merging, got %d", len(base.Definitions))
+	}
+	schema := base.Definitions[0].(*ast.SchemaDefinition)
+	if schema.RootOperations[ast.Mutation] == nil || schema.RootOperations[ast.Mutation].Name != "Mutation" {
+		t.Errorf("expected mutation root operation type to be merged")
+	}
+	person := base.Definitions[2].(*ast.ObjectDefinition)
+	if len(person.Fields) != 2 || person.Fields[1].Name != "id" || len(person.Directives) != 1 {
+		t.Errorf("expected Person extension to be merged, got %#v", person)
+	}
+	direction := base.Definitions[3].(*ast.EnumDefinition)
+	if len(direction.Values) != 2 || direction.Values[1].Value.Value != "DOWN" {
+		t.Errorf("expected Direction extension to be merged, got %#v", direction)
+	}
+
+	conflicts, err := parser.Parse([]byte(`
+extend type Person {
+	name: String
+}
+extend type Animal @foo
+extend enum Person @foo
+extend schema {
+	query: OtherQuery
+}
+extend enum Direction {
+	DOWN
+}
+`))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+	if err := base.Merge(conflicts); err != nil {
+		t.Fatalf("merge error: %v", err)
+	}
+	err = base.MergeExtensions()
+	errs, ok := err.(ast.MergeErrors)
+	if !ok || len(errs) != 5 {
+		t.Fatalf("expected 5 conflicts, got: %v", err)
+	}
+	if len(base.Definitions) != 10 {
+		t.Errorf("expected the conflicting extensions to stay in the document, got %d definitions", len(base.Definitions))
+	}
+
+	if err := base.Merge(exts); err == nil {
+		t.Errorf("expected redefining types to be a conflict")
+	}
+}
+
+func TestLocations(t *testing.T) {
+	source := `query Q($id: [ID!]! = ["1"]) @live {
+  user(id: $id, filter: {name: "x"}) {
+    ...F @include(if: true)
+    ... on User {
+      name
+    }
+  }
+}
+
+fragment F on User {
+  id
+}
+
+"description"
+type User implements Node {
+  "the name"
+  name(upper: Boolean = false): String @deprecated
+}
+
+extend enum Role {
+  ADMIN
+}
+`
+	doc, err := parser.Parse([]byte(source))
+	if err != nil {
+		t.Fatalf("error: %v", err)
+	}
+
+	// every node must have a location that matches its line, column and offsets
+	lineStarts := []int{0}
+	for i, c := range source {
+		if c == '\n' {
+			lineStarts = append(lineStarts, i+1)
+		}
+	}
+	visitor.Walk(doc, &visitor.Visitor{
+		Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+			v := reflect.ValueOf(node).Elem().FieldByName("Location")
+			if !v.IsValid() {
+				return visitor.Continue
+			}
+			loc := v.Interface().(ast.Location)
+			if loc.Line < 1 || loc.Line > len(lineStarts) || lineStarts[loc.Line-1]+loc.Column-1 != loc.Start || loc.End <= loc.Start {
+				t.Errorf("invalid location of %T at %v: %+v", node, info.Path, loc)
+			}
+			return visitor.Continue
+		},
+	})
+
+	text := func(loc ast.Location) string {
+		return source[loc.Start:loc.End]
+	}
+	op := doc.Operations[0]
+	user := op.SelectionSet[0].(*ast.Field)
+	object := doc.Definitions[0].(*ast.ObjectDefinition)
+	tests := []struct {
+		name     string
+		location ast.Location
+		expected string
+	}{
+		{"Variable", op.Variables[0].Location, `$id: [ID!]! = ["1"]`},
+		{"VariableType", op.Variables[0].Type.(*ast.NonNullType).Location, `[ID!]!`},
+		{"ListType", op.Variables[0].Type.(*ast.NonNullType).Type.(*ast.ListType).Location, `[ID!]`},
+		{"DefaultValue", op.Variables[0].DefaultValue.GetLocation(), `["1"]`},
+		{"OperationDirective", op.Directives[0].Location, `@live`},
+		{"Argument", user.Arguments[0].Location, `id: $id`},
+		{"ObjectValue", user.Arguments[1].Value.GetLocation(), `{name: "x"}`},
+		{"ObjectField", user.Arguments[1].Value.(*ast.ObjectValue).Fields[0].Location, `name: "x"`},
+		{"FragmentSpread", user.SelectionSet[0].(*ast.FragmentSpread).Location, `...F @include(if: true)`},
+		{"InlineFragment", user.SelectionSet[1].(*ast.InlineFragment).Location, "... on User {\n      name\n    }"},
+		{"Fragment", doc.Fragments[0].Location, "fragment F on User {\n  id\n}"},
+		{"ObjectDefinition", object.Location, source[strings.Index(source, `"description"`):strings.Index(source, "\n\nextend")]},
+		{"Implements", object.Implements[0].Location, `Node`},
+		{"FieldDefinition", object.Fields[0].Location, "\"the name\"\n  name(upper: Boolean = false): String @deprecated"},
+		{"InputValueDefinition", object.Fields[0].Arguments[0].Location, `upper: Boolean = false`},
+		{"Extension", doc.Definitions[1].(*ast.EnumExtension).Location, "extend enum Role {\n  ADMIN\n}"},
+		{"EnumValueDefinition", doc.Definitions[1].(*ast.EnumExtension).Values[0].Location, `ADMIN`},
+	}
+	for _, tt := range tests {
+		if out := text(tt.location); out != tt.expected {
+			t.Errorf("%s: expected location of %q, got %q", tt.name, tt.expected, out)
+		}
+	}
+	if op.Location.Start != 0 || op.Location.End != strings.Index(source, "\n\nfragment") {
+		t.Errorf("invalid location of the operation: %+v", op.Location)
+	}
+}
+
+func TestParseWithOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		opts    parser.Options
+		message string
+		line    int
+		column  int
+	}{
+		{
+			name:  "NoLimits",
+			query: "{ a(x: [[[1]]]) { b { c } } }",
+		},
+		{
+			name:  "WithinLimits",
+			query: "{ a(x: [[1]]) { b } }",
+			opts:  parser.Options{MaxTokens: 15, MaxDepth: 3},
+		},
+		{
+			name:    "TooManyTokens",
+			query:   "{\n  a\n  b\n  c\n}",
+			opts:    parser.Options{MaxTokens: 3},
+			message: "Syntax Error: Document contains more than 3 tokens, parsing aborted.",
+			line:    4,
+			column:  3,
+		},
+		{
+			name:    "NestedSelectionSets",
+			query:   "{ a { b { c { d } } } }",
+			opts:    parser.Options{MaxDepth: 3},
+			message: "Syntax Error: Document exceeds the maximum depth of 3, parsing aborted.",
+			line:    1,
+			column:  13,
+		},
+		{
+			name:    "NestedLists",
+			query:   "{ a(x: [[[[1]]]]) }",
+			opts:    parser.Options{MaxDepth: 4},
+			message: "Syntax Error: Document exceeds the maximum depth of 4, parsing aborted.",
+			line:    1,
+			column:  11,
+		},
+		{
+			name:    "NestedListTypes",
+			query:   "query ($a: [[[Int]]]) { a }",
+			opts:    parser.Options{MaxDepth: 2},
+			message: "Syntax Error: Document exceeds the maximum depth of 2, parsing aborted.",
+			line:    1,
+			column:  14,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parser.ParseWithOptions([]byte(tt.query), tt.opts)
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			perr, ok := err.(*parser.ParserError)
+			if !ok {
+				t.Fatalf("expected a *ParserError, got %#v", err)
+			}
+			if perr.Message != tt.message || perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("expected '%s' at %d:%d, got '%s' at %d:%d", tt.message, tt.line, tt.column, perr.Message, perr.Line, perr.Column)
+			}
+		})
+	}
+
+	doc, err := parser.ParseWithOptions([]byte("{ a(x: 1) }"), parser.Options{NoLocation: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := doc.Operations[0].SelectionSet[0].(*ast.Field)
+	if f.Location != (ast.Location{}) || f.Arguments[0].Location != (ast.Location{}) || f.Arguments[0].Value.GetLocation() != (ast.Location{}) {
+		t.Errorf("expected no locations, got %+v", f)
+	}
+}
+
+func TestParseComments(t *testing.T) {
+	query := `# the schema
+type Query {
+	# the id
+	id: ID # after the id
+	"described"
+	# the name
+	name(
+		# the first argument
+		a: Int
+	): String
+}
+
+# color
+enum Color {
+	# red
+	RED
+}
+
+# the operation
+query {
+	# a field
+	a
+	# a spread
+	...F
+}
+# at the end`
+	doc, err := parser.ParseWithOptions([]byte(query), parser.Options{Comments: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	values := func(cs []*ast.Comment) []string {
+		out := []string{}
+		for _, c := range cs {
+			out = append(out, c.Value)
+		}
+		return out
+	}
+	obj := doc.Definitions[0].(*ast.ObjectDefinition)
+	enum := doc.Definitions[1].(*ast.EnumDefinition)
+	op := doc.Operations[0]
+	for _, tt := range []struct {
+		got      []*ast.Comment
+		expected []string
+	}{
+		{obj.Comments, []string{" the schema"}},
+		{obj.Fields[0].Comments, []string{" the id"}},
+		{obj.Fields[1].Comments, []string{" after the id"}},
+		{obj.Fields[1].Arguments[0].Comments, []string{" the first argument"}},
+		{enum.Comments, []string{" color"}},
+		{enum.Values[0].Comments, []string{" red"}},
+		{op.Comments, []string{" the operation"}},
+		{op.SelectionSet[0].(*ast.Field).Comments, []string{" a field"}},
+		{op.SelectionSet[1].(*ast.FragmentSpread).Comments, []string{" a spread"}},
+		{doc.Comments, []string{" the name", " at the end"}},
+	} {
+		if got := values(tt.got); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("expected comments %q, got %q", tt.expected, got)
+		}
+	}
+	if loc := obj.Comments[0].Location; loc != (ast.Location{Line: 1, Column: 1, Start: 0, End: 12}) {
+		t.Errorf("unexpected location of the comment: %+v", loc)
+	}
+	if loc := obj.Fields[0].Location; loc.Start != strings.Index(query, "id:") || loc.End != strings.Index(query, " # after") {
+		t.Errorf("the comments are part of the location of the field: %+v", loc)
+	}
+
+	doc, err = parser.Parse([]byte(query))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(doc.Comments) != 0 || len(doc.Operations[0].Comments) != 0 {
+		t.Errorf("expected no comments without the option")
+	}
+}
+
+// BenchmarkParse parses realistic documents, the queries of a repository page on GitHub and
+// a collection page of a Shopify storefront, and a part of the GitHub schema
+func BenchmarkParse(b *testing.B) {
+	for _, name := range []string{"github_query", "shopify_query", "github_schema"} {
+		document, err := ioutil.ReadFile("testdata/" + name + ".graphql")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if _, err := parser.Parse(document); err != nil {
+			b.Fatalf("%s: %v", name, err)
+		}
+		b.Run(name, func(b *testing.B) {
+			b.ReportAllocs()
+			b.SetBytes(int64(len(document)))
+			for i := 0; i < b.N; i++ {
+				parser.Parse(document)
+			}
+		})
+	}
+}
+
+func TestParseValueAndType(t *testing.T) {
+	tests := []struct {
+		name    string
+		parse   func([]byte) (string, error)
+		source  string
+		printed string
+		message string
+		line    int
+		column  int
+	}{
+		{name: "Object", parse: parseValue, source: "{a: [1, 2], b: ENUM}", printed: "{a: [1, 2], b: ENUM}"},
+		{name: "Variable", parse: parseValue, source: " $var ", printed: "$var"},
+		{name: "BlockString", parse: parseValue, source: `"""block"""`, printed: `"""` + "\nblock\n" + `"""`},
+		{name: "ConstObject", parse: parseConstValue, source: `{a: null, b: [1.5, "x"]}`, printed: `{a: null, b: [1.5, "x"]}`},
+		{name: "NonNullList", parse: parseType, source: "[String!]!", printed: "[String!]!"},
+		{name: "Named", parse: parseType, source: "Int", printed: "Int"},
+		{
+			name:    "ConstVariable",
+			parse:   parseConstValue,
+			source:  "[1, {a: $var}]",
+			message: `Syntax Error: Unexpected variable "$var" in constant value.`,
+			line:    1,
+			column:  9,
+		},
+		{
+			name:    "ValueNotConsumed",
+			parse:   parseValue,
+			source:  "1 2",
+			message: `Syntax Error: Expected <EOF>, found Int "2".`,
+			line:    1,
+			column:  3,
+		},
+		{
+			name:    "ValueWithExtraName",
+			parse:   parseValue,
+			source:  "String extra",
+			message: `Syntax Error: Expected <EOF>, found Name "extra".`,
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "ConstValueNotConsumed",
+			parse:   parseConstValue,
+			source:  "ENUM 1",
+			message: `Syntax Error: Expected <EOF>, found Int "1".`,
+			line:    1,
+			column:  6,
+		},
+		{
+			name:    "EmptyValue",
+			parse:   parseValue,
+			source:  "",
+			message: "Syntax Error: Unexpected <EOF>.",
+			line:    1,
+			column:  1,
+		},
+		{
+			name:    "UnclosedList",
+			parse:   parseValue,
+			source:  "[1, 2",
+			message: "Syntax Error: Unexpected <EOF>.",
+			line:    1,
+			column:  6,
+		},
+		{
+			name:    "DoubleNonNull",
+			parse:   parseType,
+			source:  "String!!",
+			message: `Syntax Error: Expected <EOF>, found "!".`,
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "UnclosedListType",
+			parse:   parseType,
+			source:  "[String",
+			message: "Syntax Error: Unexpected <EOF>.",
+			line:    1,
+			column:  8,
+		},
+		{
+			name:    "NotAType",
+			parse:   parseType,
+			source:  "1",
+			message: `Syntax Error: Unexpected Int "1".`,
+			line:    1,
+			column:  1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			printed, err := tt.parse([]byte(tt.source))
+			if tt.message == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if printed != tt.printed {
+					t.Errorf("expected '%s', got '%s'", tt.printed, printed)
+				}
+				return
+			}
+			perr, ok := err.(*parser.ParserError)
+			if !ok {
+				t.Fatalf("expected a *ParserError, got %#v", err)
+			}
+			if printed != "" {
+				t.Errorf("expected no value with the error, got '%s'", printed)
+			}
+			if perr.Message != tt.message || perr.Line != tt.line || perr.Column != tt.column {
+				t.Errorf("expected '%s' at %d:%d, got '%s' at %d:%d", tt.message, tt.line, tt.column, perr.Message, perr.Line, perr.Column)
+			}
+		})
+	}
+
+	for _, query := range []string{
+		"query ($a: Int = $b) { a }",
+		"type Query { f(a: [Int] = [1, $b]): Int }",
+	} {
+		if _, err := parser.Parse([]byte(query)); err == nil || !strings.Contains(err.Error(), "in constant value") {
+			t.Errorf("expected a variable in a default value to be rejected, got %v", err)
+		}
+	}
+}
+
+func parseValue(source []byte) (string, error) {
+	v, err := parser.ParseValue(source)
+	if v == nil {
+		return "", err
+	}
+	return printer.PrintValue(v), err
+}
+
+func parseConstValue(source []byte) (string, error) {
+	v, err := parser.ParseConstValue(source)
+	if v == nil {
+		return "", err
+	}
+	return printer.PrintValue(v), err
+}
+
+func parseType(source []byte) (string, error) {
+	t, err := parser.ParseType(source)
+	if t == nil {
+		return "", err
+	}
+	return printer.PrintType(t), err
 }
@@ -0,0 +1,236 @@
+package printer
+
+import (
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+func (p *printer) definition(def ast.Definition) {
+	switch def := def.(type) {
+	case *ast.SchemaDefinition:
+		p.comments(def.Comments)
+		p.write("schema")
+		p.directives(def.Directives)
+		p.rootOperations(def.RootOperations)
+	case *ast.SchemaExtension:
+		p.comments(def.Comments)
+		p.write("extend schema")
+		p.directives(def.Directives)
+		if len(def.RootOperations) != 0 {
+			p.rootOperations(def.RootOperations)
+		}
+	case *ast.ScalarDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.scalar(def.Name, def.Directives)
+	case *ast.ScalarExtension:
+		p.comments(def.Comments)
+		p.write("extend")
+		p.space()
+		p.scalar(def.Name, def.Directives)
+	case *ast.ObjectDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.object(def.Name, def.Implements, def.Directives, def.Fields)
+	case *ast.ObjectExtension:
+		p.comments(def.Comments)
+		p.write("extend")
+		p.space()
+		p.object(def.Name, def.Implements, def.Directives, def.Fields)
+	case *ast.InterfaceDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.words("interface", def.Name)
+		p.directives(def.Directives)
+		p.fieldDefinitions(def.Fields)
+	case *ast.InterfaceExtension:
+		p.comments(def.Comments)
+		p.words("extend", "interface", def.Name)
+		p.directives(def.Directives)
+		p.fieldDefinitions(def.Fields)
+	case *ast.UnionDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.union(def.Name, def.Directives, def.Members)
+	case *ast.UnionExtension:
+		p.comments(def.Comments)
+		p.write("extend")
+		p.space()
+		p.union(def.Name, def.Directives, def.Members)
+	case *ast.EnumDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.enum(def.Name, def.Directives, def.Values)
+	case *ast.EnumExtension:
+		p.comments(def.Comments)
+		p.write("extend")
+		p.space()
+		p.enum(def.Name, def.Directives, def.Values)
+	case *ast.InputObjectDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.inputObject(def.Name, def.Directives, def.Fields)
+	case *ast.InputObjectExtension:
+		p.comments(def.Comments)
+		p.write("extend")
+		p.space()
+		p.inputObject(def.Name, def.Directives, def.Fields)
+	case *ast.DirectiveDefinition:
+		p.comments(def.Comments)
+		p.description(def.Description)
+		p.write("directive @")
+		p.write(def.Name)
+		p.argumentDefinitions(def.Arguments)
+		p.space()
+		p.write("on")
+		p.space()
+		for i, l := range def.Locations {
+			if i != 0 {
+				p.space()
+				p.write("|")
+				p.space()
+			}
+			p.write(l)
+		}
+	}
+}
+
+func (p *printer) rootOperations(ops map[ast.OperationType]*ast.NamedType) {
+	ots := []ast.OperationType{}
+	for _, ot := range []ast.OperationType{ast.Query, ast.Mutation, ast.Subscription} {
+		if nt, ok := ops[ot]; ok && nt != nil {
+			ots = append(ots, ot)
+		}
+	}
+	p.block(len(ots), func(i int) {
+		p.write(ots[i].String())
+		p.write(":")
+		p.space()
+		p.write(ops[ots[i]].Name)
+	})
+}
+
+func (p *printer) scalar(name string, ds []*ast.Directive) {
+	p.words("scalar", name)
+	p.directives(ds)
+}
+
+func (p *printer) object(name string, ints []*ast.NamedType, ds []*ast.Directive, fs []*ast.FieldDefinition) {
+	p.words("type", name)
+	for i, nt := range ints {
+		if i == 0 {
+			p.space()
+			p.write("implements")
+			p.space()
+		} else {
+			p.space()
+			p.write("&")
+			p.space()
+		}
+		p.write(nt.Name)
+	}
+	p.directives(ds)
+	p.fieldDefinitions(fs)
+}
+
+func (p *printer) union(name string, ds []*ast.Directive, members []*ast.NamedType) {
+	p.words("union", name)
+	p.directives(ds)
+	for i, m := range members {
+		p.space()
+		if i == 0 {
+			p.write("=")
+		} else {
+			p.write("|")
+		}
+		p.space()
+		p.write(m.Name)
+	}
+}
+
+func (p *printer) enum(name string, ds []*ast.Directive, values []*ast.EnumValueDefinition) {
+	p.words("enum", name)
+	p.directives(ds)
+	if len(values) == 0 {
+		return
+	}
+	p.block(len(values), func(i int) {
+		p.comments(values[i].Comments)
+		p.description(values[i].Description)
+		p.write(values[i].Value.Value)
+		p.directives(values[i].Directives)
+	})
+}
+
+func (p *printer) inputObject(name string, ds []*ast.Directive, fs []*ast.InputValueDefinition) {
+	p.words("input", name)
+	p.directives(ds)
+	if len(fs) == 0 {
+		return
+	}
+	p.block(len(fs), func(i int) {
+		p.inputValueDefinition(fs[i])
+	})
+}
+
+func (p *printer) fieldDefinitions(fs []*ast.FieldDefinition) {
+	if len(fs) == 0 {
+		return
+	}
+	p.block(len(fs), func(i int) {
+		f := fs[i]
+		p.comments(f.Comments)
+		p.description(f.Description)
+		p.write(f.Name)
+		p.argumentDefinitions(f.Arguments)
+		p.write(":")
+		p.space()
+		p.typ(f.Type)
+		p.directives(f.Directives)
+	})
+}
+
+// argumentDefinitions prints the arguments in one line, or each of them
+// in a new line in the pretty form, if any of them has a description or comments
+func (p *printer) argumentDefinitions(args []*ast.InputValueDefinition) {
+	if len(args) == 0 {
+		return
+	}
+	multiline := false
+	for _, a := range args {
+		multiline = multiline || a.Description != "" || len(a.Comments) != 0
+	}
+	p.write("(")
+	if multiline {
+		p.level++
+		for _, a := range args {
+			p.newline()
+			p.inputValueDefinition(a)
+		}
+		p.level--
+		p.newline()
+	} else {
+		for i, a := range args {
+			if i != 0 {
+				p.separator()
+			}
+			p.inputValueDefinition(a)
+		}
+	}
+	p.write(")")
+}
+
+func (p *printer) inputValueDefinition(v *ast.InputValueDefinition) {
+	p.comments(v.Comments)
+	p.description(v.Description)
+	p.write(v.Name)
+	p.write(":")
+	p.space()
+	p.typ(v.Type)
+	if v.DefaultValue != nil {
+		p.space()
+		p.write("=")
+		p.space()
+		p.value(v.DefaultValue)
+	}
+	p.directives(v.Directives)
+}
@@ -0,0 +1,428 @@
+/*
+Package printer prints an AST back to valid GraphQL.
+
+Print returns a canonical, human readable form of the document, indented with two spaces,
+while PrintMinified returns the most compact form, without any ignored tokens that are not
+required to separate the names and numbers. Both of them can be parsed by parser.Parse,
+and the printed document is the same after printing the parsed document again.
+
+The comments kept by parser.ParseWithOptions are printed in the pretty form before the nodes
+they belong to, and the rest of them at the end of the document. The minified form has no comments.
+*/
+package printer
+
+import (
+	"strings"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+// Print returns the document in a pretty, human readable form
+func Print(doc *ast.Document) string {
+	p := &printer{}
+	p.document(doc)
+	return p.sb.String()
+}
+
+// PrintMinified returns the document in its most compact form
+func PrintMinified(doc *ast.Document) string {
+	p := &printer{minify: true}
+	p.document(doc)
+	return p.sb.String()
+}
+
+// PrintValue returns the value as it would be printed in a pretty document
+func PrintValue(v ast.Value) string {
+	p := &printer{}
+	p.value(v)
+	return p.sb.String()
+}
+
+// PrintType returns the type reference, like '[String!]!'
+func PrintType(t ast.Type) string {
+	p := &printer{}
+	p.typ(t)
+	return p.sb.String()
+}
+
+type printer struct {
+	sb     strings.Builder
+	minify bool
+	level  int
+}
+
+// write writes the string, and in the minified form a space before it, if it would
+// be a part of the previous name or number otherwise
+func (p *printer) write(s string) {
+	if s == "" {
+		return
+	}
+	if p.minify && p.sb.Len() != 0 {
+		out := p.sb.String()
+		if isNameRune(out[len(out)-1]) && isNameRune(s[0]) {
+			p.sb.WriteByte(' ')
+		}
+	}
+	p.sb.WriteString(s)
+}
+
+// words writes the names separated by a space
+func (p *printer) words(ws ...string) {
+	for i, w := range ws {
+		if i != 0 {
+			p.space()
+		}
+		p.write(w)
+	}
+}
+
+// space writes a space in the pretty form only
+func (p *printer) space() {
+	if !p.minify {
+		p.sb.WriteByte(' ')
+	}
+}
+
+// newline starts a new line with the current indentation in the pretty form only
+func (p *printer) newline() {
+	if !p.minify {
+		p.sb.WriteByte('\n')
+		p.sb.WriteString(strings.Repeat("  ", p.level))
+	}
+}
+
+// separator writes a comma and a space between list items in the pretty form only
+func (p *printer) separator() {
+	if !p.minify {
+		p.sb.WriteString(", ")
+	}
+}
+
+func isNameRune(b byte) bool {
+	return b == '_' || ('a' <= b && b <= 'z') || ('A' <= b && b <= 'Z') || ('0' <= b && b <= '9')
+}
+
+// block prints the items between braces, each of them in a new line in the pretty form
+func (p *printer) block(n int, item func(i int)) {
+	p.space()
+	p.write("{")
+	p.level++
+	for i := 0; i < n; i++ {
+		p.newline()
+		item(i)
+	}
+	p.level--
+	p.newline()
+	p.write("}")
+}
+
+func (p *printer) document(doc *ast.Document) {
+	first := true
+	next := func() {
+		if !first && !p.minify {
+			p.sb.WriteString("\n\n")
+		}
+		first = false
+	}
+	for _, def := range doc.Definitions {
+		next()
+		p.definition(def)
+	}
+	for _, op := range doc.Operations {
+		next()
+		p.operation(op)
+	}
+	for _, f := range doc.Fragments {
+		next()
+		p.fragment(f)
+	}
+	if len(doc.Comments) != 0 && !p.minify {
+		next()
+		for i, c := range doc.Comments {
+			if i != 0 {
+				p.sb.WriteByte('\n')
+			}
+			p.sb.WriteString("#" + c.Value)
+		}
+	}
+	if !first && !p.minify {
+		p.sb.WriteByte('\n')
+	}
+}
+
+func (p *printer) operation(op *ast.Operation) {
+	p.comments(op.Comments)
+	// use the query shorthand when it's possible
+	if op.OperationType == ast.Query && op.Name == "" && len(op.Variables) == 0 && len(op.Directives) == 0 {
+		p.selectionSet(op.SelectionSet)
+		return
+	}
+	if op.Name != "" {
+		p.words(op.OperationType.String(), op.Name)
+	} else {
+		p.write(op.OperationType.String())
+	}
+	if len(op.Variables) != 0 {
+		p.write("(")
+		for i, v := range op.Variables {
+			if i != 0 {
+				p.separator()
+			}
+			p.write("$")
+			p.write(v.Name)
+			p.write(":")
+			p.space()
+			p.typ(v.Type)
+			if v.DefaultValue != nil {
+				p.space()
+				p.write("=")
+				p.space()
+				p.value(v.DefaultValue)
+			}
+		}
+		p.write(")")
+	}
+	p.directives(op.Directives)
+	p.space()
+	p.selectionSet(op.SelectionSet)
+}
+
+func (p *printer) fragment(f *ast.Fragment) {
+	p.comments(f.Comments)
+	p.words("fragment", f.Name, "on", f.TypeCondition)
+	p.directives(f.Directives)
+	p.space()
+	p.selectionSet(f.SelectionSet)
+}
+
+func (p *printer) selectionSet(set []ast.Selection) {
+	p.write("{")
+	p.level++
+	for _, sel := range set {
+		p.newline()
+		p.selection(sel)
+	}
+	p.level--
+	p.newline()
+	p.write("}")
+}
+
+func (p *printer) selection(sel ast.Selection) {
+	switch sel := sel.(type) {
+	case *ast.Field:
+		p.comments(sel.Comments)
+		if sel.Alias != "" && sel.Alias != sel.Name {
+			p.write(sel.Alias)
+			p.write(":")
+			p.space()
+		}
+		p.write(sel.Name)
+		p.arguments(sel.Arguments)
+		p.directives(sel.Directives)
+		if len(sel.SelectionSet) != 0 {
+			p.space()
+			p.selectionSet(sel.SelectionSet)
+		}
+	case *ast.FragmentSpread:
+		p.comments(sel.Comments)
+		p.write("...")
+		p.write(sel.Name)
+		p.directives(sel.Directives)
+	case *ast.InlineFragment:
+		p.comments(sel.Comments)
+		p.write("...")
+		if sel.TypeCondition != "" {
+			p.space()
+			p.words("on", sel.TypeCondition)
+		}
+		p.directives(sel.Directives)
+		p.space()
+		p.selectionSet(sel.SelectionSet)
+	}
+}
+
+func (p *printer) arguments(args []*ast.Argument) {
+	if len(args) == 0 {
+		return
+	}
+	p.write("(")
+	for i, a := range args {
+		if i != 0 {
+			p.separator()
+		}
+		p.write(a.Name)
+		p.write(":")
+		p.space()
+		p.value(a.Value)
+	}
+	p.write(")")
+}
+
+func (p *printer) directives(ds []*ast.Directive) {
+	for _, d := range ds {
+		p.space()
+		p.write("@")
+		p.write(d.Name)
+		p.arguments(d.Arguments)
+	}
+}
+
+func (p *printer) typ(t ast.Type) {
+	switch t := t.(type) {
+	case *ast.NamedType:
+		p.write(t.Name)
+	case *ast.ListType:
+		p.write("[")
+		p.typ(t.Type)
+		p.write("]")
+	case *ast.NonNullType:
+		p.typ(t.Type)
+		p.write("!")
+	}
+}
+
+func (p *printer) value(v ast.Value) {
+	switch v := v.(type) {
+	case *ast.VariableValue:
+		p.write("$")
+		p.write(v.Name)
+	case *ast.IntValue:
+		p.write(v.Value)
+	case *ast.FloatValue:
+		p.write(v.Value)
+	case *ast.BooleanValue:
+		p.write(v.Value)
+	case *ast.NullValue:
+		p.write("null")
+	case *ast.EnumValue:
+		p.write(v.Value)
+	case *ast.StringValue:
+		p.str(v.Value, v.Block)
+	case *ast.ListValue:
+		p.write("[")
+		for i, lv := range v.Values {
+			if i != 0 {
+				p.separator()
+			}
+			p.value(lv)
+		}
+		p.write("]")
+	case *ast.ObjectValue:
+		p.write("{")
+		for i, f := range v.Fields {
+			if i != 0 {
+				p.separator()
+			}
+			p.write(f.Name)
+			p.write(":")
+			p.space()
+			p.value(f.Value)
+		}
+		p.write("}")
+	}
+}
+
+// str prints a string value, as a block string if it was given as one and it can be printed
+// as a block string without changing its value
+func (p *printer) str(s string, block bool) {
+	if block && !p.minify && canBeBlockString(s) {
+		p.blockString(s)
+		return
+	}
+	p.write(quote(s))
+}
+
+func (p *printer) blockString(s string) {
+	indent := strings.Repeat("  ", p.level)
+	p.write(`"""`)
+	for _, line := range strings.Split(s, "\n") {
+		p.sb.WriteByte('\n')
+		if line != "" {
+			p.sb.WriteString(indent)
+			p.sb.WriteString(line)
+		}
+	}
+	p.sb.WriteByte('\n')
+	p.sb.WriteString(indent)
+	p.sb.WriteString(`"""`)
+}
+
+// canBeBlockString tells if parsing the string printed as an indented block string
+// would result in the same value
+func canBeBlockString(s string) bool {
+	if s == "" || strings.Contains(s, `"""`) {
+		return false
+	}
+	for _, r := range s {
+		if r < ' ' && r != '\t' && r != '\n' {
+			return false
+		}
+	}
+	lines := strings.Split(s, "\n")
+	if strings.TrimLeft(lines[0], " \t") == "" || strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		return false
+	}
+	// the common indentation would be removed
+	for _, line := range lines {
+		if line != "" && line[0] != ' ' && line[0] != '\t' {
+			return true
+		}
+	}
+	return false
+}
+
+// quote returns the string as a GraphQL string value, with the escapes required by the spec
+func quote(s string) string {
+	const hex = "0123456789ABCDEF"
+	sb := strings.Builder{}
+	sb.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\b':
+			sb.WriteString(`\b`)
+		case '\f':
+			sb.WriteString(`\f`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			if r < ' ' {
+				sb.WriteString(`\u00`)
+				sb.WriteByte(hex[r>>4])
+				sb.WriteByte(hex[r&0xF])
+			} else {
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+// comments prints the leading comments of a node, each of them in its own line,
+// in the pretty form only
+func (p *printer) comments(cs []*ast.Comment) {
+	if p.minify {
+		return
+	}
+	for _, c := range cs {
+		p.sb.WriteString("#" + c.Value)
+		p.newline()
+	}
+}
+
+// description prints the description of a type system definition in its own line
+func (p *printer) description(desc string) {
+	if desc == "" {
+		return
+	}
+	p.str(desc, strings.Contains(desc, "\n"))
+	p.newline()
+}
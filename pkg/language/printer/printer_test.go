@@ -0,0 +1,219 @@
+package printer_test
+
+import (
+	"testing"
+
+	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/language/printer"
+)
+
+const kitchenSink = `
+query Q($id: ID! = "1", $ids: [Int!] = [1, 2], $o: In = {a: 1.5e3, b: [true, null], c: {}}) @dir(a: ENUM) {
+	alias: node(id: $id, s: "esc\"aped\\ \n\t") @include(if: true) {
+		id
+		... on Person @skip(if: false) {
+			name
+		}
+		...Frag @dir
+		... @include(if: $b) {
+			x
+		}
+	}
+	other
+}
+
+{ a }
+
+mutation { b(x: -1) }
+
+subscription S { c }
+
+fragment Frag on Person @dir {
+	name
+}
+
+"""
+Some description
+  with indentation
+"""
+type Person implements Node & Entity @key(fields: "id") {
+	"the id"
+	id: ID!
+	friends(
+		"how many"
+		first: Int = 10
+		after: String
+	): [Person!]! @deprecated(reason: "no")
+	name(short: Boolean = false, upper: Boolean): String
+}
+
+schema @dir {
+	query: Query
+	mutation: Mutation
+}
+
+scalar Time @specifiedBy(url: "https://example.com")
+
+interface Node @dir {
+	id: ID!
+}
+
+union Entity @dir = Person | Company
+
+"directions"
+enum Direction @dir {
+	"up"
+	UP @deprecated
+	DOWN
+}
+
+input Point @dir {
+	x: Float = 1.5 @dir
+	"y"
+	y: Float!
+}
+
+directive @dir(a: Direction = UP, b: [String!]) on QUERY | FIELD | OBJECT
+
+extend schema @dir
+extend scalar Time @dir
+extend type Person implements Other @dir { age: Int }
+extend interface Node { createdAt: Time }
+extend union Entity = Animal
+extend enum Direction { LEFT }
+extend input Point { z: Float }
+`
+
+func TestPrint(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		pretty   string
+		minified string
+	}{
+		{
+			name:  "Shorthand",
+			query: `query { a b }`,
+			pretty: `{
+  a
+  b
+}
+`,
+			minified: `{a b}`,
+		},
+		{
+			name:  "Operation",
+			query: `query Q($a: Int = 1, $b: [String!]!) @d(x: [1, 2]) { a: b(c: $a, d: {e: "f", g: 1.5}) { ...F ... on T { h } } }`,
+			pretty: `query Q($a: Int = 1, $b: [String!]!) @d(x: [1, 2]) {
+  a: b(c: $a, d: {e: "f", g: 1.5}) {
+    ...F
+    ... on T {
+      h
+    }
+  }
+}
+`,
+			minified: `query Q($a:Int=1$b:[String!]!)@d(x:[1 2]){a:b(c:$a d:{e:"f"g:1.5}){...F...on T{h}}}`,
+		},
+		{
+			name: "Definitions",
+			query: `
+"""
+Multi
+line
+"""
+type A implements B & C @d { "f" f(a: Int = 1): [A!] }
+enum E { X Y }
+directive @d(a: Int) on FIELD | OBJECT`,
+			pretty: `"""
+Multi
+line
+"""
+type A implements B & C @d {
+  "f"
+  f(a: Int = 1): [A!]
+}
+
+enum E {
+  X
+  Y
+}
+
+directive @d(a: Int) on FIELD | OBJECT
+`,
+			minified: `"Multi\nline"type A implements B&C@d{"f"f(a:Int=1):[A!]}enum E{X Y}directive @d(a:Int)on FIELD|OBJECT`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err != nil {
+				t.Fatalf("parse error: %v", err)
+			}
+			if out := printer.Print(doc); out != tt.pretty {
+				t.Errorf("expected pretty output\n%s\ngot\n%s", tt.pretty, out)
+			}
+			if out := printer.PrintMinified(doc); out != tt.minified {
+				t.Errorf("expected minified output\n%s\ngot\n%s", tt.minified, out)
+			}
+		})
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	doc, err := parser.Parse([]byte(kitchenSink))
+	if err != nil {
+		t.Fatalf("parse error: %v\n%s", err, err.(*parser.ParserError).Excerpt)
+	}
+	pretty := printer.Print(doc)
+	minified := printer.PrintMinified(doc)
+
+	for name, printed := range map[string]string{"pretty": pretty, "minified": minified} {
+		doc, err := parser.Parse([]byte(printed))
+		if err != nil {
+			t.Fatalf("parse error of the %s output: %v\n%s", name, err, printed)
+		}
+		if out := printer.Print(doc); out != pretty {
+			t.Errorf("%s output does not round-trip, expected\n%s\ngot\n%s", name, pretty, out)
+		}
+		if out := printer.PrintMinified(doc); out != minified {
+			t.Errorf("%s output does not round-trip, expected\n%s\ngot\n%s", name, minified, out)
+		}
+	}
+}
+
+func TestPrintComments(t *testing.T) {
+	source := `# the schema
+type Query {
+  # the id
+  id: ID
+  name(
+    # the argument
+    a: Int
+  ): String
+}
+
+# the operation
+{
+  # a field
+  a
+  ... on Query {
+    # b
+    b
+  }
+}
+
+# at the end
+# of the document
+`
+	doc, err := parser.ParseWithOptions([]byte(source), parser.Options{Comments: true})
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	if out := printer.Print(doc); out != source {
+		t.Errorf("expected\n%s\ngot\n%s", source, out)
+	}
+	if out, expected := printer.PrintMinified(doc), "type Query{id:ID name(a:Int):String}{a...on Query{b}}"; out != expected {
+		t.Errorf("expected\n%s\ngot\n%s", expected, out)
+	}
+}
@@ -0,0 +1,201 @@
+package visitor
+
+import (
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+// Kind is the kind of an AST node
+type Kind int
+
+const (
+	// UnknownKind is the kind of anything that is not an AST node
+	UnknownKind Kind = iota
+
+	// Executable documents
+	DocumentKind
+	OperationKind
+	FragmentKind
+	VariableKind
+	FieldKind
+	FragmentSpreadKind
+	InlineFragmentKind
+	ArgumentKind
+	DirectiveKind
+
+	// Types
+	NamedTypeKind
+	ListTypeKind
+	NonNullTypeKind
+
+	// Values
+	VariableValueKind
+	IntValueKind
+	FloatValueKind
+	StringValueKind
+	BooleanValueKind
+	NullValueKind
+	EnumValueKind
+	ListValueKind
+	ObjectValueKind
+	ObjectFieldValueKind
+
+	// Type system definitions
+	SchemaDefinitionKind
+	ScalarDefinitionKind
+	ObjectDefinitionKind
+	FieldDefinitionKind
+	InputValueDefinitionKind
+	InterfaceDefinitionKind
+	UnionDefinitionKind
+	EnumDefinitionKind
+	EnumValueDefinitionKind
+	InputObjectDefinitionKind
+	DirectiveDefinitionKind
+
+	// Type system extensions
+	SchemaExtensionKind
+	ScalarExtensionKind
+	ObjectExtensionKind
+	InterfaceExtensionKind
+	UnionExtensionKind
+	EnumExtensionKind
+	InputObjectExtensionKind
+)
+
+var kindNames = []string{
+	"Unknown",
+	"Document",
+	"Operation",
+	"Fragment",
+	"Variable",
+	"Field",
+	"FragmentSpread",
+	"InlineFragment",
+	"Argument",
+	"Directive",
+	"NamedType",
+	"ListType",
+	"NonNullType",
+	"VariableValue",
+	"IntValue",
+	"FloatValue",
+	"StringValue",
+	"BooleanValue",
+	"NullValue",
+	"EnumValue",
+	"ListValue",
+	"ObjectValue",
+	"ObjectFieldValue",
+	"SchemaDefinition",
+	"ScalarDefinition",
+	"ObjectDefinition",
+	"FieldDefinition",
+	"InputValueDefinition",
+	"InterfaceDefinition",
+	"UnionDefinition",
+	"EnumDefinition",
+	"EnumValueDefinition",
+	"InputObjectDefinition",
+	"DirectiveDefinition",
+	"SchemaExtension",
+	"ScalarExtension",
+	"ObjectExtension",
+	"InterfaceExtension",
+	"UnionExtension",
+	"EnumExtension",
+	"InputObjectExtension",
+}
+
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return kindNames[UnknownKind]
+}
+
+// KindOf returns the kind of the AST node
+func KindOf(node interface{}) Kind {
+	switch node.(type) {
+	case *ast.Document:
+		return DocumentKind
+	case *ast.Operation:
+		return OperationKind
+	case *ast.Fragment:
+		return FragmentKind
+	case *ast.Variable:
+		return VariableKind
+	case *ast.Field:
+		return FieldKind
+	case *ast.FragmentSpread:
+		return FragmentSpreadKind
+	case *ast.InlineFragment:
+		return InlineFragmentKind
+	case *ast.Argument:
+		return ArgumentKind
+	case *ast.Directive:
+		return DirectiveKind
+	case *ast.NamedType:
+		return NamedTypeKind
+	case *ast.ListType:
+		return ListTypeKind
+	case *ast.NonNullType:
+		return NonNullTypeKind
+	case *ast.VariableValue:
+		return VariableValueKind
+	case *ast.IntValue:
+		return IntValueKind
+	case *ast.FloatValue:
+		return FloatValueKind
+	case *ast.StringValue:
+		return StringValueKind
+	case *ast.BooleanValue:
+		return BooleanValueKind
+	case *ast.NullValue:
+		return NullValueKind
+	case *ast.EnumValue:
+		return EnumValueKind
+	case *ast.ListValue:
+		return ListValueKind
+	case *ast.ObjectValue:
+		return ObjectValueKind
+	case *ast.ObjectFieldValue:
+		return ObjectFieldValueKind
+	case *ast.SchemaDefinition:
+		return SchemaDefinitionKind
+	case *ast.ScalarDefinition:
+		return ScalarDefinitionKind
+	case *ast.ObjectDefinition:
+		return ObjectDefinitionKind
+	case *ast.FieldDefinition:
+		return FieldDefinitionKind
+	case *ast.InputValueDefinition:
+		return InputValueDefinitionKind
+	case *ast.InterfaceDefinition:
+		return InterfaceDefinitionKind
+	case *ast.UnionDefinition:
+		return UnionDefinitionKind
+	case *ast.EnumDefinition:
+		return EnumDefinitionKind
+	case *ast.EnumValueDefinition:
+		return EnumValueDefinitionKind
+	case *ast.InputObjectDefinition:
+		return InputObjectDefinitionKind
+	case *ast.DirectiveDefinition:
+		return DirectiveDefinitionKind
+	case *ast.SchemaExtension:
+		return SchemaExtensionKind
+	case *ast.ScalarExtension:
+		return ScalarExtensionKind
+	case *ast.ObjectExtension:
+		return ObjectExtensionKind
+	case *ast.InterfaceExtension:
+		return InterfaceExtensionKind
+	case *ast.UnionExtension:
+		return UnionExtensionKind
+	case *ast.EnumExtension:
+		return EnumExtensionKind
+	case *ast.InputObjectExtension:
+		return InputObjectExtensionKind
+	}
+	return UnknownKind
+}
@@ -0,0 +1,404 @@
+/*
+Package visitor walks the AST in depth-first order, calling the enter and leave functions
+of the visitors for every node, like
+
+	visitor.Walk(doc, &visitor.Visitor{
+		Kinds: map[visitor.Kind]visitor.Funcs{
+			visitor.FieldKind: {
+				Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+					log.Println(node.(*ast.Field).Name, info.Path)
+					return visitor.Continue
+				},
+			},
+		},
+	})
+
+Multiple visitors can run in parallel in a single pass, each of them can skip subtrees
+or stop walking independently of the others. With Edit, the visitors can also replace
+or delete the nodes.
+*/
+package visitor
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+// Action tells the walker how to continue after calling a visit function
+type Action int
+
+const (
+	// Continue walking as usual
+	Continue Action = iota
+	// Skip the children of the node (only when entering a node), the leave function of the visitor
+	// is not called for the node either
+	Skip
+	// Break stops walking for the visitor
+	Break
+	// Delete removes the node from its parent, only in Edit
+	Delete
+)
+
+// VisitFunc is called when entering or leaving a node
+type VisitFunc func(node interface{}, info *Info) Action
+
+// Funcs are the visit functions for a kind of node
+type Funcs struct {
+	Enter VisitFunc
+	Leave VisitFunc
+}
+
+/*
+Visitor has the functions to call when entering and leaving nodes. Enter and Leave are called
+for all the nodes, and the functions in Kinds only for the nodes of the given kind, after the
+generic ones.
+*/
+type Visitor struct {
+	Enter VisitFunc
+	Leave VisitFunc
+	Kinds map[Kind]Funcs
+}
+
+/*
+Info is the position of the current node in the AST. The slices in it are reused during
+the walk, copy them if they are needed after the visit function returns.
+*/
+type Info struct {
+	// Key of the node in its parent, the name of the field or the index in a list
+	Key interface{}
+	// Parent node, nil for the root
+	Parent interface{}
+	// Path of keys from the root to the node
+	Path []interface{}
+	// Ancestors of the node, starting with the root, not including the node
+	Ancestors []interface{}
+
+	replacement interface{}
+	replaced    bool
+}
+
+// Replace replaces the current node with the given one, only in Edit. When it's called while
+// entering a node, the children of the new node are visited. The new node must be usable in
+// the place of the current one, replacing it with nil deletes the node.
+func (i *Info) Replace(node interface{}) {
+	i.replacement = node
+	i.replaced = true
+}
+
+// Walk walks the AST from the root, calling the visitors in parallel
+func Walk(root interface{}, visitors ...*Visitor) {
+	w := newWalker(visitors, false)
+	w.visit(root, nil, nil)
+}
+
+// Edit walks the AST from the root like Walk, but the visitors can replace or delete the nodes,
+// which are modified in place. It returns the root, which is nil if it was deleted.
+func Edit(root interface{}, visitors ...*Visitor) interface{} {
+	w := newWalker(visitors, true)
+	node, deleted := w.visit(root, nil, nil)
+	if deleted {
+		return nil
+	}
+	return node
+}
+
+// broken marks a visitor that returned Break
+var broken = new(struct{})
+
+type walker struct {
+	visitors []*Visitor
+	// skipping has the node that is skipped by the visitor, or broken
+	skipping  []interface{}
+	active    int
+	edit      bool
+	path      []interface{}
+	ancestors []interface{}
+}
+
+func newWalker(visitors []*Visitor, edit bool) *walker {
+	return &walker{
+		visitors: visitors,
+		skipping: make([]interface{}, len(visitors)),
+		active:   len(visitors),
+		edit:     edit,
+	}
+}
+
+func (w *walker) done() bool {
+	return w.active == 0
+}
+
+// call calls the visit functions of the visitor, and returns the first action that is not Continue
+func (w *walker) call(f VisitFunc, kf VisitFunc, node interface{}, info *Info) Action {
+	if f != nil {
+		if a := f(node, info); a != Continue || info.replaced {
+			return a
+		}
+	}
+	if kf != nil {
+		return kf(node, info)
+	}
+	return Continue
+}
+
+// visit visits the node and its children, and returns the node that should be in its place
+func (w *walker) visit(node interface{}, key interface{}, parent interface{}) (interface{}, bool) {
+	kind := KindOf(node)
+	if kind == UnknownKind || reflect.ValueOf(node).IsNil() {
+		return node, false
+	}
+	if key != nil {
+		w.path = append(w.path, key)
+		defer func() {
+			w.path = w.path[:len(w.path)-1]
+		}()
+	}
+	info := &Info{
+		Key:       key,
+		Parent:    parent,
+		Path:      w.path,
+		Ancestors: w.ancestors,
+	}
+
+	// enter the node
+	walkChildren := false
+	for i, v := range w.visitors {
+		if w.skipping[i] != nil {
+			continue
+		}
+		a := w.call(v.Enter, v.Kinds[kind].Enter, node, info)
+		if w.edit && (a == Delete || info.replaced && info.replacement == nil) {
+			w.stopSkipping(node)
+			return nil, true
+		}
+		switch a {
+		case Skip:
+			w.skipping[i] = node
+		case Break:
+			w.skipping[i] = broken
+			w.active--
+		default:
+			walkChildren = true
+		}
+		if w.edit && info.replaced {
+			// the rest of the visitors enter the new node
+			w.replaceSkipping(node, info.replacement)
+			node, kind = info.replacement, KindOf(info.replacement)
+			info.replacement, info.replaced = nil, false
+		}
+	}
+
+	if walkChildren && !w.done() {
+		w.ancestors = append(w.ancestors, node)
+		w.children(node)
+		w.ancestors = w.ancestors[:len(w.ancestors)-1]
+	}
+
+	// leave the node
+	for i, v := range w.visitors {
+		if w.skipping[i] == node {
+			w.skipping[i] = nil
+			continue
+		} else if w.skipping[i] != nil {
+			continue
+		}
+		a := w.call(v.Leave, v.Kinds[kind].Leave, node, info)
+		if w.edit && (a == Delete || info.replaced && info.replacement == nil) {
+			w.stopSkipping(node)
+			return nil, true
+		}
+		if a == Break {
+			w.skipping[i] = broken
+			w.active--
+		}
+		if w.edit && info.replaced {
+			// the rest of the visitors leave the new node
+			w.replaceSkipping(node, info.replacement)
+			node, kind = info.replacement, KindOf(info.replacement)
+			info.replacement, info.replaced = nil, false
+		}
+	}
+	return node, false
+}
+
+// stopSkipping clears the skipping of the node, when it's deleted before leaving it
+func (w *walker) stopSkipping(node interface{}) {
+	for i := range w.skipping {
+		if w.skipping[i] == node {
+			w.skipping[i] = nil
+		}
+	}
+}
+
+// replaceSkipping makes the visitors skipping the node skip the replacement
+func (w *walker) replaceSkipping(node interface{}, replacement interface{}) {
+	if KindOf(replacement) == UnknownKind {
+		panic(fmt.Sprintf("visitor: %T is not an AST node", replacement))
+	}
+	for i := range w.skipping {
+		if w.skipping[i] == node {
+			w.skipping[i] = replacement
+		}
+	}
+}
+
+// children visits the fields of the node that can contain other nodes, in the order of the fields
+func (w *walker) children(node interface{}) {
+	v := reflect.ValueOf(node).Elem()
+	for _, f := range nodeFields(v.Type()) {
+		if w.done() {
+			return
+		}
+		fv := v.Field(f.index)
+		switch fv.Kind() {
+		case reflect.Slice:
+			w.list(node, f.name, fv)
+		case reflect.Map:
+			w.mapValues(node, f.name, fv)
+		default:
+			if fv.IsNil() {
+				continue
+			}
+			old := fv.Interface()
+			res, deleted := w.visit(old, f.name, node)
+			if !w.edit {
+				continue
+			}
+			if deleted {
+				fv.Set(reflect.Zero(fv.Type()))
+			} else if res != old {
+				fv.Set(assignable(res, fv.Type(), f.name))
+			}
+		}
+	}
+}
+
+func (w *walker) list(node interface{}, key string, list reflect.Value) {
+	if list.Len() == 0 {
+		return
+	}
+	w.path = append(w.path, key)
+	defer func() {
+		w.path = w.path[:len(w.path)-1]
+	}()
+
+	// the edited list is only built in edit mode, a read-only walk just visits the items
+	if !w.edit {
+		for i := 0; i < list.Len() && !w.done(); i++ {
+			w.visit(list.Index(i).Interface(), i, node)
+		}
+		return
+	}
+
+	edited := reflect.MakeSlice(list.Type(), 0, list.Len())
+	changed := false
+	for i := 0; i < list.Len(); i++ {
+		item := list.Index(i)
+		if w.done() {
+			edited = reflect.Append(edited, item)
+			continue
+		}
+		old := item.Interface()
+		res, deleted := w.visit(old, i, node)
+		if deleted {
+			changed = true
+			continue
+		} else if res != old {
+			changed = true
+			edited = reflect.Append(edited, assignable(res, list.Type().Elem(), key))
+			continue
+		}
+		edited = reflect.Append(edited, item)
+	}
+	if changed {
+		list.Set(edited)
+	}
+}
+
+func (w *walker) mapValues(node interface{}, key string, m reflect.Value) {
+	if m.Len() == 0 {
+		return
+	}
+	w.path = append(w.path, key)
+	defer func() {
+		w.path = w.path[:len(w.path)-1]
+	}()
+
+	// visit the values in the order of their keys
+	keys := m.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].Kind() == reflect.Int {
+			return keys[i].Int() < keys[j].Int()
+		}
+		return fmt.Sprint(keys[i].Interface()) < fmt.Sprint(keys[j].Interface())
+	})
+	for _, k := range keys {
+		if w.done() {
+			return
+		}
+		old := m.MapIndex(k).Interface()
+		res, deleted := w.visit(old, k.Interface(), node)
+		if !w.edit {
+			continue
+		}
+		if deleted {
+			m.SetMapIndex(k, reflect.Value{})
+		} else if res != old {
+			m.SetMapIndex(k, assignable(res, m.Type().Elem(), key))
+		}
+	}
+}
+
+func assignable(node interface{}, t reflect.Type, key string) reflect.Value {
+	if node == nil {
+		return reflect.Zero(t)
+	}
+	v := reflect.ValueOf(node)
+	if !v.Type().AssignableTo(t) {
+		panic(fmt.Sprintf("visitor: a node in '%s' can not be replaced with %T", key, node))
+	}
+	return v
+}
+
+type nodeField struct {
+	index int
+	name  string
+}
+
+var nodeFieldsCache sync.Map
+
+// nodeFields returns the fields of the struct type that can contain nodes
+func nodeFields(t reflect.Type) []nodeField {
+	if fs, ok := nodeFieldsCache.Load(t); ok {
+		return fs.([]nodeField)
+	}
+	fs := []nodeField{}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		ft := f.Type
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Map {
+			ft = ft.Elem()
+		}
+		if isNodeType(ft) {
+			fs = append(fs, nodeField{index: i, name: f.Name})
+		}
+	}
+	nodeFieldsCache.Store(t, fs)
+	return fs
+}
+
+var astPkgPath = reflect.TypeOf(ast.Document{}).PkgPath()
+
+func isNodeType(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Interface:
+		return t.PkgPath() == astPkgPath
+	case reflect.Ptr:
+		return KindOf(reflect.Zero(t).Interface()) != UnknownKind
+	}
+	return false
+}
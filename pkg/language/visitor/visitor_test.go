@@ -0,0 +1,230 @@
+package visitor_test
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/language/printer"
+	"github.com/rigglo/gql/pkg/language/visitor"
+)
+
+func parse(t *testing.T, query string) *ast.Document {
+	doc, err := parser.Parse([]byte(query))
+	if err != nil {
+		t.Fatalf("parse error: %v", err)
+	}
+	return doc
+}
+
+// trace records the enter and leave calls of the visited nodes
+func trace(events *[]string, name string) *visitor.Visitor {
+	return &visitor.Visitor{
+		Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+			*events = append(*events, fmt.Sprintf("%senter %v", name, visitor.KindOf(node)))
+			return visitor.Continue
+		},
+		Leave: func(node interface{}, info *visitor.Info) visitor.Action {
+			*events = append(*events, fmt.Sprintf("%sleave %v", name, visitor.KindOf(node)))
+			return visitor.Continue
+		},
+	}
+}
+
+func TestWalk(t *testing.T) {
+	doc := parse(t, `query Q($a: Int = 1) { a(x: [$a]) @skip(if: false) { ...F } }`)
+	events := []string{}
+	visitor.Walk(doc, trace(&events, ""))
+	expected := []string{
+		"enter Document",
+		"enter Operation",
+		"enter Variable",
+		"enter NamedType",
+		"leave NamedType",
+		"enter IntValue",
+		"leave IntValue",
+		"leave Variable",
+		"enter Field",
+		"enter Argument",
+		"enter ListValue",
+		"enter VariableValue",
+		"leave VariableValue",
+		"leave ListValue",
+		"leave Argument",
+		"enter Directive",
+		"enter Argument",
+		"enter BooleanValue",
+		"leave BooleanValue",
+		"leave Argument",
+		"leave Directive",
+		"enter FragmentSpread",
+		"leave FragmentSpread",
+		"leave Field",
+		"leave Operation",
+		"leave Document",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
+
+func TestWalkInfo(t *testing.T) {
+	doc := parse(t, `{ a { b(x: 1) } }`)
+	var (
+		path      []interface{}
+		ancestors []string
+		parent    interface{}
+	)
+	visitor.Walk(doc, &visitor.Visitor{
+		Kinds: map[visitor.Kind]visitor.Funcs{
+			visitor.IntValueKind: {
+				Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+					path = append(path, info.Path...)
+					for _, a := range info.Ancestors {
+						ancestors = append(ancestors, visitor.KindOf(a).String())
+					}
+					parent = info.Parent
+					return visitor.Continue
+				},
+			},
+		},
+	})
+	expectedPath := []interface{}{"Operations", 0, "SelectionSet", 0, "SelectionSet", 0, "Arguments", 0, "Value"}
+	if !reflect.DeepEqual(path, expectedPath) {
+		t.Errorf("expected path %v, got %v", expectedPath, path)
+	}
+	expectedAncestors := []string{"Document", "Operation", "Field", "Field", "Argument"}
+	if !reflect.DeepEqual(ancestors, expectedAncestors) {
+		t.Errorf("expected ancestors %v, got %v", expectedAncestors, ancestors)
+	}
+	if arg, ok := parent.(*ast.Argument); !ok || arg.Name != "x" {
+		t.Errorf("expected parent to be argument 'x', got %#v", parent)
+	}
+}
+
+func TestSkipAndBreak(t *testing.T) {
+	doc := parse(t, `{ a { b } c { d } e }`)
+	names := func(skip string, stop string) []string {
+		out := []string{}
+		visitor.Walk(doc, &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+						name := node.(*ast.Field).Name
+						out = append(out, name)
+						if name == skip {
+							return visitor.Skip
+						} else if name == stop {
+							return visitor.Break
+						}
+						return visitor.Continue
+					},
+					Leave: func(node interface{}, info *visitor.Info) visitor.Action {
+						out = append(out, "/"+node.(*ast.Field).Name)
+						return visitor.Continue
+					},
+				},
+			},
+		})
+		return out
+	}
+
+	if out, expected := names("a", ""), []string{"a", "c", "d", "/d", "/c", "e", "/e"}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %v when skipping, got %v", expected, out)
+	}
+	if out, expected := names("", "c"), []string{"a", "b", "/b", "/a", "c"}; !reflect.DeepEqual(out, expected) {
+		t.Errorf("expected %v when breaking, got %v", expected, out)
+	}
+}
+
+func TestParallel(t *testing.T) {
+	doc := parse(t, `{ a { b } c }`)
+	events := []string{}
+	skipper := trace(&events, "1:")
+	skipper.Kinds = map[visitor.Kind]visitor.Funcs{
+		visitor.FieldKind: {
+			Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+				if node.(*ast.Field).Name == "a" {
+					return visitor.Skip
+				}
+				return visitor.Continue
+			},
+		},
+	}
+	breaker := trace(&events, "2:")
+	breaker.Kinds = map[visitor.Kind]visitor.Funcs{
+		visitor.FieldKind: {
+			Leave: func(node interface{}, info *visitor.Info) visitor.Action {
+				return visitor.Break
+			},
+		},
+	}
+	visitor.Walk(doc, skipper, breaker)
+	expected := []string{
+		"1:enter Document", "2:enter Document",
+		"1:enter Operation", "2:enter Operation",
+		"1:enter Field", "2:enter Field",
+		"2:enter Field",
+		"2:leave Field",
+		"1:enter Field",
+		"1:leave Field",
+		"1:leave Operation",
+		"1:leave Document",
+	}
+	if !reflect.DeepEqual(events, expected) {
+		t.Errorf("expected events\n%s\ngot\n%s", strings.Join(expected, "\n"), strings.Join(events, "\n"))
+	}
+}
+
+func TestEdit(t *testing.T) {
+	doc := parse(t, `query Q { a @remove b(x: 1) { c } d @remove(if: true) }`)
+	res := visitor.Edit(doc, &visitor.Visitor{
+		Kinds: map[visitor.Kind]visitor.Funcs{
+			visitor.FieldKind: {
+				Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+					for _, d := range node.(*ast.Field).Directives {
+						if d.Name == "remove" {
+							return visitor.Delete
+						}
+					}
+					return visitor.Continue
+				},
+			},
+			visitor.IntValueKind: {
+				Leave: func(node interface{}, info *visitor.Info) visitor.Action {
+					info.Replace(&ast.VariableValue{Name: "x"})
+					return visitor.Continue
+				},
+			},
+			visitor.OperationKind: {
+				Leave: func(node interface{}, info *visitor.Info) visitor.Action {
+					op := *node.(*ast.Operation)
+					op.Name = "Edited"
+					info.Replace(&op)
+					return visitor.Continue
+				},
+			},
+		},
+	})
+	if res != doc {
+		t.Fatalf("expected the root to stay the same")
+	}
+	expected := "query Edited {\n  b(x: $x) {\n    c\n  }\n}\n"
+	if out := printer.Print(doc); out != expected {
+		t.Errorf("expected edited document\n%s\ngot\n%s", expected, out)
+	}
+
+	// the AST must not change in Walk
+	visitor.Walk(doc, &visitor.Visitor{
+		Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+			info.Replace(&ast.NullValue{})
+			return visitor.Delete
+		},
+	})
+	if out := printer.Print(doc); out != expected {
+		t.Errorf("expected document not to change in Walk, got\n%s", out)
+	}
+}
@@ -9,6 +9,9 @@ import (
 func init() {
 	DogType.Implements = gql.Interfaces{PetInterface}
 	CatType.Implements = gql.Interfaces{PetInterface}
+	HumanType.Fields["pets"] = &gql.Field{
+		Type: gql.NewList(PetInterface),
+	}
 }
 
 type Dog struct {
@@ -65,7 +68,8 @@ var (
 	}
 
 	Schema = &gql.Schema{
-		Query: Query,
+		Query:        Query,
+		Subscription: Subscription,
 	}
 
 	Query = &gql.Object{
@@ -125,6 +129,23 @@ var (
 					return true, nil
 				},
 			},
+			"human": &gql.Field{
+				Type: HumanType,
+				Arguments: gql.Arguments{
+					"id": &gql.Argument{
+						Type: gql.ID,
+					},
+				},
+				Resolver: func(c gql.Context) (interface{}, error) {
+					return Bob, nil
+				},
+			},
+			"complicatedArgs": &gql.Field{
+				Type: ComplicatedArgs,
+				Resolver: func(c gql.Context) (interface{}, error) {
+					return true, nil
+				},
+			},
 			"findDog": &gql.Field{
 				Type: DogType,
 				Arguments: gql.Arguments{
@@ -226,6 +247,113 @@ var (
 		},
 	}
 
+	// Subscription has the subscription root fields of the graphql-js validation test schema
+	Subscription = &gql.Object{
+		Name: "Subscription",
+		Fields: gql.Fields{
+			"importantEmails": &gql.Field{
+				Type: gql.NewList(gql.String),
+			},
+			"notImportantEmails": &gql.Field{
+				Type: gql.NewList(gql.String),
+			},
+			"moreImportantEmails": &gql.Field{
+				Type: gql.NewList(gql.String),
+			},
+		},
+	}
+
+	// ComplicatedArgs has fields with all kinds of arguments, as the type with the same name in
+	// the graphql-js validation test schema
+	ComplicatedArgs = &gql.Object{
+		Name: "ComplicatedArgs",
+		Fields: gql.Fields{
+			"intArgField":               argField("intArg", gql.Int, nil),
+			"nonNullIntArgField":        argField("nonNullIntArg", gql.NewNonNull(gql.Int), nil),
+			"stringArgField":            argField("stringArg", gql.String, nil),
+			"booleanArgField":           argField("booleanArg", gql.Boolean, nil),
+			"enumArgField":              argField("enumArg", FurColorEnum, nil),
+			"floatArgField":             argField("floatArg", gql.Float, nil),
+			"idArgField":                argField("idArg", gql.ID, nil),
+			"stringListArgField":        argField("stringListArg", gql.NewList(gql.String), nil),
+			"stringListNonNullArgField": argField("stringListNonNullArg", gql.NewList(gql.NewNonNull(gql.String)), nil),
+			"complexArgField":           argField("complexArg", ComplicatedInput, nil),
+			"oneOfArgField":             argField("oneOfArg", OneOfInput, nil),
+			"nonNullFieldWithDefault":   argField("arg", gql.NewNonNull(gql.Int), 0),
+			"multipleReqs": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"req1": &gql.Argument{Type: gql.NewNonNull(gql.Int)},
+					"req2": &gql.Argument{Type: gql.NewNonNull(gql.Int)},
+				},
+			},
+			"multipleOpts": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"opt1": &gql.Argument{Type: gql.Int, DefaultValue: 0},
+					"opt2": &gql.Argument{Type: gql.Int, DefaultValue: 0},
+				},
+			},
+			"multipleOptAndReq": &gql.Field{
+				Type: gql.String,
+				Arguments: gql.Arguments{
+					"req1": &gql.Argument{Type: gql.NewNonNull(gql.Int)},
+					"req2": &gql.Argument{Type: gql.NewNonNull(gql.Int)},
+					"opt1": &gql.Argument{Type: gql.Int, DefaultValue: 0},
+					"opt2": &gql.Argument{Type: gql.Int, DefaultValue: 0},
+				},
+			},
+		},
+	}
+
+	ComplicatedInput = &gql.InputObject{
+		Name: "ComplicatedInput",
+		Fields: gql.InputFields{
+			"requiredField": &gql.InputField{
+				Type: gql.NewNonNull(gql.Boolean),
+			},
+			"nonNullField": &gql.InputField{
+				Type:         gql.NewNonNull(gql.Boolean),
+				DefaultValue: false,
+			},
+			"intField": &gql.InputField{
+				Type: gql.Int,
+			},
+			"stringField": &gql.InputField{
+				Type: gql.String,
+			},
+			"booleanField": &gql.InputField{
+				Type: gql.Boolean,
+			},
+			"stringListField": &gql.InputField{
+				Type: gql.NewList(gql.String),
+			},
+		},
+	}
+
+	OneOfInput = &gql.InputObject{
+		Name:       "OneOfInput",
+		Directives: gql.TypeSystemDirectives{gql.OneOfInput()},
+		Fields: gql.InputFields{
+			"stringField": &gql.InputField{
+				Type: gql.String,
+			},
+			"intField": &gql.InputField{
+				Type: gql.Int,
+			},
+		},
+	}
+
+	FurColorEnum = &gql.Enum{
+		Name: "FurColor",
+		Values: gql.EnumValues{
+			&gql.EnumValue{Name: "BROWN", Value: 0},
+			&gql.EnumValue{Name: "BLACK", Value: 1},
+			&gql.EnumValue{Name: "TAN", Value: 2},
+			&gql.EnumValue{Name: "SPOTTED", Value: 3},
+		},
+	}
+
 	ComplexInput = &gql.InputObject{
 		Name: "ComplexInput",
 		Fields: gql.InputFields{
@@ -296,6 +424,17 @@ var (
 			"owner": &gql.Field{
 				Type: HumanType,
 			},
+			"isAtLocation": &gql.Field{
+				Arguments: gql.Arguments{
+					"x": &gql.Argument{
+						Type: gql.Int,
+					},
+					"y": &gql.Argument{
+						Type: gql.Int,
+					},
+				},
+				Type: gql.Boolean,
+			},
 		},
 	}
 
@@ -343,6 +482,9 @@ var (
 			"name": &gql.Field{
 				Type: gql.NewNonNull(gql.String),
 			},
+			"iq": &gql.Field{
+				Type: gql.Int,
+			},
 		},
 	}
 
@@ -404,3 +546,16 @@ var (
 		Members: gql.Members{HumanType, AlienType},
 	}
 )
+
+// argField returns a String field with a single argument
+func argField(name string, t gql.Type, defaultValue interface{}) *gql.Field {
+	return &gql.Field{
+		Type: gql.String,
+		Arguments: gql.Arguments{
+			name: &gql.Argument{
+				Type:         t,
+				DefaultValue: defaultValue,
+			},
+		},
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"math"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/rigglo/gql/pkg/language/ast"
@@ -164,15 +163,11 @@ var DateTime *Scalar = &Scalar{
 	},
 }
 
-func trimString(value string) string {
-	return strings.Trim(value, `"`)
-}
-
 func unserializeDateTime(value interface{}) (interface{}, error) {
 	switch value := value.(type) {
 	case ast.Value:
 		if v, ok := value.(*ast.StringValue); ok {
-			return unserializeDateTime(trimString(v.Value))
+			return unserializeDateTime(v.Value)
 		}
 		return nil, errors.New("invalid value for DateTime scalar")
 	case time.Time:
@@ -500,7 +500,7 @@ func Test_DateTimeScalarInput(t *testing.T) {
 		{
 			name: "astString",
 			value: &ast.StringValue{
-				Value: `"2015-10-21T07:28:05Z"`,
+				Value: `2015-10-21T07:28:05Z`,
 			},
 			expected: func() time.Time {
 				t, _ := time.Parse(time.RFC3339, "2015-10-21T07:28:05Z")
@@ -508,6 +508,14 @@ func Test_DateTimeScalarInput(t *testing.T) {
 			}(),
 			hasErr: false,
 		},
+		{
+			name: "astQuotedString",
+			value: &ast.StringValue{
+				Value: `"2015-10-21T07:28:05Z"`,
+			},
+			expected: nil,
+			hasErr:   true,
+		},
 		{
 			name: "astInt",
 			value: &ast.IntValue{
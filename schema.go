@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 
 	"github.com/rigglo/gql/pkg/language/ast"
@@ -32,6 +33,51 @@ func (s Schema) SDL() string {
 	return b.Build()
 }
 
+/*
+ValidateSchema checks the rules of the type system that can't be expressed with the Go types, like
+the fields of a OneOf Input Object being nullable and having no default values. It returns the
+violations as Errors, or nil if the schema is valid. NewExecutor runs it, and the executor of an
+invalid schema returns these errors for every request.
+*/
+func (s Schema) ValidateSchema() error {
+	if errs := validateSchema(&s); len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateSchema(s *Schema) Errors {
+	types, _, _ := getTypes(s)
+	names := make([]string, 0, len(types))
+	for name := range types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	errs := Errors{}
+	for _, name := range names {
+		o, ok := types[name].(*InputObject)
+		if !ok || !o.IsOneOf() {
+			continue
+		}
+		fns := make([]string, 0, len(o.Fields))
+		for fn := range o.Fields {
+			fns = append(fns, fn)
+		}
+		sort.Strings(fns)
+		for _, fn := range fns {
+			f := o.Fields[fn]
+			if f.Type.GetKind() == NonNullKind {
+				errs = append(errs, &Error{Message: fmt.Sprintf("field '%s.%s' of a OneOf input object must be nullable", o.Name, fn)})
+			}
+			if f.IsDefaultValueSet() {
+				errs = append(errs, &Error{Message: fmt.Sprintf("field '%s.%s' of a OneOf input object must not have a default value", o.Name, fn)})
+			}
+		}
+	}
+	return errs
+}
+
 // TypeKind shows the kind of a Type
 type TypeKind uint
 
@@ -748,6 +794,9 @@ type Argument struct {
 	Description  string
 	Type         Type
 	DefaultValue interface{}
+	Directives   TypeSystemDirectives
+	// Constraints are checked on the value of the argument, after it's coerced
+	Constraints Constraints
 }
 
 /*
@@ -814,6 +863,19 @@ func (o *InputObject) GetFields() map[string]*InputField {
 	return o.Fields
 }
 
+/*
+IsOneOf returns if the @oneOf directive is set for the input object, so exactly one of its
+fields must be provided
+*/
+func (o *InputObject) IsOneOf() bool {
+	for _, d := range o.Directives {
+		if _, ok := d.(*oneOf); ok {
+			return true
+		}
+	}
+	return false
+}
+
 // String implements the fmt.Stringer
 func (o *InputObject) String() string {
 	return o.Name
@@ -828,6 +890,8 @@ type InputField struct {
 	Type         Type
 	DefaultValue interface{}
 	Directives   TypeSystemDirectives
+	// Constraints are checked on the value of the field, after it's coerced
+	Constraints Constraints
 }
 
 /*
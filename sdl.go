@@ -3,6 +3,7 @@ package gql
 import (
 	"fmt"
 	"reflect"
+	"sort"
 
 	"github.com/rigglo/gql/pkg/language/ast"
 )
@@ -54,7 +55,7 @@ func toAstValue(t Type, i interface{}) ast.Value {
 			}
 		case float32, float64:
 			return &ast.FloatValue{
-				Value: fmt.Sprintf("%f", i),
+				Value: fmt.Sprintf("%v", i),
 			}
 		default:
 			return &ast.IntValue{
@@ -62,6 +63,13 @@ func toAstValue(t Type, i interface{}) ast.Value {
 			}
 		}
 	case *Enum:
+		for _, ev := range t.Values {
+			if reflect.DeepEqual(ev.Value, i) {
+				return &ast.EnumValue{
+					Value: ev.Name,
+				}
+			}
+		}
 		return &ast.EnumValue{
 			Value: fmt.Sprintf("%v", i),
 		}
@@ -88,6 +96,8 @@ type sdlBuilder struct {
 	defs          []ast.Definition
 	typeDefs      map[string]bool
 	directiveDefs map[string]bool
+	// constraints is true if the schema has constraints, so @constraint has to be defined
+	constraints bool
 }
 
 func newSDLBuilder(s *Schema) *sdlBuilder {
@@ -98,6 +108,7 @@ func newSDLBuilder(s *Schema) *sdlBuilder {
 			"skip":       true,
 			"include":    true,
 			"deprecated": true,
+			"oneOf":      true,
 		},
 		typeDefs: map[string]bool{
 			"String":   true,
@@ -141,6 +152,10 @@ func (b *sdlBuilder) Build() string {
 		b.visitType(t)
 	}
 
+	if b.constraints {
+		b.defs = append(b.defs, directiveDefinition(constraintDirective))
+	}
+
 	b.defs = append(b.defs, sdef)
 	out := ""
 	for i, def := range b.defs {
@@ -195,7 +210,9 @@ func (b *sdlBuilder) visitType(t Type) {
 					Type:         typeToAst(a.Type),
 					DefaultValue: toAstValue(a.Type, a.DefaultValue),
 					Description:  a.Description,
+					Directives:   b.inputValueDirectives(a.Directives, a.Type, a.Constraints),
 				})
+				b.visitType(a.Type)
 			}
 			def.Fields = append(def.Fields, fdef)
 			b.visitType(f.Type)
@@ -263,7 +280,7 @@ func (b *sdlBuilder) visitType(t Type) {
 				Name:        fn,
 				Description: f.Description,
 				Type:        typeToAst(f.Type),
-				Directives:  f.Directives.ast(),
+				Directives:  b.inputValueDirectives(f.Directives, f.Type, f.Constraints),
 			})
 			b.visitType(f.Type)
 		}
@@ -271,6 +288,42 @@ func (b *sdlBuilder) visitType(t Type) {
 	}
 }
 
+// inputValueDirectives returns the directives of an argument or input field with its constraints
+func (b *sdlBuilder) inputValueDirectives(ds TypeSystemDirectives, t Type, cs Constraints) []*ast.Directive {
+	withCs := withConstraints(ds, t, cs)
+	if len(withCs) != len(ds) {
+		b.constraints = true
+	}
+	return withCs.ast()
+}
+
+// directiveDefinition returns the definition of the directive for the SDL
+func directiveDefinition(d Directive) *ast.DirectiveDefinition {
+	def := &ast.DirectiveDefinition{
+		Name:        d.GetName(),
+		Description: d.GetDescription(),
+		Locations:   make([]string, 0, len(d.GetLocations())),
+		Arguments:   make([]*ast.InputValueDefinition, 0, len(d.GetArguments())),
+	}
+	for _, l := range d.GetLocations() {
+		def.Locations = append(def.Locations, string(l))
+	}
+	args := d.GetArguments()
+	names := make([]string, 0, len(args))
+	for name := range args {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		def.Arguments = append(def.Arguments, &ast.InputValueDefinition{
+			Name:        name,
+			Description: args[name].Description,
+			Type:        typeToAst(args[name].Type),
+		})
+	}
+	return def
+}
+
 func (b *sdlBuilder) visitDirective(d Directive) {
 	if _, ok := b.directiveDefs[d.GetName()]; ok {
 		return
@@ -0,0 +1,31 @@
+package gql_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/rigglo/gql"
+)
+
+func Test_SDLFloatValues(t *testing.T) {
+	schema := &gql.Schema{
+		Query: &gql.Object{
+			Name: "Query",
+			Fields: gql.Fields{
+				"scale": &gql.Field{
+					Type: gql.Float,
+					Arguments: gql.Arguments{
+						"ratio": &gql.Argument{
+							Type:        gql.Float,
+							Constraints: gql.Constraints{gql.Min(0.25), gql.Max(2)},
+						},
+					},
+				},
+			},
+		},
+	}
+	sdl := schema.SDL()
+	if s := `ratio: Float @constraint(max: 2, min: 0.25)`; !strings.Contains(sdl, s) {
+		t.Fatalf("expected '%s' in the SDL, got\n%s", s, sdl)
+	}
+}
@@ -0,0 +1,157 @@
+package gql
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+)
+
+// maxSuggestions is the maximum number of suggestions in an error
+const maxSuggestions = 5
+
+/*
+suggestionList returns the options that are similar to the input, the ones within 40% of the
+input's length in edit distance, ordered by the distance and then by name
+*/
+func suggestionList(input string, options []string) []string {
+	threshold := len(input)*4/10 + 1
+	lowerInput := strings.ToLower(input)
+	distances := map[string]int{}
+	suggestions := []string{}
+	for _, o := range options {
+		if _, ok := distances[o]; ok {
+			continue
+		}
+		d := lexicalDistance(input, lowerInput, o, threshold)
+		if d <= threshold {
+			distances[o] = d
+			suggestions = append(suggestions, o)
+		}
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		di, dj := distances[suggestions[i]], distances[suggestions[j]]
+		if di != dj {
+			return di < dj
+		}
+		return suggestions[i] < suggestions[j]
+	})
+	return suggestions
+}
+
+/*
+lexicalDistance is the optimal string alignment distance of the strings, counting insertions,
+deletions, substitutions and transpositions of adjacent characters, where a difference only in
+the case of the characters counts as one. If the distance is surely greater than the threshold,
+it returns threshold+1 without computing it.
+*/
+func lexicalDistance(a, lowerA, b string, threshold int) int {
+	if a == b {
+		return 0
+	}
+	lowerB := strings.ToLower(b)
+	if lowerA == lowerB {
+		return 1
+	}
+	s, t := []rune(lowerA), []rune(lowerB)
+	if len(s) < len(t) {
+		s, t = t, s
+	}
+	if len(s)-len(t) > threshold {
+		return threshold + 1
+	}
+
+	rows := [3][]int{make([]int, len(t)+1), make([]int, len(t)+1), make([]int, len(t)+1)}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		prev, cur := rows[(i-1)%3], rows[i%3]
+		cur[0] = i
+		smallest := cur[0]
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d := min3(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				if tr := rows[(i-2)%3][j-2] + 1; tr < d {
+					d = tr
+				}
+			}
+			cur[j] = d
+			if d < smallest {
+				smallest = d
+			}
+		}
+		// the distance can't get smaller in the next rows
+		if smallest > threshold {
+			return threshold + 1
+		}
+	}
+	return rows[len(s)%3][len(t)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+// quotedOrList lists the items like 'a', 'b', or 'c'
+func quotedOrList(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = "'" + item + "'"
+	}
+	switch len(quoted) {
+	case 0:
+		return ""
+	case 1:
+		return quoted[0]
+	case 2:
+		return quoted[0] + " or " + quoted[1]
+	}
+	return strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1]
+}
+
+// didYouMean is the hint added to the error messages, empty if there are no suggestions
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ". Did you mean " + quotedOrList(suggestions) + "?"
+}
+
+// newSuggestionError creates a validation error with the suggestions in its message and in
+// its extensions
+func newSuggestionError(msg string, suggestions []string, locs ...ast.Location) *Error {
+	if len(suggestions) != 0 {
+		// the hint starts a new sentence, so the message may end with a period already
+		msg = strings.TrimSuffix(msg, ".")
+	}
+	e := newValidationError(msg+didYouMean(suggestions), locs...)
+	if len(suggestions) != 0 {
+		e.Extensions = map[string]interface{}{
+			"suggestions": suggestions,
+		}
+	}
+	return e
+}
+
+// namesOf returns the keys of a map with string keys, like Fields or Arguments, as options for
+// the suggestions
+func namesOf(m interface{}) []string {
+	v := reflect.ValueOf(m)
+	names := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		names = append(names, k.String())
+	}
+	return names
+}
@@ -1,720 +1,605 @@
 package gql
 
 import (
-	"fmt"
-	"reflect"
-	"strings"
-
 	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/visitor"
 )
 
-func validate(ctx *gqlCtx) {
-	// TODO: parse type system definitions and return error that those are not supported in a query
-
-	ops := map[string]bool{}
-
-	for _, f := range ctx.doc.Fragments {
-		if _, ok := ctx.fragments[f.Name]; ok {
-			ctx.addErr(&Error{fmt.Sprintf("Fragment name '%s' is not unique, it's already used", f.Name), nil, nil, nil})
-			continue
-		}
-		if t, ok := ctx.types[f.TypeCondition]; !ok {
-			ctx.addErr(&Error{fmt.Sprintf("Invalid fragment target '%s' for fragment '%s', type does not exist", f.TypeCondition, f.Name), nil, nil, nil})
-			continue
-		} else {
-			if !isCompositeType(t) {
-				ctx.addErr(&Error{fmt.Sprintf("Invalid fragment target '%s' for fragment '%s', type is not composite", f.TypeCondition, f.Name), nil, nil, nil})
-				continue
-			}
-		}
-		// validateDirectives(ctx, o, f.Directives, FragmentDefinitionLoc)
-		ctx.fragments[f.Name] = f
-		ctx.fragmentUsage[f.Name] = false
-	}
+/*
+ValidationRule is a validation rule for the executable documents. The Visitor function is called
+once for every validated document, and the returned visitor is walked over the document together
+with the visitors of the other rules, in a single pass. The errors are reported on the context.
+*/
+type ValidationRule struct {
+	// Name of the rule, it can be used to find or remove the rule from a list
+	Name string
+	// Visitor returns the visitor of the rule for the validated document
+	Visitor func(ctx *ValidationContext) *visitor.Visitor
+}
 
-	for _, o := range ctx.doc.Operations {
-		// 5.2.1 - Named Operation Definitions
-		if _, ok := ops[o.Name]; ok && o.Name != "" {
-			ctx.addErr(&Error{fmt.Sprintf(errValidateOperationName, o.Name), nil, nil, nil})
-		} else {
-			ops[o.Name] = true
-		}
+/*
+SpecifiedRules are the validation rules from the spec, in the order they are run. They're
+used when no rules are set for the executor, a custom rule can be added like
+
+	rules := append([]*gql.ValidationRule{}, gql.SpecifiedRules...)
+	rules = append(rules, myRule)
+*/
+var SpecifiedRules = []*ValidationRule{
+	ExecutableDefinitionsRule,
+	UniqueOperationNamesRule,
+	LoneAnonymousOperationRule,
+	KnownOperationTypesRule,
+	SingleFieldSubscriptionsRule,
+	FieldsOnCorrectTypeRule,
+	OverlappingFieldsCanBeMergedRule,
+	ScalarLeafsRule,
+	KnownArgumentNamesRule,
+	UniqueArgumentNamesRule,
+	ProvidedRequiredArgumentsRule,
+	UniqueFragmentNamesRule,
+	FragmentsOnCompositeTypesRule,
+	NoUnusedFragmentsRule,
+	KnownFragmentNamesRule,
+	NoFragmentCyclesRule,
+	PossibleFragmentSpreadsRule,
+	ValuesOfCorrectTypeRule,
+	UniqueInputFieldNamesRule,
+	KnownDirectivesRule,
+	UniqueDirectivesPerLocationRule,
+	UniqueVariableNamesRule,
+	VariablesAreInputTypesRule,
+	NoUndefinedVariablesRule,
+	NoUnusedVariablesRule,
+	VariablesInAllowedPositionRule,
+}
 
-		// 5.2.2 - Anonymous Operation Definitions
-		if o.Name == "" && len(ctx.doc.Operations) > 1 {
-			ctx.addErr(&Error{fmt.Sprintf(errAnonymousOperationDefinitions), nil, nil, nil})
-		}
+/*
+Validate validates the document against the schema with the given rules, SpecifiedRules if the
+rules are nil, and returns the errors found in the document
+*/
+func Validate(schema *Schema, doc *ast.Document, rules []*ValidationRule) []*Error {
+	types, directives, implementors := getTypes(schema)
+	ctx := newValidationContext(schema, doc, types, directives, implementors)
+	return ctx.validate(rules)
+}
 
-		// validate varibles
-		ctx.variableDefs[o.Name] = validateVariables(ctx, o)
-		ctx.variableUsages[o.Name] = map[string]struct{}{}
+/*
+ValidateDocument validates the document against the schema with the SpecifiedRules, without
+executing it, so no variables or operation name are needed. It reports the errors of all the
+operations in the document at once, like a CI check of the queries of a client
 
-		// 5.3 - Fields
-		switch o.OperationType {
-		case ast.Query:
-			if ctx.schema.Query == nil {
-				ctx.addErr(&Error{fmt.Sprintf("No root query defined in schema"), nil, nil, nil})
-				break
-			}
-			validateDirectives(ctx, o, o.Directives, QueryLoc)
-			validateSelectionSet(ctx, o, o.SelectionSet, ctx.schema.Query, []string{})
-		case ast.Mutation:
-			if ctx.schema.Mutation == nil {
-				ctx.addErr(&Error{fmt.Sprintf("No root mutation defined in schema"), nil, nil, nil})
-				break
-			}
-			validateDirectives(ctx, o, o.Directives, MutationLoc)
-			validateSelectionSet(ctx, o, o.SelectionSet, ctx.schema.Mutation, []string{})
-		case ast.Subscription:
-			if ctx.schema.Subscription == nil {
-				ctx.addErr(&Error{fmt.Sprintf("No root subscription defined in schema"), nil, nil, nil})
-				break
-			}
-			if len(o.SelectionSet) != 1 {
-				ctx.addErr(&Error{fmt.Sprintf("Subscriptions must have only one root field in the selection set"), nil, nil, nil})
-				break
-			}
-			validateDirectives(ctx, o, o.Directives, SubscriptionLoc)
-			validateSelectionSet(ctx, o, o.SelectionSet, ctx.schema.Subscription, []string{})
-		}
-		for _, vDef := range ctx.variableDefs[o.Name] {
-			if _, ok := ctx.variableUsages[o.Name][vDef.Name]; !ok {
-				ctx.addErr(&Error{fmt.Sprintf("Variable defined but not used"), nil, nil, nil})
-			}
-		}
+	doc, err := parser.Parse(query)
+	if err != nil {
+		return err
 	}
-
-	for fragName, used := range ctx.fragmentUsage {
-		if !used {
-			ctx.addErr(&Error{fmt.Sprintf("fragment '%s' is not used", fragName), nil, nil, nil})
-		}
+	if errs := gql.ValidateDocument(schema, doc); len(errs) != 0 {
+		return gql.Errors(errs)
 	}
+*/
+func ValidateDocument(schema *Schema, doc *ast.Document) []*Error {
+	return Validate(schema, doc, nil)
 }
 
-func validateMetaField(ctx *gqlCtx, op *ast.Operation, f *ast.Field, t Type, visitedFrags []string) {
-	switch f.Name {
-	case "__typename":
-		{
-			// TODO: raise errors..
-		}
-	case "__schema":
-		{
-			if rq := ctx.schema.Query; rq != nil {
-				if rq == t {
-					validateSelectionSet(ctx, op, f.SelectionSet, schemaIntrospection, visitedFrags)
-				} else {
-					// TODO: raise errors
-				}
-			} else {
-				// TODO: raise errors
+// validate validates the document of the execution and collects the fragments for the execution
+func validate(ctx *gqlCtx, rules []*ValidationRule) {
+	if ctx.validationCache != nil {
+		if errs, ok := ctx.validationCache.get(ctx.schema, ctx.params.Query, ctx.disableIntrospection); ok {
+			for _, err := range errs {
+				ctx.addErr(err)
 			}
-		}
-	case "__type":
-		{
-			if rq := ctx.schema.Query; rq != nil {
-				if rq == t {
-					validateSelectionSet(ctx, op, f.SelectionSet, t, visitedFrags)
-				} else {
-					// TODO: raise errors
+			for _, f := range ctx.doc.Fragments {
+				if _, ok := ctx.fragments[f.Name]; !ok {
+					ctx.fragments[f.Name] = f
 				}
-			} else {
-				// TODO: raise errors
 			}
+			return
 		}
-	default:
-		{
-			ctx.addErr(&Error{fmt.Sprintf(errFieldDoesNotExist, f.Name, t.GetName()), nil, nil, nil})
-		}
+	}
+	vctx := newValidationContext(ctx.schema, ctx.doc, ctx.types, ctx.directives, ctx.implementors)
+	vctx.disableSuggestions = ctx.disableSuggestions
+	vctx.disableIntrospection = ctx.disableIntrospection
+	errs := vctx.validate(rules)
+	if ctx.validationCache != nil {
+		ctx.validationCache.add(ctx.schema, ctx.params.Query, ctx.disableIntrospection, errs)
+	}
+	for _, err := range errs {
+		ctx.addErr(err)
+	}
+	for name, f := range vctx.fragments {
+		ctx.fragments[name] = f
 	}
 }
 
-func fieldsInSetCanMerge(ctx *gqlCtx, set []ast.Selection, t Type) {
-	fieldsForName := collectFieldsForValidation(ctx, t, set, []string{})
-	for _, fields := range fieldsForName {
-		if len(fields) > 1 {
-			for i := 1; i < len(fields); i++ {
-				var pa, pb Type
-				pa = ctx.types[fields[0].ParentType]
-				pb = ctx.types[fields[i].ParentType]
-
-				if !sameResponseShape(ctx, fields[0], fields[i], pa, pb) {
-					ctx.addErr(&Error{fmt.Sprintf(errResponseShapeMismatch, "response shape is not the same"), nil, nil, nil})
-					continue
-				}
+/*
+ValidationContext is passed to the validation rules, it has the schema and the document that's being
+validated. During the walk, it also knows the types of the current node, like the parent type of the
+current field or the input type expected for the current value.
+*/
+type ValidationContext struct {
+	schema       *Schema
+	doc          *ast.Document
+	types        map[string]Type
+	directives   map[string]Directive
+	implementors map[string][]Type
+	fragments    map[string]*ast.Fragment
+	info         *typeInfo
+	errs         []*Error
+
+	disableSuggestions   bool
+	disableIntrospection bool
+
+	fragmentsOfOperation map[*ast.Operation][]*ast.Fragment
+	usagesOfOperation    map[*ast.Operation][]VariableUsage
+}
 
-				// this is bad, we should check the PARENT TYPE..
-				if reflect.DeepEqual(pa, pb) || (pa.GetKind() != ObjectKind || pb.GetKind() != ObjectKind) {
-					if fields[0].Name != fields[i].Name {
-						ctx.addErr(&Error{fmt.Sprintf(errResponseShapeMismatch, "field names are not equal"), nil, nil, nil})
-						continue
-					}
-
-					if !equalArguments(fields[0].Arguments, fields[i].Arguments) {
-						ctx.addErr(&Error{fmt.Sprintf(errResponseShapeMismatch, "arguments don't match"), nil, nil, nil})
-						continue
-					}
-					mergedSet := append(fields[0].SelectionSet, fields[i].SelectionSet...)
-					fieldsInSetCanMerge(ctx, mergedSet, t)
-				}
-			}
-		}
-	}
+// VariableUsage is a variable used in an operation, with the input type expected at its position
+// and the default value of the argument or input field at the position, nil if there is none
+type VariableUsage struct {
+	Node         *ast.VariableValue
+	Type         Type
+	DefaultValue interface{}
+	// ParentType is the input type that contains the position, like the input object of a field
+	ParentType Type
 }
 
-func equalArguments(a []*ast.Argument, b []*ast.Argument) bool {
-	if (a == nil && b != nil) || (a != nil && b == nil) {
-		return false
+func newValidationContext(schema *Schema, doc *ast.Document, types map[string]Type, directives map[string]Directive, implementors map[string][]Type) *ValidationContext {
+	ctx := &ValidationContext{
+		schema:               schema,
+		doc:                  doc,
+		types:                types,
+		directives:           directives,
+		implementors:         implementors,
+		fragments:            map[string]*ast.Fragment{},
+		fragmentsOfOperation: map[*ast.Operation][]*ast.Fragment{},
+		usagesOfOperation:    map[*ast.Operation][]VariableUsage{},
 	}
-	for _, fa := range a {
-		found := false
-		for _, fb := range b {
-			if fa.Name == fb.Name && equalValue(fa.Value, fb.Value) {
-				found = true
-				break
-			}
-		}
-		if !found {
-			return false
+	for _, f := range doc.Fragments {
+		if _, ok := ctx.fragments[f.Name]; !ok {
+			ctx.fragments[f.Name] = f
 		}
 	}
-	return true
+	ctx.info = newTypeInfo(ctx)
+	return ctx
 }
 
-func equalValue(a ast.Value, b ast.Value) bool {
-	if a.Kind() == b.Kind() {
-		switch a.Kind() {
-		case ast.VariableValueKind:
-			if a.GetValue() != b.GetValue() {
-				return false
-			}
-		default:
-			if a.GetValue() != b.GetValue() {
-				return false
-			}
-		}
-		return true
+func (c *ValidationContext) validate(rules []*ValidationRule) []*Error {
+	if rules == nil {
+		rules = SpecifiedRules
 	}
-	return false
+	if c.disableIntrospection {
+		rules = append(rules[:len(rules):len(rules)], noIntrospectionRule)
+	}
+	// the type info enters the nodes before and leaves them after the rules
+	visitors := make([]*visitor.Visitor, 0, len(rules)+2)
+	visitors = append(visitors, &visitor.Visitor{Enter: c.info.enter})
+	for _, r := range rules {
+		visitors = append(visitors, r.Visitor(c))
+	}
+	visitors = append(visitors, &visitor.Visitor{Leave: c.info.leave})
+	visitor.Walk(c.doc, visitors...)
+	return c.errs
 }
 
-func equalType(a Type, b Type) bool {
-	if a.GetName() != b.GetName() {
-		return false
+// Suggestions returns the options that are similar to the input for the "did you mean" hints
+// of the errors, at most five of them, or nil if the suggestions are disabled
+func (c *ValidationContext) Suggestions(input string, options []string) []string {
+	if c.disableSuggestions {
+		return nil
 	}
-	if wa, ok := a.(WrappingType); ok {
-		return equalType(wa.Unwrap(), b.(WrappingType).Unwrap())
+	s := suggestionList(input, options)
+	if len(s) > maxSuggestions {
+		s = s[:maxSuggestions]
 	}
-	return true
+	return s
 }
 
-func sameResponseShape(ctx *gqlCtx, fa *ast.Field, fb *ast.Field, pa Type, pb Type) bool {
-	var typeA, typeB Type
-	typeA = pa.(hasFields).GetFields()[fa.Name].Type
-	typeB = pb.(hasFields).GetFields()[fb.Name].Type
+// ReportError adds an error to the result of the validation, with the ErrValidationFailed code
+// if the error has no code yet
+func (c *ValidationContext) ReportError(err *Error) {
+	c.errs = append(c.errs, withCode(err, ErrValidationFailed))
+}
 
-	for {
-		if typeA.GetKind() == NonNullKind || typeB.GetKind() == NonNullKind {
-			if typeA.GetKind() != NonNullKind || typeB.GetKind() != NonNullKind {
-				return false
-			}
-			typeA = typeA.(*NonNull).Unwrap()
-			typeB = typeB.(*NonNull).Unwrap()
-		}
+// Errors returns the errors reported so far
+func (c *ValidationContext) Errors() []*Error {
+	return c.errs
+}
 
-		if typeA.GetKind() == ListKind || typeB.GetKind() == ListKind {
-			if typeA.GetKind() != ListKind || typeB.GetKind() != ListKind {
-				return false
-			}
-			typeA = typeA.(*List).Unwrap()
-			typeB = typeB.(*List).Unwrap()
-			continue
-		}
-		break
-	}
+// Schema that's used for the validation
+func (c *ValidationContext) Schema() *Schema {
+	return c.schema
+}
 
-	if typeA.GetKind() == ScalarKind || typeB.GetKind() == ScalarKind || typeA.GetKind() == EnumKind || typeB.GetKind() == EnumKind {
-		// TODO: Could try reflect.DeepEqual(typeA, typeB)
-		if typeA.GetName() == typeB.GetName() {
-			return true
-		}
-		return false
-	}
+// Document that's being validated
+func (c *ValidationContext) Document() *ast.Document {
+	return c.doc
+}
 
-	if !isCompositeType(typeA) || !isCompositeType(typeB) {
-		return false
-	}
+// Fragment returns the first fragment definition with the given name in the document, or nil
+func (c *ValidationContext) Fragment(name string) *ast.Fragment {
+	return c.fragments[name]
+}
 
-	mergedSet := append(fa.SelectionSet, fb.SelectionSet...)
-	fieldsForName := collectFields(ctx, typeA.(*Object), mergedSet, []string{})
-	for _, fields := range fieldsForName {
-		if len(fields) > 1 {
-			for i := 1; i < len(fields); i++ {
-				if !sameResponseShape(ctx, fields[0], fields[i], pa, pb) {
-					return false
-				}
-			}
-		}
-	}
-	return true
+// LookupType returns the type with the given name from the schema, or nil
+func (c *ValidationContext) LookupType(name string) Type {
+	return c.types[name]
 }
 
-func collectFieldsForValidation(ctx *gqlCtx, t Type, ss []ast.Selection, vFrags []string) map[string]ast.Fields {
-	if vFrags == nil {
-		vFrags = []string{}
+// LookupDirective returns the directive with the given name from the schema, or nil
+func (c *ValidationContext) LookupDirective(name string) Directive {
+	return c.directives[name]
+}
+
+// PossibleTypes returns the object types that can be the runtime type of the given type
+func (c *ValidationContext) PossibleTypes(t Type) []Type {
+	switch t.GetKind() {
+	case ObjectKind:
+		return []Type{t}
+	case InterfaceKind:
+		return c.implementors[t.GetName()]
+	case UnionKind:
+		return t.(*Union).GetMembers()
 	}
-	gfields := map[string]ast.Fields{}
+	return []Type{}
+}
 
-	for _, sel := range ss {
-		skip := false
-		for _, d := range sel.GetDirectives() {
-			if d.Name == "skip" {
-				skip = skipDirective.Skip(d.Arguments)
-			} else if d.Name == "include" {
-				skip = !includeDirective.Include(d.Arguments)
-			}
-		}
-		if skip {
-			continue
-		}
+// ParentType is the type of the selection set that contains the current field or fragment,
+// nil if it's not known
+func (c *ValidationContext) ParentType() Type {
+	return c.info.parentType()
+}
 
-		switch sel.Kind() {
-		case ast.FieldSelectionKind:
-			{
-				f := sel.(*ast.Field)
-				f.ParentType = t.GetName()
-				if _, ok := gfields[f.Alias]; ok {
-					gfields[f.Alias] = append(gfields[f.Alias], f)
-				} else {
-					gfields[f.Alias] = ast.Fields{f}
-				}
-			}
-		case ast.FragmentSpreadSelectionKind:
-			{
-				fSpread := sel.(*ast.FragmentSpread)
-				skip := false
-				for _, fragName := range vFrags {
-					if fSpread.Name == fragName {
-						skip = true
-					}
-				}
-				if skip {
-					continue
-				}
+// Type is the output type of the current field, or the type of the current operation or fragment
+func (c *ValidationContext) Type() Type {
+	return c.info.outputType()
+}
 
-				vFrags = append(vFrags, fSpread.Name)
+// FieldDef is the definition of the current field, nil if the field does not exist
+func (c *ValidationContext) FieldDef() *Field {
+	return c.info.fieldDef()
+}
 
-				fragment, ok := ctx.fragments[fSpread.Name]
-				if !ok {
-					continue
-				}
+// InputType is the input type expected for the current argument, variable or value
+func (c *ValidationContext) InputType() Type {
+	return c.info.inputType()
+}
 
-				fgfields := collectFieldsForValidation(ctx, ctx.types[fragment.TypeCondition], fragment.SelectionSet, vFrags)
-				for rkey, fg := range fgfields {
-					if _, ok := gfields[rkey]; ok {
-						gfields[rkey] = append(gfields[rkey], fg...)
-					} else {
-						gfields[rkey] = fg
-					}
-				}
-			}
-		case ast.InlineFragmentSelectionKind:
-			{
-				f := sel.(*ast.InlineFragment)
+// Directive is the definition of the current directive, nil if it's not defined
+func (c *ValidationContext) Directive() Directive {
+	return c.info.directive
+}
 
-				fragmentType := t
-				if f.TypeCondition != "" {
-					fragmentType = ctx.types[f.TypeCondition]
-				}
+// Argument is the definition of the current argument, nil if it's not defined
+func (c *ValidationContext) Argument() *Argument {
+	return c.info.argument
+}
 
-				fgfields := collectFieldsForValidation(ctx, fragmentType, f.SelectionSet, vFrags)
-				for rkey, fg := range fgfields {
-					if _, ok := gfields[rkey]; ok {
-						gfields[rkey] = append(gfields[rkey], fg...)
-					} else {
-						gfields[rkey] = fg
-					}
-				}
+// RecursivelyReferencedFragments returns the fragments used by the operation, directly or
+// by other fragments, in the order they are found
+func (c *ValidationContext) RecursivelyReferencedFragments(op *ast.Operation) []*ast.Fragment {
+	if fs, ok := c.fragmentsOfOperation[op]; ok {
+		return fs
+	}
+	fs := []*ast.Fragment{}
+	visited := map[string]bool{}
+	sets := [][]ast.Selection{op.SelectionSet}
+	for len(sets) > 0 {
+		set := sets[0]
+		sets = sets[1:]
+		for _, spread := range fragmentSpreads(set) {
+			if visited[spread.Name] {
+				continue
+			}
+			visited[spread.Name] = true
+			if f, ok := c.fragments[spread.Name]; ok {
+				fs = append(fs, f)
+				sets = append(sets, f.SelectionSet)
 			}
 		}
 	}
-	return gfields
+	c.fragmentsOfOperation[op] = fs
+	return fs
 }
 
-func isCompositeType(t Type) bool {
-	if t.GetKind() == ObjectKind || t.GetKind() == InterfaceKind || t.GetKind() == UnionKind {
-		return true
+// RecursiveVariableUsages returns the variables used in the operation and in the fragments
+// used by it
+func (c *ValidationContext) RecursiveVariableUsages(op *ast.Operation) []VariableUsage {
+	if us, ok := c.usagesOfOperation[op]; ok {
+		return us
 	}
-	return false
-}
-
-func validateSelectionSet(ctx *gqlCtx, op *ast.Operation, set []ast.Selection, t Type, visitedFrags []string) {
-	fieldsInSetCanMerge(ctx, set, t)
-
-	if len(set) == 0 {
-		ctx.addErr(&Error{fmt.Sprintf(errLeafFieldSelectionsSelectionMissing, t.GetName()), nil, nil, nil})
-		return
+	us := []VariableUsage{}
+	info := newTypeInfo(c)
+	collect := &visitor.Visitor{
+		Kinds: map[visitor.Kind]visitor.Funcs{
+			visitor.VariableValueKind: {
+				Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+					us = append(us, VariableUsage{
+						Node:         node.(*ast.VariableValue),
+						Type:         info.inputType(),
+						DefaultValue: info.defaultValue(),
+						ParentType:   info.parentInputType(),
+					})
+					return visitor.Continue
+				},
+			},
+			// the default values can't have variables
+			visitor.VariableKind: {
+				Enter: func(interface{}, *visitor.Info) visitor.Action {
+					return visitor.Skip
+				},
+			},
+		},
+	}
+	visitor.Walk(op, &visitor.Visitor{Enter: info.enter}, collect, &visitor.Visitor{Leave: info.leave})
+	for _, f := range c.RecursivelyReferencedFragments(op) {
+		visitor.Walk(f, &visitor.Visitor{Enter: info.enter}, collect, &visitor.Visitor{Leave: info.leave})
 	}
+	c.usagesOfOperation[op] = us
+	return us
+}
 
+// fragmentSpreads returns the fragment spreads in the selection set, including the ones in the
+// nested selection sets
+func fragmentSpreads(set []ast.Selection) []*ast.FragmentSpread {
+	spreads := []*ast.FragmentSpread{}
 	for _, s := range set {
-		if s.Kind() == ast.FieldSelectionKind {
-			f := s.(*ast.Field)
-			t := t
-
-			// check if it's a meta field then check if that meta field can be queried on the specific type or does it even exists
-			if strings.HasPrefix(f.Name, "__") {
-				if f.Name == "__typename" {
-					validateMetaField(ctx, op, f, t, visitedFrags)
-					continue
-				} else if rq := ctx.schema.Query; rq != nil {
-					if rq == t {
-						t = introspectionQuery
-					} else {
-						// TODO: INVALID META FIELD, FIELD DOES NOT EXIST ON TYPE
-					}
-				}
-			}
-
-			// check if the type 't' is an Object
-			if o, ok := t.(*Object); ok {
-				if tf, ok := o.Fields[f.Name]; ok {
-					// 5.3.3 - Leaf Field Selections
-					selType := unwrapper(tf.GetType())
-
-					validateArguments(ctx, op, f.Arguments, tf.Arguments)
-					validateDirectives(ctx, op, f.Directives, FieldLoc)
-
-					if isCompositeType(selType) {
-						validateSelectionSet(ctx, op, f.SelectionSet, selType, visitedFrags)
-					} else if !isCompositeType(selType) {
-						if len(f.SelectionSet) != 0 {
-							ctx.addErr(&Error{fmt.Sprintf(errLeafFieldSelectionsSelectionNotAllowed, selType.GetName()), nil, nil, nil})
-						}
-					}
-				} else {
-					// 5.3.1 - Field Selections on Objects, Interfaces, and Unions Types
-					// if field does NOT exist on type
-					ctx.addErr(&Error{fmt.Sprintf(errFieldDoesNotExist, f.Name, t.GetName()), nil, nil, nil})
-					continue
-				}
-			} else if i, ok := t.(*Interface); ok {
-				if tf, ok := i.Fields[f.Name]; ok {
-					validateArguments(ctx, op, f.Arguments, tf.Arguments)
-					validateDirectives(ctx, op, f.Directives, FieldLoc)
-
-					// 5.3.3 - Leaf Field Selections
-					selType := unwrapper(tf.GetType())
-					if isCompositeType(selType) {
-						validateSelectionSet(ctx, op, f.SelectionSet, selType, visitedFrags)
-					} else if !isCompositeType(selType) {
-						if len(f.SelectionSet) != 0 {
-							ctx.addErr(&Error{fmt.Sprintf(errLeafFieldSelectionsSelectionNotAllowed, selType.GetName()), nil, nil, nil})
-						}
-					}
-
-				} else {
-					// 5.3.1 - Field Selections on Objects, Interfaces, and Unions Types
-					// if field does NOT exist on type
-					ctx.addErr(&Error{fmt.Sprintf(errFieldDoesNotExist, f.Name, t.GetName()), nil, nil, nil})
-					continue
-				}
-			} else {
-				ctx.addErr(&Error{fmt.Sprintf("Invalid field selection on type '%s'", t.GetName()), nil, nil, nil})
-				continue
-			}
-		} else if s.Kind() == ast.FragmentSpreadSelectionKind {
-			f := s.(*ast.FragmentSpread)
-			cycle := false
-			for _, v := range visitedFrags {
-				if v == f.Name {
-					ctx.addErr(&Error{fmt.Sprintf("fragment cycle detected for fragment '%s'", f.Name), nil, nil, nil})
-					cycle = true
-				}
-			}
-			if cycle {
-				continue
-			}
+		switch s := s.(type) {
+		case *ast.Field:
+			spreads = append(spreads, fragmentSpreads(s.SelectionSet)...)
+		case *ast.FragmentSpread:
+			spreads = append(spreads, s)
+		case *ast.InlineFragment:
+			spreads = append(spreads, fragmentSpreads(s.SelectionSet)...)
+		}
+	}
+	return spreads
+}
 
-			fDef, ok := ctx.fragments[f.Name]
-			if !ok {
-				ctx.addErr(&Error{fmt.Sprintf("fragment '%s' is not defined in query", f.Name), nil, nil, nil})
-				continue
-			}
-			tCond, ok := ctx.types[fDef.TypeCondition]
-			if !ok {
-				ctx.addErr(&Error{fmt.Sprintf("fragment's (%s) target type (%s) is not defined in query", f.Name, fDef.TypeCondition), nil, nil, nil})
-				continue
-			}
-			if !isPossibleSpread(ctx, t, tCond) {
-				ctx.addErr(&Error{fmt.Sprintf("cannot use '%s' spead on type '%s'", f.Name, t.GetName()), nil, nil, nil})
-				continue
-			}
+/*
+typeInfo tracks the types of the current node during the walk, its enter function has to be called
+before and its leave function after the visitors that are using it. A nil type on the stacks means
+that the type is not known, because the document is invalid.
+*/
+type typeInfo struct {
+	ctx         *ValidationContext
+	parentTypes []Type
+	types       []Type
+	fieldDefs   []*Field
+	inputTypes  []Type
+	// defaultValues are the default values of the arguments and input fields, next to their input types
+	defaultValues []interface{}
+	directive     Directive
+	inDirective   bool
+	argument      *Argument
+}
 
-			validateDirectives(ctx, op, f.Directives, FragmentSpreadLoc)
-			ctx.fragmentUsage[f.Name] = true
-			validateSelectionSet(ctx, op, fDef.SelectionSet, tCond, append(visitedFrags, f.Name))
-		} else if s.Kind() == ast.InlineFragmentSelectionKind {
-			fDef := s.(*ast.InlineFragment)
-			tCond, ok := ctx.types[fDef.TypeCondition]
-			if !ok && fDef.TypeCondition != "" {
-				ctx.addErr(&Error{fmt.Sprintf("fragment's target type (%s) is not defined in query", fDef.TypeCondition), nil, nil, nil})
-				continue
-			} else if fDef.TypeCondition == "" {
-				tCond = t
-			}
-			if !isPossibleSpread(ctx, t, tCond) {
-				ctx.addErr(&Error{fmt.Sprintf("invalid use of inline fragment on type '%s': target does not match", t.GetName()), nil, nil, nil})
-				continue
-			}
+func newTypeInfo(ctx *ValidationContext) *typeInfo {
+	return &typeInfo{ctx: ctx}
+}
 
-			validateDirectives(ctx, op, fDef.Directives, InlineFragmentLoc)
-			validateSelectionSet(ctx, op, fDef.SelectionSet, tCond, visitedFrags)
-		}
+func (ti *typeInfo) parentType() Type {
+	if len(ti.parentTypes) == 0 {
+		return nil
 	}
+	return ti.parentTypes[len(ti.parentTypes)-1]
 }
 
-func isPossibleSpread(ctx *gqlCtx, parentType Type, fragType Type) bool {
-	pts := getPossibleTypes(ctx, parentType)
-	fts := getPossibleTypes(ctx, fragType)
-	for _, t := range pts {
-		for _, ft := range fts {
-			if t == ft {
-				return true
-			}
-		}
+func (ti *typeInfo) outputType() Type {
+	if len(ti.types) == 0 {
+		return nil
 	}
-	return false
+	return ti.types[len(ti.types)-1]
 }
 
-func getPossibleTypes(ctx *gqlCtx, t Type) []Type {
-	switch t.GetKind() {
-	case ObjectKind:
-		return []Type{t}
-	case InterfaceKind:
-		return ctx.implementors[t.GetName()]
-	case UnionKind:
-		return t.(*Union).GetMembers()
+// namedType returns the named type of the current output type, the type of the selection set
+func (ti *typeInfo) namedType() Type {
+	if t := ti.outputType(); t != nil {
+		return unwrapper(t)
 	}
-	return []Type{}
+	return nil
 }
 
-func validateArguments(ctx *gqlCtx, op *ast.Operation, astArgs []*ast.Argument, args Arguments) {
-	visitesArgs := map[string]*ast.Argument{}
-	for _, a := range astArgs {
-		if ta, ok := args[a.Name]; ok {
-			if _, visited := visitesArgs[a.Name]; visited {
-				ctx.addErr(&Error{fmt.Sprintf("argument '%s' is set multiple times", a.Name), nil, nil, nil})
-			} else {
-				if ta.IsDefaultValueSet() && ta.Type.GetKind() == NonNullKind {
+func (ti *typeInfo) fieldDef() *Field {
+	if len(ti.fieldDefs) == 0 {
+		return nil
+	}
+	return ti.fieldDefs[len(ti.fieldDefs)-1]
+}
 
-				}
-				// TODO: don't raise error when arg is NonNull, has default value and the provided value is null
-				validateValue(ctx, op, ta.Type, a.Value)
-				visitesArgs[a.Name] = a
-			}
-		} else {
-			ctx.addErr(&Error{fmt.Sprintf("argument '%s' is not defined", a.Name), nil, nil, nil})
-		}
+func (ti *typeInfo) inputType() Type {
+	if len(ti.inputTypes) == 0 {
+		return nil
 	}
+	return ti.inputTypes[len(ti.inputTypes)-1]
+}
 
-	for aName, a := range args {
-		if a.Type.GetKind() == NonNullKind && !a.IsDefaultValueSet() {
-			if astArg, ok := visitesArgs[aName]; ok {
-				if astArg.Value.Kind() == ast.NullValueKind {
-					ctx.addErr(&Error{fmt.Sprintf("argument '%s' is NonNull and the provided value is null", aName), nil, nil, nil})
-				}
-			} else {
-				ctx.addErr(&Error{fmt.Sprintf("argument '%s' is required (NonNull) but not provided", aName), nil, nil, nil})
-			}
-		}
+// parentInputType returns the input type that contains the current input type, like the input
+// object of a field, or nil if there's none
+func (ti *typeInfo) parentInputType() Type {
+	if len(ti.inputTypes) < 2 {
+		return nil
+	}
+	if t := ti.inputTypes[len(ti.inputTypes)-2]; t != nil {
+		return unwrapNonNull(t)
 	}
+	return nil
 }
 
-func validateDirectives(ctx *gqlCtx, op *ast.Operation, ds []*ast.Directive, loc DirectiveLocation) {
-	visited := map[string]bool{}
-	for _, d := range ds {
-		if def, ok := ctx.directives[d.Name]; ok {
-			ok = false
-			for _, l := range def.GetLocations() {
-				if l == loc {
-					ok = true
-				}
-			}
-			if !ok {
-				// DIRECTIVE IS ON INVALID LOCATION
-				ctx.addErr(&Error{fmt.Sprintf("directive '%s' is on invalid location", d.Name), nil, nil, nil})
-				continue
-			}
-			if _, ok = visited[d.Name]; ok {
-				// DIRECTIVE IS NOT UNIQUE PER LOCATION
-				ctx.addErr(&Error{fmt.Sprintf("directive '%s' is not unique per location", d.Name), nil, nil, nil})
-				continue
-			}
-			validateArguments(ctx, op, d.Arguments, def.GetArguments())
-			visited[d.Name] = true
-		} else {
-			// NOT DEFINED
-			ctx.addErr(&Error{fmt.Sprintf("directive '%s' is not defined", d.Name), nil, nil, nil})
-		}
+func (ti *typeInfo) defaultValue() interface{} {
+	if len(ti.defaultValues) == 0 {
+		return nil
 	}
+	return ti.defaultValues[len(ti.defaultValues)-1]
 }
 
-func validateValue(ctx *gqlCtx, op *ast.Operation, t Type, val ast.Value) {
-	switch {
-	case val.Kind() == ast.VariableValueKind:
-		vv := val.(*ast.VariableValue)
-		for _, vDef := range op.Variables {
-			if vDef.Name == vv.Name {
-				ctx.variableUsages[op.Name][vv.Name] = struct{}{}
-				vdefType, err := resolveAstType(ctx.types, vDef.Type)
-				if err != nil {
-					return
-				}
+func (ti *typeInfo) pushInputType(t Type, defaultValue interface{}) {
+	ti.inputTypes = append(ti.inputTypes, t)
+	ti.defaultValues = append(ti.defaultValues, defaultValue)
+}
 
-				if equalType(vdefType, t) {
-					return
-				} else if t.GetKind() == NonNullKind {
-					if equalType(t.(*NonNull).Unwrap(), vdefType) && vDef.DefaultValue != nil && vDef.DefaultValue.Kind() != ast.NullValueKind {
-						return
-					}
-				}
-				ctx.addErr(&Error{fmt.Sprintf("variable '%s' is not allowed to use", vv.Name), []*ErrorLocation{{Line: vv.Location.Line, Column: vv.Location.Column}}, nil, nil})
-				return
-			}
-		}
-		ctx.addErr(&Error{fmt.Sprintf("variable '%s' is defined", vv.Name), []*ErrorLocation{{Line: vv.Location.Line, Column: vv.Location.Column}}, nil, nil})
-		return
-	case t.GetKind() == NonNullKind:
-		if vv, ok := val.(*ast.NullValue); ok {
-			ctx.addErr(&Error{fmt.Sprintf("null value provided for NonNull type"), []*ErrorLocation{{Line: vv.Location.Line, Column: vv.Location.Column}}, nil, nil})
-			return
-		}
-		validateValue(ctx, op, t.(*NonNull).Unwrap(), val)
-		return
-	case val.Kind() == ast.NullValueKind:
-		return
-	case t.GetKind() == ListKind:
-		lv := val.(*ast.ListValue)
-		for i := 0; i < len(lv.Values); i++ {
-			validateValue(ctx, op, t.(*List).Unwrap(), lv.Values[i])
-		}
-		return
-	case t.GetKind() == ScalarKind:
-		var err error
-		if raw, ok := val.(ast.Value); ok {
-			if err = t.(*Scalar).AstValidator(val); err != nil {
-				ctx.addErr(&Error{err.Error(), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-			} else {
-				_, err = t.(*Scalar).CoerceInputFunc(raw.GetValue())
-			}
+func (ti *typeInfo) popInputType() {
+	ti.inputTypes = ti.inputTypes[:len(ti.inputTypes)-1]
+	ti.defaultValues = ti.defaultValues[:len(ti.defaultValues)-1]
+}
+
+func (ti *typeInfo) enter(node interface{}, _ *visitor.Info) visitor.Action {
+	switch node := node.(type) {
+	case *ast.Operation:
+		var t Type
+		switch node.OperationType {
+		case ast.Query:
+			t = ti.ctx.schema.Query
+		case ast.Mutation:
+			t = ti.ctx.schema.Mutation
+		case ast.Subscription:
+			t = ti.ctx.schema.Subscription
+		}
+		if o, ok := t.(*Object); !ok || o == nil {
+			t = nil
+		}
+		ti.types = append(ti.types, t)
+	case *ast.Fragment:
+		ti.types = append(ti.types, ti.ctx.types[node.TypeCondition])
+	case *ast.InlineFragment:
+		parent := ti.namedType()
+		t := parent
+		if node.TypeCondition != "" {
+			t = ti.ctx.types[node.TypeCondition]
+		}
+		ti.parentTypes = append(ti.parentTypes, parent)
+		ti.types = append(ti.types, t)
+	case *ast.FragmentSpread:
+		ti.parentTypes = append(ti.parentTypes, ti.namedType())
+	case *ast.Field:
+		parent := ti.namedType()
+		fd := ti.lookupField(parent, node.Name)
+		var t Type
+		if fd != nil {
+			t = fd.Type
+		}
+		ti.parentTypes = append(ti.parentTypes, parent)
+		ti.fieldDefs = append(ti.fieldDefs, fd)
+		ti.types = append(ti.types, t)
+	case *ast.Directive:
+		ti.directive = ti.ctx.directives[node.Name]
+		ti.inDirective = true
+	case *ast.Argument:
+		var args Arguments
+		if ti.inDirective {
+			if ti.directive != nil {
+				args = ti.directive.GetArguments()
+			}
+		} else if fd := ti.fieldDef(); fd != nil {
+			args = fd.Arguments
+		}
+		ti.argument = args[node.Name]
+		if ti.argument != nil {
+			ti.pushInputType(ti.argument.Type, ti.argument.DefaultValue)
 		} else {
-			_, err = t.(*Scalar).CoerceInputFunc(val)
+			ti.pushInputType(nil, nil)
 		}
-		if err != nil {
-			ctx.addErr(&Error{err.Error(), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
+	case *ast.Variable:
+		t, err := resolveAstType(ti.ctx.types, node.Type)
+		if err != nil || !isInputType(t) {
+			t = nil
 		}
-		return
-	case t.GetKind() == EnumKind:
-		if val.Kind() != ast.EnumValueKind {
-			ctx.addErr(&Error{fmt.Sprintf("invalid value for Enum"), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
+		ti.pushInputType(t, nil)
+	case *ast.ListValue:
+		var t Type
+		if it := ti.inputType(); it != nil {
+			if nn, ok := it.(*NonNull); ok {
+				it = nn.Unwrap()
+			}
+			if l, ok := it.(*List); ok {
+				t = l.Unwrap()
+			}
 		}
-		e := t.(*Enum)
-		if v, ok := val.GetValue().(string); ok {
-			for _, ev := range e.Values {
-				if ev.Name == v {
-					return
-				}
+		ti.pushInputType(t, nil)
+	case *ast.ObjectFieldValue:
+		var f *InputField
+		if it := ti.inputType(); it != nil {
+			if o, ok := unwrapper(it).(*InputObject); ok {
+				f = o.Fields[node.Name]
 			}
-			ctx.addErr(&Error{fmt.Sprintf("invalid enum value '%s'", v), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-			return
 		}
-		ctx.addErr(&Error{fmt.Sprintf("invalid value for Enum"), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-		return
-	case t.GetKind() == InputObjectKind:
-		ov, ok := val.(*ast.ObjectValue)
-		if !ok {
-			ctx.addErr(&Error{fmt.Sprintf("invalid value for InputObject"), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-			return
+		if f != nil {
+			ti.pushInputType(f.Type, f.DefaultValue)
+		} else {
+			ti.pushInputType(nil, nil)
 		}
-		o := t.(*InputObject)
-		visitedFields := map[string]struct{}{}
-		for _, astf := range ov.Fields {
-			field, ok := o.Fields[astf.Name]
-			if !ok {
-				ctx.addErr(&Error{fmt.Sprintf("field '%s' is not defined", astf.Name), []*ErrorLocation{{Line: astf.GetLocation().Line, Column: astf.GetLocation().Column}}, nil, nil})
-				continue
-			}
+	}
+	return visitor.Continue
+}
 
-			if _, ok := visitedFields[astf.Name]; ok {
-				ctx.addErr(&Error{fmt.Sprintf("field '%s' was set multiple times", astf.Name), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-				continue
-			}
-			visitedFields[astf.Name] = struct{}{}
+func (ti *typeInfo) leave(node interface{}, _ *visitor.Info) visitor.Action {
+	switch node.(type) {
+	case *ast.Operation, *ast.Fragment:
+		ti.types = ti.types[:len(ti.types)-1]
+	case *ast.InlineFragment:
+		ti.parentTypes = ti.parentTypes[:len(ti.parentTypes)-1]
+		ti.types = ti.types[:len(ti.types)-1]
+	case *ast.FragmentSpread:
+		ti.parentTypes = ti.parentTypes[:len(ti.parentTypes)-1]
+	case *ast.Field:
+		ti.parentTypes = ti.parentTypes[:len(ti.parentTypes)-1]
+		ti.fieldDefs = ti.fieldDefs[:len(ti.fieldDefs)-1]
+		ti.types = ti.types[:len(ti.types)-1]
+	case *ast.Directive:
+		ti.directive = nil
+		ti.inDirective = false
+	case *ast.Argument:
+		ti.argument = nil
+		ti.popInputType()
+	case *ast.Variable, *ast.ListValue, *ast.ObjectFieldValue:
+		ti.popInputType()
+	}
+	return visitor.Continue
+}
 
-			if astf.Value.Kind() == ast.NullValueKind && field.Type.GetKind() != NonNullKind {
-				continue
-			}
-			if astf.Value.Kind() != ast.VariableValueKind {
-				validateValue(ctx, op, field.Type, astf.Value)
-				continue
-			}
-			if astf.Value.Kind() == ast.VariableValueKind {
-				vv := astf.Value.(*ast.VariableValue)
-				ok := false
-				for _, vDef := range op.Variables {
-					if vDef.Name == vv.Name {
-						ctx.variableUsages[op.Name][vv.Name] = struct{}{}
-						vdefType, err := resolveAstType(ctx.types, vDef.Type)
-						if err != nil {
-							ctx.addErr(err)
-							ok = true
-							break
-						}
-						if equalType(vdefType, field.Type) {
-							ok = true
-							break
-						}
-						ctx.addErr(&Error{fmt.Sprintf("invalid variable type for field '%s'", astf.Name), []*ErrorLocation{{Line: val.GetLocation().Line, Column: val.GetLocation().Column}}, nil, nil})
-						ok = true
-						break
-					}
-				}
-				if !ok {
-					ctx.addErr(&Error{fmt.Sprintf("variable '%s' is defined", vv.Name), []*ErrorLocation{{Line: vv.Location.Line, Column: vv.Location.Column}}, nil, nil})
-				}
-			}
+// lookupField returns the definition of the field on the parent type, including the meta fields
+func (ti *typeInfo) lookupField(parent Type, name string) *Field {
+	if parent == nil {
+		return nil
+	}
+	switch name {
+	case "__typename":
+		if isCompositeType(parent) {
+			return typenameField
 		}
-
-		for fn, field := range o.Fields {
-			if _, ok := visitedFields[fn]; !ok {
-				if !field.IsDefaultValueSet() && field.Type.GetKind() == NonNullKind {
-					ctx.addErr(&Error{fmt.Sprintf("no value provided for field '%s' with NonNull type", fn), []*ErrorLocation{{Line: ov.Location.Line, Column: ov.Location.Column}}, nil, nil})
-				}
-			}
+	case "__schema", "__type":
+		if q := ti.ctx.schema.Query; q != nil && parent == Type(q) {
+			return introspectionQuery.Fields[name]
 		}
 	}
+	if hf, ok := parent.(hasFields); ok {
+		return hf.GetFields()[name]
+	}
+	return nil
 }
 
-func validateVariables(ctx *gqlCtx, op *ast.Operation) map[string]*ast.Variable {
-	visited := map[string]*ast.Variable{}
-	for _, v := range op.Variables {
-		// variable has to be unique
-		if _, ok := visited[v.Name]; ok {
-			ctx.addErr(&Error{fmt.Sprintf("variable '%s' is used multiple times", v.Name), nil, nil, nil})
-			continue
-		}
+// typenameField is the definition of the __typename meta field, that's available on all
+// the composite types
+var typenameField = &Field{
+	Type: NewNonNull(String),
+}
 
-		// variable has to be input type
-		if vt, err := resolveAstType(ctx.types, v.Type); err != nil {
-			ctx.addErr(err)
-			continue
-		} else if !isInputType(vt) {
-			ctx.addErr(&Error{fmt.Sprintf("variable '%s' is not an input type", v.Name), nil, nil, nil})
-			continue
-		} else if v.DefaultValue != nil {
-			// default value has to be validated
-			validateValue(ctx, op, vt, v.DefaultValue)
-		}
-		visited[v.Name] = v
+func isCompositeType(t Type) bool {
+	if t.GetKind() == ObjectKind || t.GetKind() == InterfaceKind || t.GetKind() == UnionKind {
+		return true
+	}
+	return false
+}
+
+// newValidationError returns an Error pointing to the given locations in the document
+func newValidationError(msg string, locs ...ast.Location) *Error {
+	e := &Error{
+		Message:   msg,
+		Locations: make([]*ErrorLocation, 0, len(locs)),
+	}
+	for _, loc := range locs {
+		e.Locations = append(e.Locations, &ErrorLocation{
+			Line:   loc.Line,
+			Column: loc.Column,
+		})
 	}
-	return visited
+	return e
 }
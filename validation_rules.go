@@ -0,0 +1,1247 @@
+package gql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/visitor"
+)
+
+// ExecutableDefinitionsRule checks that the document has only operations and fragments (5.1.1)
+var ExecutableDefinitionsRule = &ValidationRule{
+	Name: "ExecutableDefinitions",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.DocumentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						for _, def := range node.(*ast.Document).Definitions {
+							var (
+								name string
+								loc  ast.Location
+							)
+							switch def := def.(type) {
+							case *ast.SchemaDefinition:
+								name, loc = "schema", def.Location
+							case *ast.SchemaExtension:
+								name, loc = "schema", def.Location
+							case *ast.ScalarDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.ObjectDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.InterfaceDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.UnionDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.EnumDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.InputObjectDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.DirectiveDefinition:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.ScalarExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.ObjectExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.InterfaceExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.UnionExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.EnumExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							case *ast.InputObjectExtension:
+								name, loc = "'"+def.Name+"'", def.Location
+							}
+							ctx.ReportError(newValidationError(fmt.Sprintf("The %s definition is not executable", name), loc))
+						}
+						return visitor.Skip
+					},
+				},
+			},
+		}
+	},
+}
+
+// UniqueOperationNamesRule checks that the names of the operations are unique (5.2.1.1)
+var UniqueOperationNamesRule = &ValidationRule{
+	Name: "UniqueOperationNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		operations := map[string]*ast.Operation{}
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						if o.Name == "" {
+							return visitor.Continue
+						}
+						if first, ok := operations[o.Name]; ok {
+							ctx.ReportError(newValidationError(fmt.Sprintf(errValidateOperationName, o.Name), first.Location, o.Location))
+							return visitor.Continue
+						}
+						operations[o.Name] = o
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// LoneAnonymousOperationRule checks that an anonymous operation is the only operation in the document (5.2.2.1)
+var LoneAnonymousOperationRule = &ValidationRule{
+	Name: "LoneAnonymousOperation",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						if o.Name == "" && len(ctx.Document().Operations) > 1 {
+							ctx.ReportError(newValidationError(errAnonymousOperationDefinitions, o.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// KnownOperationTypesRule checks that the schema has a root type for the operations
+var KnownOperationTypesRule = &ValidationRule{
+	Name: "KnownOperationTypes",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						if ctx.Type() == nil {
+							ctx.ReportError(newValidationError(fmt.Sprintf("No root %s defined in schema", o.OperationType), o.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// SingleFieldSubscriptionsRule checks that the subscriptions have exactly one root field, including
+// the fields of the fragments, which is not an introspection field (5.2.3.1)
+var SingleFieldSubscriptionsRule = &ValidationRule{
+	Name: "SingleFieldSubscriptions",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						if o.OperationType != ast.Subscription {
+							return visitor.Skip
+						}
+						keys, groups := groupByResponseKey(collectFieldsForValidation(ctx, ctx.Type(), o.SelectionSet, map[string]bool{}))
+						if len(keys) != 1 {
+							ctx.ReportError(newValidationError("Subscriptions must have only one root field in the selection set", o.Location))
+						}
+						for _, key := range keys {
+							for _, f := range groups[key] {
+								if strings.HasPrefix(f.field.Name, "__") {
+									ctx.ReportError(newValidationError(fmt.Sprintf("Subscriptions can not have the introspection field '%s' as root field", f.field.Name), f.field.Location))
+								}
+							}
+						}
+						return visitor.Skip
+					},
+				},
+			},
+		}
+	},
+}
+
+// FieldsOnCorrectTypeRule checks that the selected fields are defined on their parent types (5.3.1)
+var FieldsOnCorrectTypeRule = &ValidationRule{
+	Name: "FieldsOnCorrectType",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.Field)
+						parent := ctx.ParentType()
+						if parent == nil || ctx.FieldDef() != nil {
+							return visitor.Continue
+						}
+						msg := fmt.Sprintf("Invalid field selection on type '%s'", parent.GetName())
+						if _, ok := parent.(hasFields); ok {
+							msg = fmt.Sprintf(errFieldDoesNotExist, f.Name, parent.GetName())
+						}
+						// on abstract types, the field may be selected with an inline fragment
+						if types := suggestedTypesForField(ctx, parent, f.Name); len(types) != 0 {
+							err := newValidationError(msg+". Did you mean to use an inline fragment on "+quotedOrList(types)+"?", f.Location)
+							err.Extensions = map[string]interface{}{
+								"suggestions": types,
+							}
+							ctx.ReportError(err)
+							return visitor.Continue
+						}
+						var suggestions []string
+						if hf, ok := parent.(hasFields); ok {
+							suggestions = ctx.Suggestions(f.Name, namesOf(hf.GetFields()))
+						}
+						ctx.ReportError(newSuggestionError(msg, suggestions, f.Location))
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// OverlappingFieldsCanBeMergedRule checks that the fields with the same response name can be merged (5.3.2)
+var OverlappingFieldsCanBeMergedRule = &ValidationRule{
+	Name: "OverlappingFieldsCanBeMerged",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		m := &fieldsMerger{
+			ctx:      ctx,
+			fields:   map[selectionSetKey][]fieldAndParent{},
+			compared: map[fieldPairKey]string{},
+		}
+		return &visitor.Visitor{
+			Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+				var set []ast.Selection
+				switch node := node.(type) {
+				case *ast.Operation:
+					set = node.SelectionSet
+				case *ast.Fragment:
+					set = node.SelectionSet
+				case *ast.InlineFragment:
+					set = node.SelectionSet
+				case *ast.Field:
+					set = node.SelectionSet
+				default:
+					return visitor.Continue
+				}
+				if t := ctx.Type(); t != nil && len(set) != 0 && isCompositeType(unwrapper(t)) {
+					m.fieldsInSetCanMerge(set, unwrapper(t))
+				}
+				return visitor.Continue
+			},
+		}
+	},
+}
+
+// ScalarLeafsRule checks that the leaf fields have no selections and the others do (5.3.3)
+var ScalarLeafsRule = &ValidationRule{
+	Name: "ScalarLeafs",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.Field)
+						t := ctx.Type()
+						if t == nil {
+							return visitor.Continue
+						}
+						t = unwrapper(t)
+						if isCompositeType(t) && len(f.SelectionSet) == 0 {
+							ctx.ReportError(newValidationError(fmt.Sprintf(errLeafFieldSelectionsSelectionMissing, t.GetName()), f.Location))
+						} else if !isCompositeType(t) && len(f.SelectionSet) != 0 {
+							ctx.ReportError(newValidationError(fmt.Sprintf(errLeafFieldSelectionsSelectionNotAllowed, t.GetName()), f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// KnownArgumentNamesRule checks that the arguments are defined on the fields and directives (5.4.1)
+var KnownArgumentNamesRule = &ValidationRule{
+	Name: "KnownArgumentNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.ArgumentKind: {
+					Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+						a := node.(*ast.Argument)
+						if ctx.Argument() != nil {
+							return visitor.Continue
+						}
+						var args Arguments
+						switch info.Parent.(type) {
+						case *ast.Field:
+							if ctx.FieldDef() == nil {
+								return visitor.Continue
+							}
+							args = ctx.FieldDef().Arguments
+						case *ast.Directive:
+							if ctx.Directive() == nil {
+								return visitor.Continue
+							}
+							args = ctx.Directive().GetArguments()
+						}
+						suggestions := ctx.Suggestions(a.Name, namesOf(args))
+						ctx.ReportError(newSuggestionError(fmt.Sprintf("argument '%s' is not defined", a.Name), suggestions, a.Location))
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// UniqueArgumentNamesRule checks that an argument is not set multiple times (5.4.2)
+var UniqueArgumentNamesRule = &ValidationRule{
+	Name: "UniqueArgumentNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		check := func(args []*ast.Argument) {
+			seen := map[string]*ast.Argument{}
+			for _, a := range args {
+				if first, ok := seen[a.Name]; ok {
+					ctx.ReportError(newValidationError(fmt.Sprintf("argument '%s' is set multiple times", a.Name), first.Location, a.Location))
+					continue
+				}
+				seen[a.Name] = a
+			}
+		}
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						check(node.(*ast.Field).Arguments)
+						return visitor.Continue
+					},
+				},
+				visitor.DirectiveKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						check(node.(*ast.Directive).Arguments)
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// ProvidedRequiredArgumentsRule checks that the required arguments are provided (5.4.2.1)
+var ProvidedRequiredArgumentsRule = &ValidationRule{
+	Name: "ProvidedRequiredArguments",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		check := func(astArgs []*ast.Argument, args Arguments, loc ast.Location) {
+			names := make([]string, 0, len(args))
+			for name := range args {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				a := args[name]
+				if a.Type.GetKind() != NonNullKind || a.IsDefaultValueSet() {
+					continue
+				}
+				if _, ok := getArgOfArgs(name, astArgs); !ok {
+					ctx.ReportError(newValidationError(fmt.Sprintf("argument '%s' is required (NonNull) but not provided", name), loc))
+				}
+			}
+		}
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FieldKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						if fd := ctx.FieldDef(); fd != nil {
+							f := node.(*ast.Field)
+							check(f.Arguments, fd.Arguments, f.Location)
+						}
+						return visitor.Continue
+					},
+				},
+				visitor.DirectiveKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						if def := ctx.Directive(); def != nil {
+							d := node.(*ast.Directive)
+							check(d.Arguments, def.GetArguments(), d.Location)
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// UniqueFragmentNamesRule checks that the names of the fragments are unique (5.5.1.1)
+var UniqueFragmentNamesRule = &ValidationRule{
+	Name: "UniqueFragmentNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FragmentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.Fragment)
+						if first := ctx.Fragment(f.Name); first != f {
+							ctx.ReportError(newValidationError(fmt.Sprintf("Fragment name '%s' is not unique, it's already used", f.Name), first.Location, f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// FragmentsOnCompositeTypesRule checks that the type conditions of the fragments are existing
+// composite types (5.5.1.2, 5.5.1.3)
+var FragmentsOnCompositeTypesRule = &ValidationRule{
+	Name: "FragmentsOnCompositeTypes",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FragmentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.Fragment)
+						if t := ctx.LookupType(f.TypeCondition); t == nil {
+							suggestions := ctx.Suggestions(f.TypeCondition, namesOf(ctx.types))
+							ctx.ReportError(newSuggestionError(fmt.Sprintf("Invalid fragment target '%s' for fragment '%s', type does not exist", f.TypeCondition, f.Name), suggestions, f.Location))
+						} else if !isCompositeType(t) {
+							ctx.ReportError(newValidationError(fmt.Sprintf("Invalid fragment target '%s' for fragment '%s', type is not composite", f.TypeCondition, f.Name), f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+				visitor.InlineFragmentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.InlineFragment)
+						if f.TypeCondition == "" {
+							return visitor.Continue
+						}
+						if t := ctx.LookupType(f.TypeCondition); t == nil {
+							suggestions := ctx.Suggestions(f.TypeCondition, namesOf(ctx.types))
+							ctx.ReportError(newSuggestionError(fmt.Sprintf("fragment's target type (%s) is not defined in query", f.TypeCondition), suggestions, f.Location))
+						} else if !isCompositeType(t) {
+							ctx.ReportError(newValidationError(fmt.Sprintf("Invalid fragment target '%s' for inline fragment, type is not composite", f.TypeCondition), f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// NoUnusedFragmentsRule checks that all the fragments are used by an operation (5.5.1.4)
+var NoUnusedFragmentsRule = &ValidationRule{
+	Name: "NoUnusedFragments",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.DocumentKind: {
+					Leave: func(node interface{}, _ *visitor.Info) visitor.Action {
+						doc := node.(*ast.Document)
+						used := map[*ast.Fragment]bool{}
+						for _, o := range doc.Operations {
+							for _, f := range ctx.RecursivelyReferencedFragments(o) {
+								used[f] = true
+							}
+						}
+						// report the unused fragments in the order of the document
+						for _, f := range doc.Fragments {
+							if ctx.Fragment(f.Name) == f && !used[f] {
+								ctx.ReportError(newValidationError(fmt.Sprintf("fragment '%s' is not used", f.Name), f.Location))
+							}
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// KnownFragmentNamesRule checks that the spread fragments are defined (5.5.2.1)
+var KnownFragmentNamesRule = &ValidationRule{
+	Name: "KnownFragmentNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FragmentSpreadKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						fs := node.(*ast.FragmentSpread)
+						if ctx.Fragment(fs.Name) == nil {
+							suggestions := ctx.Suggestions(fs.Name, namesOf(ctx.fragments))
+							ctx.ReportError(newSuggestionError(fmt.Sprintf("fragment '%s' is not defined in query", fs.Name), suggestions, fs.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// NoFragmentCyclesRule checks that the fragment spreads don't form cycles (5.5.2.2)
+var NoFragmentCyclesRule = &ValidationRule{
+	Name: "NoFragmentCycles",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		visited := map[string]bool{}
+		// the spreads from the fragment that's being checked, and their indexes by the fragment names
+		path := []*ast.FragmentSpread{}
+		pathIndex := map[string]int{}
+
+		var detectCycles func(f *ast.Fragment)
+		detectCycles = func(f *ast.Fragment) {
+			if visited[f.Name] {
+				return
+			}
+			visited[f.Name] = true
+			pathIndex[f.Name] = len(path)
+			for _, fs := range fragmentSpreads(f.SelectionSet) {
+				i, ok := pathIndex[fs.Name]
+				path = append(path, fs)
+				if !ok {
+					if next := ctx.Fragment(fs.Name); next != nil {
+						detectCycles(next)
+					}
+				} else {
+					locs := make([]ast.Location, 0, len(path)-i)
+					for _, s := range path[i:] {
+						locs = append(locs, s.Location)
+					}
+					ctx.ReportError(newValidationError(fmt.Sprintf("fragment cycle detected for fragment '%s'", fs.Name), locs...))
+				}
+				path = path[:len(path)-1]
+			}
+			delete(pathIndex, f.Name)
+		}
+
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FragmentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						detectCycles(node.(*ast.Fragment))
+						return visitor.Skip
+					},
+				},
+			},
+		}
+	},
+}
+
+// PossibleFragmentSpreadsRule checks that the fragments can apply on the types they're spread on (5.5.2.3)
+var PossibleFragmentSpreadsRule = &ValidationRule{
+	Name: "PossibleFragmentSpreads",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.FragmentSpreadKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						fs := node.(*ast.FragmentSpread)
+						parent := ctx.ParentType()
+						f := ctx.Fragment(fs.Name)
+						if parent == nil || f == nil {
+							return visitor.Continue
+						}
+						t := ctx.LookupType(f.TypeCondition)
+						if t == nil || !isCompositeType(t) || !isCompositeType(parent) {
+							return visitor.Continue
+						}
+						if !isPossibleSpread(ctx, parent, t) {
+							ctx.ReportError(newValidationError(fmt.Sprintf("cannot use '%s' spread on type '%s'", fs.Name, parent.GetName()), fs.Location))
+						}
+						return visitor.Continue
+					},
+				},
+				visitor.InlineFragmentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						f := node.(*ast.InlineFragment)
+						parent, t := ctx.ParentType(), ctx.Type()
+						if f.TypeCondition == "" || parent == nil || t == nil || !isCompositeType(t) || !isCompositeType(parent) {
+							return visitor.Continue
+						}
+						if !isPossibleSpread(ctx, parent, t) {
+							ctx.ReportError(newValidationError(fmt.Sprintf("invalid use of inline fragment on type '%s': target does not match", parent.GetName()), f.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// ValuesOfCorrectTypeRule checks that the values of the arguments and the default values of the
+// variables are valid for their types (5.6.1, 5.6.2, 5.6.3, 5.6.4)
+var ValuesOfCorrectTypeRule = &ValidationRule{
+	Name: "ValuesOfCorrectType",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.ArgumentKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						if t := ctx.InputType(); t != nil {
+							validateValue(ctx, t, node.(*ast.Argument).Value)
+						}
+						return visitor.Continue
+					},
+				},
+				visitor.VariableKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						v := node.(*ast.Variable)
+						if t := ctx.InputType(); t != nil && v.DefaultValue != nil {
+							validateValue(ctx, t, v.DefaultValue)
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// UniqueInputFieldNamesRule checks that the fields of the input object values are unique (5.6.3)
+var UniqueInputFieldNamesRule = &ValidationRule{
+	Name: "UniqueInputFieldNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.ObjectValueKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						seen := map[string]*ast.ObjectFieldValue{}
+						for _, f := range node.(*ast.ObjectValue).Fields {
+							if first, ok := seen[f.Name]; ok {
+								ctx.ReportError(newValidationError(fmt.Sprintf("field '%s' was set multiple times", f.Name), first.Location, f.Location))
+								continue
+							}
+							seen[f.Name] = f
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// KnownDirectivesRule checks that the directives are defined and used on valid locations (5.7.1, 5.7.2)
+var KnownDirectivesRule = &ValidationRule{
+	Name: "KnownDirectives",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.DirectiveKind: {
+					Enter: func(node interface{}, info *visitor.Info) visitor.Action {
+						d := node.(*ast.Directive)
+						def := ctx.Directive()
+						if def == nil {
+							suggestions := ctx.Suggestions(d.Name, namesOf(ctx.directives))
+							ctx.ReportError(newSuggestionError(fmt.Sprintf("directive '%s' is not defined", d.Name), suggestions, d.Location))
+							return visitor.Continue
+						}
+						loc, ok := directiveLocation(info.Parent)
+						if !ok {
+							return visitor.Continue
+						}
+						for _, l := range def.GetLocations() {
+							if l == loc {
+								return visitor.Continue
+							}
+						}
+						ctx.ReportError(newValidationError(fmt.Sprintf("directive '%s' is on invalid location", d.Name), d.Location))
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// UniqueDirectivesPerLocationRule checks that a directive is used only once on a node (5.7.3)
+var UniqueDirectivesPerLocationRule = &ValidationRule{
+	Name: "UniqueDirectivesPerLocation",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+				seen := map[string]*ast.Directive{}
+				for _, d := range nodeDirectives(node) {
+					if first, ok := seen[d.Name]; ok {
+						ctx.ReportError(newValidationError(fmt.Sprintf("directive '%s' is not unique per location", d.Name), first.Location, d.Location))
+						continue
+					}
+					seen[d.Name] = d
+				}
+				return visitor.Continue
+			},
+		}
+	},
+}
+
+// UniqueVariableNamesRule checks that the variables of an operation are unique (5.8.1)
+var UniqueVariableNamesRule = &ValidationRule{
+	Name: "UniqueVariableNames",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						seen := map[string]*ast.Variable{}
+						for _, v := range node.(*ast.Operation).Variables {
+							if first, ok := seen[v.Name]; ok {
+								ctx.ReportError(newValidationError(fmt.Sprintf("variable '%s' is defined multiple times", v.Name), first.Location, v.Location))
+								continue
+							}
+							seen[v.Name] = v
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// VariablesAreInputTypesRule checks that the types of the variables are input types (5.8.2)
+var VariablesAreInputTypesRule = &ValidationRule{
+	Name: "VariablesAreInputTypes",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.VariableKind: {
+					Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+						v := node.(*ast.Variable)
+						if t, err := resolveAstType(ctx.types, v.Type); err != nil {
+							nt := namedTypeOf(v.Type)
+							ctx.ReportError(newSuggestionError(err.Message, ctx.Suggestions(nt.Name, namesOf(ctx.types)), nt.Location))
+						} else if !isInputType(t) {
+							ctx.ReportError(newValidationError(fmt.Sprintf("variable '%s' is not an input type", v.Name), v.Location))
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// NoUndefinedVariablesRule checks that the variables used by an operation are defined by it (5.8.3)
+var NoUndefinedVariablesRule = &ValidationRule{
+	Name: "NoUndefinedVariables",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Leave: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						for _, u := range ctx.RecursiveVariableUsages(o) {
+							if operationVariable(o, u.Node.Name) == nil {
+								ctx.ReportError(newValidationError(fmt.Sprintf("variable '%s' is not defined", u.Node.Name), u.Node.Location))
+							}
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// NoUnusedVariablesRule checks that the variables defined by an operation are used (5.8.4)
+var NoUnusedVariablesRule = &ValidationRule{
+	Name: "NoUnusedVariables",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Leave: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						used := map[string]bool{}
+						for _, u := range ctx.RecursiveVariableUsages(o) {
+							used[u.Node.Name] = true
+						}
+						for _, v := range o.Variables {
+							if operationVariable(o, v.Name) == v && !used[v.Name] {
+								ctx.ReportError(newValidationError("Variable defined but not used", v.Location))
+							}
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// VariablesInAllowedPositionRule checks that the types of the variables are compatible with
+// the positions they're used in (5.8.5)
+var VariablesInAllowedPositionRule = &ValidationRule{
+	Name: "VariablesInAllowedPosition",
+	Visitor: func(ctx *ValidationContext) *visitor.Visitor {
+		return &visitor.Visitor{
+			Kinds: map[visitor.Kind]visitor.Funcs{
+				visitor.OperationKind: {
+					Leave: func(node interface{}, _ *visitor.Info) visitor.Action {
+						o := node.(*ast.Operation)
+						for _, u := range ctx.RecursiveVariableUsages(o) {
+							v := operationVariable(o, u.Node.Name)
+							if v == nil || u.Type == nil {
+								continue
+							}
+							t, err := resolveAstType(ctx.types, v.Type)
+							if err != nil {
+								continue
+							}
+							if !isVariableUsageAllowed(t, v, u) {
+								ctx.ReportError(newValidationError(fmt.Sprintf("variable '%s' is not allowed to use", u.Node.Name), u.Node.Location))
+								continue
+							}
+							if o, ok := u.ParentType.(*InputObject); ok && o.IsOneOf() && t.GetKind() != NonNullKind {
+								ctx.ReportError(newValidationError(fmt.Sprintf("variable '%s' must be non-nullable to be used for OneOf input object '%s'", u.Node.Name, o.Name), u.Node.Location))
+							}
+						}
+						return visitor.Continue
+					},
+				},
+			},
+		}
+	},
+}
+
+// namedTypeOf returns the named type inside the list and non-null wrappers of the type
+func namedTypeOf(t ast.Type) *ast.NamedType {
+	for t.Kind() != ast.Named {
+		t = t.GetValue().(ast.Type)
+	}
+	return t.(*ast.NamedType)
+}
+
+// suggestedTypesForField returns the possible types of an abstract type that have the field, to
+// suggest selecting the field in an inline fragment on them
+func suggestedTypesForField(ctx *ValidationContext, t Type, name string) []string {
+	if ctx.disableSuggestions || (t.GetKind() != InterfaceKind && t.GetKind() != UnionKind) {
+		return nil
+	}
+	types := []string{}
+	for _, pt := range ctx.PossibleTypes(t) {
+		if hf, ok := pt.(hasFields); ok && hf.GetFields()[name] != nil {
+			types = append(types, pt.GetName())
+		}
+	}
+	sort.Strings(types)
+	if len(types) > maxSuggestions {
+		types = types[:maxSuggestions]
+	}
+	return types
+}
+
+// operationVariable returns the first definition of the variable in the operation, or nil
+func operationVariable(o *ast.Operation, name string) *ast.Variable {
+	for _, v := range o.Variables {
+		if v.Name == name {
+			return v
+		}
+	}
+	return nil
+}
+
+// isVariableUsageAllowed reports whether the variable with the type can be used at the position of the usage,
+// a nullable variable can be used for a NonNull type if the variable or the position has a default value
+func isVariableUsageAllowed(varType Type, v *ast.Variable, u VariableUsage) bool {
+	if nn, ok := u.Type.(*NonNull); ok && varType.GetKind() != NonNullKind {
+		hasVariableDefault := v.DefaultValue != nil && v.DefaultValue.Kind() != ast.NullValueKind
+		if !hasVariableDefault && u.DefaultValue == nil {
+			return false
+		}
+		return areTypesCompatible(varType, nn.Unwrap())
+	}
+	return areTypesCompatible(varType, u.Type)
+}
+
+func areTypesCompatible(varType Type, locType Type) bool {
+	if ln, ok := locType.(*NonNull); ok {
+		vn, ok := varType.(*NonNull)
+		if !ok {
+			return false
+		}
+		return areTypesCompatible(vn.Unwrap(), ln.Unwrap())
+	}
+	if vn, ok := varType.(*NonNull); ok {
+		return areTypesCompatible(vn.Unwrap(), locType)
+	}
+	if ll, ok := locType.(*List); ok {
+		vl, ok := varType.(*List)
+		if !ok {
+			return false
+		}
+		return areTypesCompatible(vl.Unwrap(), ll.Unwrap())
+	}
+	if varType.GetKind() == ListKind {
+		return false
+	}
+	return varType.GetName() == locType.GetName()
+}
+
+// directiveLocation returns the location of the directives of the node
+func directiveLocation(node interface{}) (DirectiveLocation, bool) {
+	switch node := node.(type) {
+	case *ast.Operation:
+		switch node.OperationType {
+		case ast.Mutation:
+			return MutationLoc, true
+		case ast.Subscription:
+			return SubscriptionLoc, true
+		}
+		return QueryLoc, true
+	case *ast.Field:
+		return FieldLoc, true
+	case *ast.Fragment:
+		return FragmentDefinitionLoc, true
+	case *ast.FragmentSpread:
+		return FragmentSpreadLoc, true
+	case *ast.InlineFragment:
+		return InlineFragmentLoc, true
+	}
+	return "", false
+}
+
+// nodeDirectives returns the directives of the executable node
+func nodeDirectives(node interface{}) []*ast.Directive {
+	switch node := node.(type) {
+	case *ast.Operation:
+		return node.Directives
+	case *ast.Fragment:
+		return node.Directives
+	case ast.Selection:
+		return node.GetDirectives()
+	}
+	return nil
+}
+
+func isPossibleSpread(ctx *ValidationContext, parentType Type, fragType Type) bool {
+	pts := ctx.PossibleTypes(parentType)
+	fts := ctx.PossibleTypes(fragType)
+	for _, t := range pts {
+		for _, ft := range fts {
+			if t == ft {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// validateValue validates the value against the input type, the variables are validated by
+// VariablesInAllowedPositionRule
+func validateValue(ctx *ValidationContext, t Type, val ast.Value) {
+	switch {
+	case val.Kind() == ast.VariableValueKind:
+		return
+	case t.GetKind() == NonNullKind:
+		if vv, ok := val.(*ast.NullValue); ok {
+			ctx.ReportError(newValidationError("null value provided for NonNull type", vv.Location))
+			return
+		}
+		validateValue(ctx, t.(*NonNull).Unwrap(), val)
+		return
+	case val.Kind() == ast.NullValueKind:
+		return
+	case t.GetKind() == ListKind:
+		lv, ok := val.(*ast.ListValue)
+		if !ok {
+			// a single value is coerced to a list of one item
+			validateValue(ctx, t.(*List).Unwrap(), val)
+			return
+		}
+		for i := 0; i < len(lv.Values); i++ {
+			validateValue(ctx, t.(*List).Unwrap(), lv.Values[i])
+		}
+		return
+	case t.GetKind() == ScalarKind:
+		s := t.(*Scalar)
+		if s.AstValidator != nil {
+			if err := s.AstValidator(val); err != nil {
+				ctx.ReportError(newValidationError(err.Error(), val.GetLocation()))
+				return
+			}
+		}
+		if _, err := s.CoerceInputFunc(val.GetValue()); err != nil {
+			ctx.ReportError(newValidationError(err.Error(), val.GetLocation()))
+		}
+		return
+	case t.GetKind() == EnumKind:
+		if val.Kind() != ast.EnumValueKind {
+			ctx.ReportError(newValidationError("invalid value for Enum", val.GetLocation()))
+			return
+		}
+		v := val.GetValue().(string)
+		names := make([]string, 0, len(t.(*Enum).Values))
+		for _, ev := range t.(*Enum).Values {
+			if ev.Name == v {
+				return
+			}
+			names = append(names, ev.Name)
+		}
+		ctx.ReportError(newSuggestionError(fmt.Sprintf("invalid enum value '%s'", v), ctx.Suggestions(v, names), val.GetLocation()))
+		return
+	case t.GetKind() == InputObjectKind:
+		ov, ok := val.(*ast.ObjectValue)
+		if !ok {
+			ctx.ReportError(newValidationError("invalid value for InputObject", val.GetLocation()))
+			return
+		}
+		o := t.(*InputObject)
+		visitedFields := map[string]struct{}{}
+		for _, astf := range ov.Fields {
+			field, ok := o.Fields[astf.Name]
+			if !ok {
+				suggestions := ctx.Suggestions(astf.Name, namesOf(o.Fields))
+				ctx.ReportError(newSuggestionError(fmt.Sprintf("field '%s' is not defined", astf.Name), suggestions, astf.GetLocation()))
+				continue
+			}
+
+			if _, ok := visitedFields[astf.Name]; ok {
+				// reported by UniqueInputFieldNamesRule
+				continue
+			}
+			visitedFields[astf.Name] = struct{}{}
+			validateValue(ctx, field.Type, astf.Value)
+		}
+
+		if o.IsOneOf() {
+			validateOneOfValue(ctx, o, ov)
+			return
+		}
+
+		names := make([]string, 0, len(o.Fields))
+		for fn := range o.Fields {
+			names = append(names, fn)
+		}
+		sort.Strings(names)
+		for _, fn := range names {
+			field := o.Fields[fn]
+			if _, ok := visitedFields[fn]; !ok {
+				if !field.IsDefaultValueSet() && field.Type.GetKind() == NonNullKind {
+					ctx.ReportError(newValidationError(fmt.Sprintf("no value provided for field '%s' with NonNull type", fn), ov.Location))
+				}
+			}
+		}
+	}
+}
+
+// validateOneOfValue checks that exactly one field of the OneOf input object is given, and its
+// value is not null
+func validateOneOfValue(ctx *ValidationContext, o *InputObject, ov *ast.ObjectValue) {
+	if len(ov.Fields) != 1 {
+		ctx.ReportError(newValidationError(fmt.Sprintf("exactly one field must be provided for OneOf input object '%s'", o.Name), ov.Location))
+		return
+	}
+	if f := ov.Fields[0]; f.Value.Kind() == ast.NullValueKind {
+		ctx.ReportError(newValidationError(fmt.Sprintf("field '%s' of OneOf input object '%s' must not be null", f.Name, o.Name), f.Value.GetLocation()))
+	}
+}
+
+// fieldAndParent is a field in a selection set, with the type it is selected on
+type fieldAndParent struct {
+	field  *ast.Field
+	parent Type
+}
+
+// collectFieldsForValidation collects the fields of the selection set, including the fields of
+// the fragments, without evaluating the directives, since the variables are not known
+func collectFieldsForValidation(ctx *ValidationContext, parent Type, set []ast.Selection, visited map[string]bool) []fieldAndParent {
+	fields := []fieldAndParent{}
+	for _, sel := range set {
+		switch sel := sel.(type) {
+		case *ast.Field:
+			fields = append(fields, fieldAndParent{field: sel, parent: parent})
+		case *ast.InlineFragment:
+			t := parent
+			if sel.TypeCondition != "" {
+				if t = ctx.types[sel.TypeCondition]; t == nil {
+					continue
+				}
+			}
+			fields = append(fields, collectFieldsForValidation(ctx, t, sel.SelectionSet, visited)...)
+		case *ast.FragmentSpread:
+			if visited[sel.Name] {
+				continue
+			}
+			visited[sel.Name] = true
+			f, ok := ctx.fragments[sel.Name]
+			if !ok {
+				continue
+			}
+			t, ok := ctx.types[f.TypeCondition]
+			if !ok {
+				continue
+			}
+			fields = append(fields, collectFieldsForValidation(ctx, t, f.SelectionSet, visited)...)
+		}
+	}
+	return fields
+}
+
+// groupByResponseKey groups the fields by their response keys, the keys are in the order of their first fields
+func groupByResponseKey(fields []fieldAndParent) ([]string, map[string][]fieldAndParent) {
+	keys := []string{}
+	groups := map[string][]fieldAndParent{}
+	for _, f := range fields {
+		if _, ok := groups[f.field.Alias]; !ok {
+			keys = append(keys, f.field.Alias)
+		}
+		groups[f.field.Alias] = append(groups[f.field.Alias], f)
+	}
+	return keys, groups
+}
+
+// fieldsMerger checks whether the fields of the selection sets can be merged. The fields collected
+// from the selection sets and the compared pairs of fields are cached, since with repeated fragment
+// spreads the same fields would be compared again on every level, growing exponentially
+type fieldsMerger struct {
+	ctx      *ValidationContext
+	fields   map[selectionSetKey][]fieldAndParent
+	compared map[fieldPairKey]string
+}
+
+// selectionSetKey identifies a selection set of the document selected on a type
+type selectionSetKey struct {
+	first  *ast.Selection
+	len    int
+	parent Type
+}
+
+// fieldPairKey identifies two compared fields and whether their parents are mutually exclusive
+type fieldPairKey struct {
+	a, b                     fieldAndParent
+	parentsMutuallyExclusive bool
+}
+
+// collectFields returns the fields of the selection set, collecting them only once per set and type
+func (m *fieldsMerger) collectFields(parent Type, set []ast.Selection) []fieldAndParent {
+	if len(set) == 0 {
+		return nil
+	}
+	key := selectionSetKey{first: &set[0], len: len(set), parent: parent}
+	fields, ok := m.fields[key]
+	if !ok {
+		fields = collectFieldsForValidation(m.ctx, parent, set, map[string]bool{})
+		m.fields[key] = fields
+	}
+	return fields
+}
+
+func (m *fieldsMerger) fieldsInSetCanMerge(set []ast.Selection, t Type) {
+	keys, groups := groupByResponseKey(m.collectFields(t, set))
+	for _, key := range keys {
+		fields := groups[key]
+		for i := 0; i < len(fields); i++ {
+			for j := i + 1; j < len(fields); j++ {
+				if reason := m.fieldsConflict(fields[i], fields[j], false); reason != "" {
+					m.ctx.ReportError(newValidationError(
+						fmt.Sprintf(errResponseShapeMismatch, fmt.Sprintf("'%s' conflict because %s", key, reason)),
+						fields[i].field.Location,
+						fields[j].field.Location,
+					))
+				}
+			}
+		}
+	}
+}
+
+// fieldsConflict returns the reason why the fields with the same response key can not be merged,
+// or an empty string if they can be. The result is cached for the pair of fields.
+func (m *fieldsMerger) fieldsConflict(a fieldAndParent, b fieldAndParent, parentsMutuallyExclusive bool) string {
+	key := fieldPairKey{a: a, b: b, parentsMutuallyExclusive: parentsMutuallyExclusive}
+	if reason, ok := m.compared[key]; ok {
+		return reason
+	}
+	// the pair is marked before comparing the subfields, so the cycles of fragments end here
+	m.compared[key] = ""
+	reason := m.compareFields(a, b, parentsMutuallyExclusive)
+	m.compared[key] = reason
+	return reason
+}
+
+func (m *fieldsMerger) compareFields(a fieldAndParent, b fieldAndParent, parentsMutuallyExclusive bool) string {
+	// the fields on different object types are never executed together, only their response shapes have to match
+	_, aIsObject := a.parent.(*Object)
+	_, bIsObject := b.parent.(*Object)
+	mutuallyExclusive := parentsMutuallyExclusive || (a.parent != b.parent && aIsObject && bIsObject)
+
+	if !mutuallyExclusive {
+		if a.field.Name != b.field.Name {
+			return fmt.Sprintf("'%s' and '%s' are different fields", a.field.Name, b.field.Name)
+		}
+		if !equalArguments(a.field.Arguments, b.field.Arguments) {
+			return "they have differing arguments"
+		}
+	}
+
+	defA := m.ctx.info.lookupField(a.parent, a.field.Name)
+	defB := m.ctx.info.lookupField(b.parent, b.field.Name)
+	if defA == nil || defB == nil {
+		// the missing fields are reported by FieldsOnCorrectTypeRule
+		return ""
+	}
+	if doTypesConflict(defA.Type, defB.Type) {
+		return fmt.Sprintf("they return conflicting types '%s' and '%s'", defA.Type, defB.Type)
+	}
+
+	typeA, typeB := unwrapper(defA.Type), unwrapper(defB.Type)
+	if !isCompositeType(typeA) || !isCompositeType(typeB) {
+		return ""
+	}
+	fieldsA := m.collectFields(typeA, a.field.SelectionSet)
+	keys, groupsB := groupByResponseKey(m.collectFields(typeB, b.field.SelectionSet))
+	for _, key := range keys {
+		for _, fa := range fieldsA {
+			if fa.field.Alias != key {
+				continue
+			}
+			for _, fb := range groupsB[key] {
+				if reason := m.fieldsConflict(fa, fb, mutuallyExclusive); reason != "" {
+					return fmt.Sprintf("subfields '%s' conflict because %s", key, reason)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// doTypesConflict reports whether two fields with the given types can't be in the same response
+func doTypesConflict(a Type, b Type) bool {
+	switch {
+	case a.GetKind() == ListKind || b.GetKind() == ListKind:
+		if a.GetKind() != b.GetKind() {
+			return true
+		}
+		return doTypesConflict(a.(*List).Unwrap(), b.(*List).Unwrap())
+	case a.GetKind() == NonNullKind || b.GetKind() == NonNullKind:
+		if a.GetKind() != b.GetKind() {
+			return true
+		}
+		return doTypesConflict(a.(*NonNull).Unwrap(), b.(*NonNull).Unwrap())
+	case !isCompositeType(a) || !isCompositeType(b):
+		return a != b
+	}
+	return false
+}
+
+func equalArguments(a []*ast.Argument, b []*ast.Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, fa := range a {
+		fb, ok := getArgOfArgs(fa.Name, b)
+		if !ok || !equalValue(fa.Value, fb.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+func equalValue(a ast.Value, b ast.Value) bool {
+	return a.Kind() == b.Kind() && a.String() == b.String()
+}
@@ -0,0 +1,1778 @@
+package gql_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/rigglo/gql"
+	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/testutil"
+)
+
+// The test cases of the rules are ported from the validation tests of graphql-js, using the
+// shared test schema, and every rule is tested on its own.
+
+type ruleError struct {
+	message   string
+	locations []*gql.ErrorLocation
+}
+
+type ruleTest struct {
+	name   string
+	query  string
+	errors []ruleError
+}
+
+func ruleErr(message string, locs ...int) ruleError {
+	e := ruleError{message: message, locations: []*gql.ErrorLocation{}}
+	for i := 0; i+1 < len(locs); i += 2 {
+		e.locations = append(e.locations, &gql.ErrorLocation{Line: locs[i], Column: locs[i+1]})
+	}
+	return e
+}
+
+func runRuleTests(t *testing.T, rule *gql.ValidationRule, tests []ruleTest) {
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := []ruleError{}
+			for _, e := range gql.Validate(testutil.Schema, doc, []*gql.ValidationRule{rule}) {
+				errs = append(errs, ruleError{message: e.Message, locations: e.Locations})
+			}
+			if tt.errors == nil {
+				tt.errors = []ruleError{}
+			}
+			if !reflect.DeepEqual(errs, tt.errors) {
+				t.Fatalf("expected errors\n%s\ngot\n%s", formatRuleErrors(tt.errors), formatRuleErrors(errs))
+			}
+		})
+	}
+}
+
+func formatRuleErrors(errs []ruleError) string {
+	out := ""
+	for _, e := range errs {
+		out += "  " + e.message
+		for _, l := range e.locations {
+			out += fmt.Sprintf(" %d:%d", l.Line, l.Column)
+		}
+		out += "\n"
+	}
+	return out
+}
+
+func TestExecutableDefinitionsRule(t *testing.T) {
+	runRuleTests(t, gql.ExecutableDefinitionsRule, []ruleTest{
+		{
+			name:  "OnlyOperation",
+			query: "query Foo {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "OperationAndFragment",
+			query: "query Foo {\n  dog {\n    name\n    ...Frag\n  }\n}\nfragment Frag on Dog {\n  name\n}",
+		},
+		{
+			name:  "TypeDefinition",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\ntype Cow {\n  name: String\n}\nextend type Dog {\n  color: String\n}",
+			errors: []ruleError{
+				ruleErr("The 'Cow' definition is not executable", 6, 1),
+				ruleErr("The 'Dog' definition is not executable", 9, 1),
+			},
+		},
+		{
+			name:  "SchemaDefinition",
+			query: "schema {\n  query: Query\n}\ntype Query {\n  test: String\n}\nextend schema @directive",
+			errors: []ruleError{
+				ruleErr("The schema definition is not executable", 1, 1),
+				ruleErr("The 'Query' definition is not executable", 4, 1),
+				ruleErr("The schema definition is not executable", 7, 1),
+			},
+		},
+	})
+}
+
+func TestUniqueOperationNamesRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueOperationNamesRule, []ruleTest{
+		{
+			name:  "NoOperations",
+			query: "fragment fragA on Dog {\n  name\n}",
+		},
+		{
+			name:  "OneAnonymousOperation",
+			query: "{\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "MultipleOperations",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\nquery Bar {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "MultipleOperationsOfDifferentTypes",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\nmutation Bar {\n  dog {\n    name\n  }\n}\nsubscription Baz {\n  importantEmails\n}",
+		},
+		{
+			name:  "FragmentAndOperationNamedTheSame",
+			query: "query Foo {\n  ...Foo\n}\nfragment Foo on Query {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "MultipleOperationsOfSameName",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\nquery Foo {\n  cat {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Operation name 'Foo' is defined multiple times", 1, 1, 6, 1),
+			},
+		},
+		{
+			name:  "MultipleOperationsOfSameNameOfDifferentTypes",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\nsubscription Foo {\n  importantEmails\n}",
+			errors: []ruleError{
+				ruleErr("Operation name 'Foo' is defined multiple times", 1, 1, 6, 1),
+			},
+		},
+	})
+}
+
+func TestLoneAnonymousOperationRule(t *testing.T) {
+	runRuleTests(t, gql.LoneAnonymousOperationRule, []ruleTest{
+		{
+			name:  "NoOperations",
+			query: "fragment fragA on Dog {\n  name\n}",
+		},
+		{
+			name:  "OneAnonymousOperation",
+			query: "{\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "MultipleNamedOperations",
+			query: "query Foo {\n  dog {\n    name\n  }\n}\nquery Bar {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "AnonymousOperationWithFragment",
+			query: "{\n  ...Foo\n}\nfragment Foo on Query {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "MultipleAnonymousOperations",
+			query: "{\n  dog {\n    name\n  }\n}\n{\n  cat {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Can not use anonymous operation where multiple operation definitions exist", 1, 1),
+				ruleErr("Can not use anonymous operation where multiple operation definitions exist", 6, 1),
+			},
+		},
+		{
+			name:  "AnonymousOperationWithAnotherOperation",
+			query: "{\n  dog {\n    name\n  }\n}\nsubscription Foo {\n  importantEmails\n}",
+			errors: []ruleError{
+				ruleErr("Can not use anonymous operation where multiple operation definitions exist", 1, 1),
+			},
+		},
+	})
+}
+
+func TestKnownOperationTypesRule(t *testing.T) {
+	runRuleTests(t, gql.KnownOperationTypesRule, []ruleTest{
+		{
+			name:  "Query",
+			query: "{\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "Subscription",
+			query: "subscription {\n  importantEmails\n}",
+		},
+		{
+			name:  "Mutation",
+			query: "mutation {\n  dog {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("No root mutation defined in schema", 1, 1),
+			},
+		},
+	})
+}
+
+func TestSingleFieldSubscriptionsRule(t *testing.T) {
+	runRuleTests(t, gql.SingleFieldSubscriptionsRule, []ruleTest{
+		{
+			name:  "SingleRootField",
+			query: "subscription ImportantEmails {\n  importantEmails\n}",
+		},
+		{
+			name:  "SingleRootFieldWithFragment",
+			query: "subscription sub {\n  ...newMessageFields\n}\nfragment newMessageFields on Subscription {\n  importantEmails\n}",
+		},
+		{
+			name:  "FragmentAndFieldWithTheSameResponseKey",
+			query: "subscription sub {\n  importantEmails\n  ...newMessageFields\n}\nfragment newMessageFields on Subscription {\n  importantEmails\n}",
+		},
+		{
+			name:  "MoreThanOneRootField",
+			query: "subscription ImportantEmails {\n  importantEmails\n  notImportantEmails\n}",
+			errors: []ruleError{
+				ruleErr("Subscriptions must have only one root field in the selection set", 1, 1),
+			},
+		},
+		{
+			name:  "MoreThanOneRootFieldWithFragment",
+			query: "subscription ImportantEmails {\n  importantEmails\n  ...F\n}\nfragment F on Subscription {\n  notImportantEmails\n}",
+			errors: []ruleError{
+				ruleErr("Subscriptions must have only one root field in the selection set", 1, 1),
+			},
+		},
+		{
+			name:  "MoreThanOneRootFieldInInlineFragment",
+			query: "subscription {\n  ... on Subscription {\n    importantEmails\n    moreImportantEmails\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Subscriptions must have only one root field in the selection set", 1, 1),
+			},
+		},
+		{
+			name:  "IntrospectionField",
+			query: "subscription ImportantEmails {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("Subscriptions can not have the introspection field '__typename' as root field", 2, 3),
+			},
+		},
+		{
+			name:  "IntrospectionFieldWithFragment",
+			query: "subscription ImportantEmails {\n  ...F\n}\nfragment F on Subscription {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("Subscriptions can not have the introspection field '__typename' as root field", 5, 3),
+			},
+		},
+		{
+			name:  "Query",
+			query: "{\n  dog {\n    name\n  }\n  cat {\n    name\n  }\n}",
+		},
+	})
+}
+
+func TestFieldsOnCorrectTypeRule(t *testing.T) {
+	runRuleTests(t, gql.FieldsOnCorrectTypeRule, []ruleTest{
+		{
+			name:  "ObjectFieldSelection",
+			query: "fragment objectFieldSelection on Dog {\n  __typename\n  name\n}",
+		},
+		{
+			name:  "AliasedObjectFieldSelection",
+			query: "fragment aliasedObjectFieldSelection on Dog {\n  tn : __typename\n  otherName : name\n}",
+		},
+		{
+			name:  "InterfaceFieldSelection",
+			query: "fragment interfaceFieldSelection on Pet {\n  __typename\n  name\n}",
+		},
+		{
+			name:  "LyingAliasSelection",
+			query: "fragment lyingAliasSelection on Dog {\n  name : nickname\n}",
+		},
+		{
+			name:  "IgnoresFieldsOnUnknownType",
+			query: "fragment unknownSelection on UnknownType {\n  unknownField\n}",
+		},
+		{
+			name:  "ReportsErrorsWhenTypeIsKnownAgain",
+			query: "fragment typeKnownAgain on Pet {\n  unknown_pet_field {\n    ... on Cat {\n      unknown_cat_field\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Field 'unknown_pet_field' does not exist on type 'Pet'", 2, 3),
+				ruleErr("Field 'unknown_cat_field' does not exist on type 'Cat'", 4, 7),
+			},
+		},
+		{
+			name:  "FieldNotDefinedOnFragment",
+			query: "fragment fieldNotDefined on Dog {\n  meowVolume\n}",
+			errors: []ruleError{
+				ruleErr("Field 'meowVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?", 2, 3),
+			},
+		},
+		{
+			name:  "IgnoresDeeplyUnknownField",
+			query: "fragment deepFieldNotDefined on Dog {\n  unknown_field {\n    deeper_unknown_field\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Field 'unknown_field' does not exist on type 'Dog'", 2, 3),
+			},
+		},
+		{
+			name:  "SubFieldNotDefined",
+			query: "fragment subFieldNotDefined on Human {\n  pets {\n    unknown_field\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Field 'unknown_field' does not exist on type 'Pet'", 3, 5),
+			},
+		},
+		{
+			name:  "FieldNotDefinedOnInlineFragment",
+			query: "fragment fieldNotDefined on Pet {\n  ... on Dog {\n    meowVolume\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Field 'meowVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?", 3, 5),
+			},
+		},
+		{
+			name:  "AliasedFieldTargetNotDefined",
+			query: "fragment aliasedFieldTargetNotDefined on Dog {\n  volume : mooVolume\n}",
+			errors: []ruleError{
+				ruleErr("Field 'mooVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?", 2, 3),
+			},
+		},
+		{
+			name:  "NotDefinedOnInterface",
+			query: "fragment notDefinedOnInterface on Pet {\n  tailLength\n}",
+			errors: []ruleError{
+				ruleErr("Field 'tailLength' does not exist on type 'Pet'", 2, 3),
+			},
+		},
+		{
+			name:  "DefinedOnImplementorsButNotOnInterface",
+			query: "fragment definedOnImplementorsButNotInterface on Pet {\n  nickname\n}",
+			errors: []ruleError{
+				ruleErr("Field 'nickname' does not exist on type 'Pet'. Did you mean to use an inline fragment on 'Cat' or 'Dog'?", 2, 3),
+			},
+		},
+		{
+			name:  "MetaFieldSelectionOnUnion",
+			query: "fragment directFieldSelectionOnUnion on CatOrDog {\n  __typename\n}",
+		},
+		{
+			name:  "DirectFieldSelectionOnUnion",
+			query: "fragment directFieldSelectionOnUnion on CatOrDog {\n  directField\n}",
+			errors: []ruleError{
+				ruleErr("Invalid field selection on type 'CatOrDog'", 2, 3),
+			},
+		},
+		{
+			name:  "DefinedOnImplementorsQueriedOnUnion",
+			query: "fragment definedOnImplementorsQueriedOnUnion on CatOrDog {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("Invalid field selection on type 'CatOrDog'. Did you mean to use an inline fragment on 'Cat' or 'Dog'?", 2, 3),
+			},
+		},
+		{
+			name:  "ValidFieldInInlineFragment",
+			query: "fragment objectFieldSelection on Pet {\n  ... on Dog {\n    name\n  }\n  ... {\n    name\n  }\n}",
+		},
+		{
+			name:  "IntrospectionFieldsOnQuery",
+			query: "{\n  __schema {\n    queryType {\n      name\n    }\n  }\n  __type(name: \"Dog\") {\n    name\n  }\n}",
+		},
+		{
+			name:  "IntrospectionFieldNotOnQuery",
+			query: "{\n  dog {\n    __schema {\n      queryType {\n        name\n      }\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Field '__schema' does not exist on type 'Dog'", 3, 5),
+			},
+		},
+	})
+}
+
+func TestOverlappingFieldsCanBeMergedRule(t *testing.T) {
+	runRuleTests(t, gql.OverlappingFieldsCanBeMergedRule, []ruleTest{
+		{
+			name:  "UniqueFields",
+			query: "fragment uniqueFields on Dog {\n  name\n  nickname\n}",
+		},
+		{
+			name:  "IdenticalFields",
+			query: "fragment mergeIdenticalFields on Dog {\n  name\n  name\n}",
+		},
+		{
+			name:  "IdenticalFieldsWithIdenticalArgs",
+			query: "fragment mergeIdenticalFieldsWithIdenticalArgs on Dog {\n  doesKnowCommand(dogCommand: SIT)\n  doesKnowCommand(dogCommand: SIT)\n}",
+		},
+		{
+			name:  "IdenticalFieldsWithIdenticalDirectives",
+			query: "fragment mergeSameFieldsWithSameDirectives on Dog {\n  name @include(if: true)\n  name @include(if: true)\n}",
+		},
+		{
+			name:  "DifferentArgsWithDifferentAliases",
+			query: "fragment differentArgsWithDifferentAliases on Dog {\n  knowsSit: doesKnowCommand(dogCommand: SIT)\n  knowsDown: doesKnowCommand(dogCommand: DOWN)\n}",
+		},
+		{
+			name:  "DifferentDirectivesWithDifferentAliases",
+			query: "fragment differentDirectivesWithDifferentAliases on Dog {\n  nameIfTrue: name @include(if: true)\n  nameIfFalse: name @include(if: false)\n}",
+		},
+		{
+			name:  "DifferentSkipIncludeDirectives",
+			query: "fragment differentDirectivesWithDifferentAliases on Dog {\n  name @include(if: true)\n  name @include(if: false)\n}",
+		},
+		{
+			name:  "SameAliasesWithDifferentFieldTargets",
+			query: "fragment sameAliasesWithDifferentFieldTargets on Dog {\n  fido: name\n  fido: nickname\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'fido' conflict because 'name' and 'nickname' are different fields", 2, 3, 3, 3),
+			},
+		},
+		{
+			name:  "SameAliasesOnNonOverlappingFields",
+			query: "fragment sameAliasesWithDifferentFieldTargets on Pet {\n  ... on Dog {\n    name\n  }\n  ... on Cat {\n    name\n  }\n}",
+		},
+		{
+			name:  "SameAliasesOnNonOverlappingFieldsWithDifferentShapes",
+			query: "fragment sameAliasesWithDifferentFieldTargets on Pet {\n  ... on Dog {\n    name\n  }\n  ... on Cat {\n    name: nickname\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'name' conflict because they return conflicting types 'String!' and 'String'", 3, 5, 6, 5),
+			},
+		},
+		{
+			name:  "AliasMaskingDirectFieldAccess",
+			query: "fragment aliasMaskingDirectFieldAccess on Dog {\n  name: nickname\n  name\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'name' conflict because 'nickname' and 'name' are different fields", 2, 3, 3, 3),
+			},
+		},
+		{
+			name:  "DifferentArgsSecondAddsAnArgument",
+			query: "fragment conflictingArgs on Dog {\n  doesKnowCommand\n  doesKnowCommand(dogCommand: HEEL)\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'doesKnowCommand' conflict because they have differing arguments", 2, 3, 3, 3),
+			},
+		},
+		{
+			name:  "DifferentArgsSecondMissingAnArgument",
+			query: "fragment conflictingArgs on Dog {\n  doesKnowCommand(dogCommand: SIT)\n  doesKnowCommand\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'doesKnowCommand' conflict because they have differing arguments", 2, 3, 3, 3),
+			},
+		},
+		{
+			name:  "ConflictingArgValues",
+			query: "fragment conflictingArgs on Dog {\n  doesKnowCommand(dogCommand: SIT)\n  doesKnowCommand(dogCommand: HEEL)\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'doesKnowCommand' conflict because they have differing arguments", 2, 3, 3, 3),
+			},
+		},
+		{
+			name:  "DifferentArgsWhereNoConflictIsPossible",
+			query: "fragment conflictingArgs on Pet {\n  ... on Dog {\n    name(surname: true)\n  }\n  ... on Cat {\n    name\n  }\n}",
+		},
+		{
+			name:  "ConflictInFragments",
+			query: "{\n  dog {\n    ...A\n    ...B\n  }\n}\nfragment A on Dog {\n  x: name\n}\nfragment B on Dog {\n  x: nickname\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'x' conflict because 'name' and 'nickname' are different fields", 8, 3, 11, 3),
+			},
+		},
+		{
+			name:  "DeepConflict",
+			query: "{\n  dog {\n    x: name\n  }\n  dog {\n    x: nickname\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'dog' conflict because subfields 'x' conflict because 'name' and 'nickname' are different fields", 2, 3, 5, 3),
+			},
+		},
+		{
+			name:  "DeepConflictWithMultipleIssues",
+			query: "{\n  dog {\n    x: name\n    y: barkVolume\n  }\n  dog {\n    x: nickname\n    y: name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'dog' conflict because subfields 'x' conflict because 'name' and 'nickname' are different fields", 2, 3, 6, 3),
+			},
+		},
+		{
+			name:  "ConflictingReturnTypesOnDifferentObjects",
+			query: "{\n  pet {\n    ... on Dog {\n      x: barkVolume\n    }\n    ... on Cat {\n      x: name\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'x' conflict because they return conflicting types 'Int' and 'String!'", 4, 7, 7, 7),
+			},
+		},
+		{
+			name:  "SameReturnTypesOnDifferentObjects",
+			query: "{\n  pet {\n    ... on Dog {\n      x: name\n    }\n    ... on Cat {\n      x: name\n    }\n  }\n}",
+		},
+		{
+			name:  "DisallowsDifferingNullability",
+			query: "{\n  pet {\n    ... on Dog {\n      x: nickname\n    }\n    ... on Cat {\n      x: name\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'x' conflict because they return conflicting types 'String' and 'String!'", 4, 7, 7, 7),
+			},
+		},
+		{
+			name:  "DisallowsDifferingListAndNonList",
+			query: "{\n  dog {\n    owner {\n      x: pets {\n        name\n      }\n    }\n  }\n  dog {\n    owner {\n      x: name\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'dog' conflict because subfields 'owner' conflict because subfields 'x' conflict because 'pets' and 'name' are different fields", 2, 3, 9, 3),
+			},
+		},
+		{
+			name:  "DifferentFieldsOnMutuallyExclusiveParents",
+			query: "{\n  catOrDog {\n    ... on Dog {\n      owner {\n        x: pets {\n          name\n        }\n      }\n    }\n    ... on Cat {\n      x: name\n    }\n  }\n}",
+		},
+		{
+			name:  "ConflictingSubfieldsOnMutuallyExclusiveParents",
+			query: "{\n  catOrDog {\n    ... on Dog {\n      owner {\n        x: pets {\n          name\n        }\n      }\n    }\n    ... on Cat {\n      owner: name\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fields in set can not be merged: 'owner' conflict because they return conflicting types 'Human' and 'String!'", 4, 7, 11, 7),
+			},
+		},
+	})
+}
+
+// repeatedFragmentsQuery returns a document where every fragment spreads the previous one in
+// two fields with the same response key, so without caching the compared fields, the checks of
+// OverlappingFieldsCanBeMergedRule grow exponentially with the depth
+func repeatedFragmentsQuery(depth int) string {
+	query := "{\n  dog {\n    ...F" + fmt.Sprint(depth) + "\n  }\n}\nfragment F0 on Dog {\n  name\n}\n"
+	for i := 1; i <= depth; i++ {
+		query += fmt.Sprintf("fragment F%d on Dog {\n  owner {\n    pets {\n      ... on Dog {\n        ...F%d\n      }\n    }\n  }\n  owner {\n    pets {\n      ... on Dog {\n        ...F%d\n      }\n    }\n  }\n}\n", i, i-1, i-1)
+	}
+	return query
+}
+
+func TestOverlappingFieldsCanBeMergedRuleRepeatedFragments(t *testing.T) {
+	doc, err := parser.Parse([]byte(repeatedFragmentsQuery(40)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs := gql.Validate(testutil.Schema, doc, []*gql.ValidationRule{gql.OverlappingFieldsCanBeMergedRule}); len(errs) != 0 {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func BenchmarkOverlappingFieldsCanBeMergedRule(b *testing.B) {
+	doc, err := parser.Parse([]byte(repeatedFragmentsQuery(40)))
+	if err != nil {
+		b.Fatal(err)
+	}
+	rules := []*gql.ValidationRule{gql.OverlappingFieldsCanBeMergedRule}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		gql.Validate(testutil.Schema, doc, rules)
+	}
+}
+
+func TestScalarLeafsRule(t *testing.T) {
+	runRuleTests(t, gql.ScalarLeafsRule, []ruleTest{
+		{
+			name:  "ValidScalarSelection",
+			query: "fragment scalarSelection on Dog {\n  barkVolume\n}",
+		},
+		{
+			name:  "ObjectTypeMissingSelection",
+			query: "query directQueryOnObjectWithoutSubFields {\n  human\n}",
+			errors: []ruleError{
+				ruleErr("Selection on type 'Human' is missing", 2, 3),
+			},
+		},
+		{
+			name:  "InterfaceTypeMissingSelection",
+			query: "{\n  human {\n    pets\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Selection on type 'Pet' is missing", 3, 5),
+			},
+		},
+		{
+			name:  "ValidScalarSelectionWithArgs",
+			query: "fragment scalarSelectionWithArgs on Dog {\n  doesKnowCommand(dogCommand: SIT)\n}",
+		},
+		{
+			name:  "ScalarSelectionNotAllowedOnBoolean",
+			query: "fragment scalarSelectionsNotAllowedOnBoolean on Dog {\n  isHousetrained {\n    sinceWhen\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Selection on type 'Boolean' is not allowed", 2, 3),
+			},
+		},
+		{
+			name:  "ScalarSelectionNotAllowedWithArgs",
+			query: "fragment scalarSelectionsNotAllowedWithArgs on Dog {\n  doesKnowCommand(dogCommand: SIT) {\n    sinceWhen\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Selection on type 'Boolean' is not allowed", 2, 3),
+			},
+		},
+		{
+			name:  "ScalarSelectionNotAllowedWithDirectives",
+			query: "fragment scalarSelectionsNotAllowedWithDirectives on Dog {\n  name @include(if: true) {\n    isAlsoHumanName\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Selection on type 'String' is not allowed", 2, 3),
+			},
+		},
+	})
+}
+
+func TestKnownArgumentNamesRule(t *testing.T) {
+	runRuleTests(t, gql.KnownArgumentNamesRule, []ruleTest{
+		{
+			name:  "SingleArgIsKnown",
+			query: "fragment argOnRequiredArg on Dog {\n  doesKnowCommand(dogCommand: SIT)\n}",
+		},
+		{
+			name:  "MultipleArgsAreKnown",
+			query: "fragment multipleArgs on ComplicatedArgs {\n  multipleReqs(req1: 1, req2: 2)\n}",
+		},
+		{
+			name:  "IgnoresArgsOfUnknownFields",
+			query: "fragment argOnUnknownField on Dog {\n  unknownField(unknownArg: SIT)\n}",
+		},
+		{
+			name:  "MultipleArgsInReverseOrder",
+			query: "fragment multipleArgsReverseOrder on ComplicatedArgs {\n  multipleReqs(req2: 2, req1: 1)\n}",
+		},
+		{
+			name:  "NoArgsOnOptionalArg",
+			query: "fragment noArgOnOptionalArg on Dog {\n  isHousetrained\n}",
+		},
+		{
+			name:  "ArgsAreKnownDeeply",
+			query: "{\n  dog {\n    doesKnowCommand(dogCommand: SIT)\n  }\n  human {\n    pets {\n      ... on Dog {\n        doesKnowCommand(dogCommand: SIT)\n      }\n    }\n  }\n}",
+		},
+		{
+			name:  "DirectiveArgsAreKnown",
+			query: "{\n  dog @skip(if: true)\n}",
+		},
+		{
+			name:  "FieldArgsAreInvalidOnDirective",
+			query: "{\n  dog @skip(unless: true)\n}",
+			errors: []ruleError{
+				ruleErr("argument 'unless' is not defined", 2, 13),
+			},
+		},
+		{
+			name:  "IgnoresArgsOfUnknownDirectives",
+			query: "{\n  dog @unknown(unless: true)\n}",
+		},
+		{
+			name:  "InvalidArgName",
+			query: "fragment invalidArgName on Dog {\n  doesKnowCommand(unknown: true)\n}",
+			errors: []ruleError{
+				ruleErr("argument 'unknown' is not defined", 2, 19),
+			},
+		},
+		{
+			name:  "UnknownArgsAmongstKnownArgs",
+			query: "fragment oneGoodArgOneInvalidArg on Dog {\n  doesKnowCommand(whoKnows: 1, dogCommand: SIT, unknown: true)\n}",
+			errors: []ruleError{
+				ruleErr("argument 'whoKnows' is not defined", 2, 19),
+				ruleErr("argument 'unknown' is not defined", 2, 49),
+			},
+		},
+		{
+			name:  "UnknownArgsDeeply",
+			query: "{\n  dog {\n    doesKnowCommand(unknown: true)\n  }\n  human {\n    pets {\n      ... on Dog {\n        doesKnowCommand(unknown: true)\n      }\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("argument 'unknown' is not defined", 3, 21),
+				ruleErr("argument 'unknown' is not defined", 8, 25),
+			},
+		},
+	})
+}
+
+func TestUniqueArgumentNamesRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueArgumentNamesRule, []ruleTest{
+		{
+			name:  "NoArguments",
+			query: "{\n  field\n}",
+		},
+		{
+			name:  "NoArgumentsOnDirective",
+			query: "{\n  field @directive\n}",
+		},
+		{
+			name:  "SameArgumentOnTwoFields",
+			query: "{\n  one: field(arg: \"value\")\n  two: field(arg: \"value\")\n}",
+		},
+		{
+			name:  "SameArgumentOnFieldAndDirective",
+			query: "{\n  field(arg: \"value\") @directive(arg: \"value\")\n}",
+		},
+		{
+			name:  "MultipleFieldArguments",
+			query: "{\n  field(arg1: \"value\", arg2: \"value\", arg3: \"value\")\n}",
+		},
+		{
+			name:  "DuplicateFieldArguments",
+			query: "{\n  field(arg1: \"value\", arg1: \"value\")\n}",
+			errors: []ruleError{
+				ruleErr("argument 'arg1' is set multiple times", 2, 9, 2, 24),
+			},
+		},
+		{
+			name:  "ManyDuplicateFieldArguments",
+			query: "{\n  field(arg1: \"value\", arg1: \"value\", arg1: \"value\")\n}",
+			errors: []ruleError{
+				ruleErr("argument 'arg1' is set multiple times", 2, 9, 2, 24),
+				ruleErr("argument 'arg1' is set multiple times", 2, 9, 2, 39),
+			},
+		},
+		{
+			name:  "DuplicateDirectiveArguments",
+			query: "{\n  field @directive(arg1: \"value\", arg1: \"value\")\n}",
+			errors: []ruleError{
+				ruleErr("argument 'arg1' is set multiple times", 2, 20, 2, 35),
+			},
+		},
+	})
+}
+
+func TestProvidedRequiredArgumentsRule(t *testing.T) {
+	runRuleTests(t, gql.ProvidedRequiredArgumentsRule, []ruleTest{
+		{
+			name:  "IgnoresUnknownArguments",
+			query: "{\n  dog {\n    isHousetrained(unknownArgument: true)\n  }\n}",
+		},
+		{
+			name:  "ArgOnOptionalArg",
+			query: "{\n  dog {\n    isHousetrained(atOtherHomes: true)\n  }\n}",
+		},
+		{
+			name:  "NoArgOnOptionalArg",
+			query: "{\n  dog {\n    isHousetrained\n  }\n}",
+		},
+		{
+			name:  "NoArgOnNonNullFieldWithDefault",
+			query: "{\n  complicatedArgs {\n    nonNullFieldWithDefault\n  }\n}",
+		},
+		{
+			name:  "MultipleArgs",
+			query: "{\n  complicatedArgs {\n    multipleReqs(req1: 1, req2: 2)\n  }\n}",
+		},
+		{
+			name:  "NoArgsOnMultipleOptional",
+			query: "{\n  complicatedArgs {\n    multipleOpts\n  }\n}",
+		},
+		{
+			name:  "AllRequiredAndOptionalArgs",
+			query: "{\n  complicatedArgs {\n    multipleOptAndReq(req1: 3, req2: 4, opt1: 5, opt2: 6)\n  }\n}",
+		},
+		{
+			name:  "MissingOneNonNullableArgument",
+			query: "{\n  complicatedArgs {\n    multipleReqs(req2: 2)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("argument 'req1' is required (NonNull) but not provided", 3, 5),
+			},
+		},
+		{
+			name:  "MissingMultipleNonNullableArguments",
+			query: "{\n  complicatedArgs {\n    multipleReqs\n  }\n}",
+			errors: []ruleError{
+				ruleErr("argument 'req1' is required (NonNull) but not provided", 3, 5),
+				ruleErr("argument 'req2' is required (NonNull) but not provided", 3, 5),
+			},
+		},
+		{
+			name:  "IncorrectValueAndMissingArgument",
+			query: "{\n  complicatedArgs {\n    multipleReqs(req1: \"one\")\n  }\n}",
+			errors: []ruleError{
+				ruleErr("argument 'req2' is required (NonNull) but not provided", 3, 5),
+			},
+		},
+		{
+			name:  "IgnoresUnknownDirectives",
+			query: "{\n  dog @unknown\n}",
+		},
+		{
+			name:  "DirectivesWithValidArguments",
+			query: "{\n  dog @include(if: true) {\n    name\n  }\n  human @skip(if: false) {\n    name\n  }\n}",
+		},
+		{
+			name:  "DirectivesWithMissingArguments",
+			query: "{\n  dog @include {\n    name @skip\n  }\n}",
+			errors: []ruleError{
+				ruleErr("argument 'if' is required (NonNull) but not provided", 2, 7),
+				ruleErr("argument 'if' is required (NonNull) but not provided", 3, 10),
+			},
+		},
+	})
+}
+
+func TestUniqueFragmentNamesRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueFragmentNamesRule, []ruleTest{
+		{
+			name:  "NoFragments",
+			query: "{\n  field\n}",
+		},
+		{
+			name:  "OneFragment",
+			query: "{\n  ...fragA\n}\nfragment fragA on Type {\n  field\n}",
+		},
+		{
+			name:  "ManyFragments",
+			query: "{\n  ...fragA\n  ...fragB\n}\nfragment fragA on Type {\n  fieldA\n}\nfragment fragB on Type {\n  fieldB\n}",
+		},
+		{
+			name:  "InlineFragmentsAreAlwaysUnique",
+			query: "{\n  ...on Type {\n    fieldA\n  }\n  ...on Type {\n    fieldB\n  }\n}",
+		},
+		{
+			name:  "FragmentAndOperationNamedTheSame",
+			query: "query Foo {\n  ...Foo\n}\nfragment Foo on Type {\n  field\n}",
+		},
+		{
+			name:  "FragmentsNamedTheSame",
+			query: "{\n  ...fragA\n}\nfragment fragA on Type {\n  fieldA\n}\nfragment fragA on Type {\n  fieldB\n}",
+			errors: []ruleError{
+				ruleErr("Fragment name 'fragA' is not unique, it's already used", 4, 1, 7, 1),
+			},
+		},
+		{
+			name:  "FragmentsNamedTheSameWithoutBeingReferenced",
+			query: "fragment fragA on Type {\n  fieldA\n}\nfragment fragA on Type {\n  fieldB\n}",
+			errors: []ruleError{
+				ruleErr("Fragment name 'fragA' is not unique, it's already used", 1, 1, 4, 1),
+			},
+		},
+	})
+}
+
+func TestFragmentsOnCompositeTypesRule(t *testing.T) {
+	runRuleTests(t, gql.FragmentsOnCompositeTypesRule, []ruleTest{
+		{
+			name:  "ObjectIsValidFragmentType",
+			query: "fragment validFragment on Dog {\n  barks\n}",
+		},
+		{
+			name:  "InterfaceIsValidFragmentType",
+			query: "fragment validFragment on Pet {\n  name\n}",
+		},
+		{
+			name:  "ObjectIsValidInlineFragmentType",
+			query: "fragment validFragment on Pet {\n  ... on Dog {\n    barks\n  }\n}",
+		},
+		{
+			name:  "InlineFragmentWithoutTypeIsValid",
+			query: "fragment validFragment on Pet {\n  ... {\n    name\n  }\n}",
+		},
+		{
+			name:  "UnionIsValidFragmentType",
+			query: "fragment validFragment on CatOrDog {\n  __typename\n}",
+		},
+		{
+			name:  "ScalarIsInvalidFragmentType",
+			query: "fragment scalarFragment on Boolean {\n  bad\n}",
+			errors: []ruleError{
+				ruleErr("Invalid fragment target 'Boolean' for fragment 'scalarFragment', type is not composite", 1, 1),
+			},
+		},
+		{
+			name:  "EnumIsInvalidFragmentType",
+			query: "fragment scalarFragment on FurColor {\n  bad\n}",
+			errors: []ruleError{
+				ruleErr("Invalid fragment target 'FurColor' for fragment 'scalarFragment', type is not composite", 1, 1),
+			},
+		},
+		{
+			name:  "InputObjectIsInvalidFragmentType",
+			query: "fragment inputFragment on ComplicatedInput {\n  stringField\n}",
+			errors: []ruleError{
+				ruleErr("Invalid fragment target 'ComplicatedInput' for fragment 'inputFragment', type is not composite", 1, 1),
+			},
+		},
+		{
+			name:  "ScalarIsInvalidInlineFragmentType",
+			query: "fragment invalidFragment on Pet {\n  ... on String {\n    barks\n  }\n}",
+			errors: []ruleError{
+				ruleErr("Invalid fragment target 'String' for inline fragment, type is not composite", 2, 3),
+			},
+		},
+		{
+			name:  "UnknownFragmentType",
+			query: "fragment unknownFragment on Unknown {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("Invalid fragment target 'Unknown' for fragment 'unknownFragment', type does not exist", 1, 1),
+			},
+		},
+		{
+			name:  "UnknownInlineFragmentType",
+			query: "fragment validFragment on Pet {\n  ... on Unknown {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fragment's target type (Unknown) is not defined in query", 2, 3),
+			},
+		},
+	})
+}
+
+func TestNoUnusedFragmentsRule(t *testing.T) {
+	runRuleTests(t, gql.NoUnusedFragmentsRule, []ruleTest{
+		{
+			name:  "AllFragmentNamesAreUsed",
+			query: "{\n  human(id: 4) {\n    ...HumanFields1\n    ... on Human {\n      ...HumanFields2\n    }\n  }\n}\nfragment HumanFields1 on Human {\n  name\n  ...HumanFields3\n}\nfragment HumanFields2 on Human {\n  name\n}\nfragment HumanFields3 on Human {\n  name\n}",
+		},
+		{
+			name:  "AllFragmentNamesAreUsedByMultipleOperations",
+			query: "query Foo {\n  human(id: 4) {\n    ...HumanFields1\n  }\n}\nquery Bar {\n  human(id: 4) {\n    ...HumanFields2\n  }\n}\nfragment HumanFields1 on Human {\n  name\n}\nfragment HumanFields2 on Human {\n  name\n}",
+		},
+		{
+			name:  "ContainsUnknownFragments",
+			query: "query Foo {\n  human(id: 4) {\n    ...HumanFields1\n  }\n}\nfragment HumanFields1 on Human {\n  name\n}\nfragment Unused1 on Human {\n  name\n}\nfragment Unused2 on Human {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("fragment 'Unused1' is not used", 9, 1),
+				ruleErr("fragment 'Unused2' is not used", 12, 1),
+			},
+		},
+		{
+			name:  "ContainsUnknownFragmentsWithReferenceCycle",
+			query: "query Foo {\n  human(id: 4) {\n    ...HumanFields1\n  }\n}\nfragment HumanFields1 on Human {\n  name\n}\nfragment Unused1 on Human {\n  ...Unused2\n}\nfragment Unused2 on Human {\n  ...Unused1\n}",
+			errors: []ruleError{
+				ruleErr("fragment 'Unused1' is not used", 9, 1),
+				ruleErr("fragment 'Unused2' is not used", 12, 1),
+			},
+		},
+		{
+			name:  "ContainsUnknownAndUndefinedFragments",
+			query: "query Foo {\n  human(id: 4) {\n    ...bar\n  }\n}\nfragment foo on Human {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("fragment 'foo' is not used", 6, 1),
+			},
+		},
+	})
+}
+
+func TestKnownFragmentNamesRule(t *testing.T) {
+	runRuleTests(t, gql.KnownFragmentNamesRule, []ruleTest{
+		{
+			name:  "KnownFragmentNamesAreValid",
+			query: "{\n  human(id: 4) {\n    ...HumanFields1\n    ... on Human {\n      ...HumanFields2\n    }\n  }\n}\nfragment HumanFields1 on Human {\n  name\n}\nfragment HumanFields2 on Human {\n  name\n}",
+		},
+		{
+			name:  "UnknownFragmentNamesAreInvalid",
+			query: "{\n  human(id: 4) {\n    ...UnknownFragment1\n    ... on Human {\n      ...UnknownFragment2\n    }\n  }\n}\nfragment HumanFields on Human {\n  name\n  ...UnknownFragment3\n}",
+			errors: []ruleError{
+				ruleErr("fragment 'UnknownFragment1' is not defined in query", 3, 5),
+				ruleErr("fragment 'UnknownFragment2' is not defined in query", 5, 7),
+				ruleErr("fragment 'UnknownFragment3' is not defined in query", 11, 3),
+			},
+		},
+	})
+}
+
+func TestNoFragmentCyclesRule(t *testing.T) {
+	runRuleTests(t, gql.NoFragmentCyclesRule, []ruleTest{
+		{
+			name:  "SingleReference",
+			query: "fragment fragA on Dog {\n  ...fragB\n}\nfragment fragB on Dog {\n  name\n}",
+		},
+		{
+			name:  "SpreadingTwice",
+			query: "fragment fragA on Dog {\n  ...fragB\n  ...fragB\n}\nfragment fragB on Dog {\n  name\n}",
+		},
+		{
+			name:  "SpreadingTwiceIndirectly",
+			query: "fragment fragA on Dog {\n  ...fragB\n  ...fragC\n}\nfragment fragB on Dog {\n  ...fragC\n}\nfragment fragC on Dog {\n  name\n}",
+		},
+		{
+			name:  "DoubleSpreadWithinAbstractTypes",
+			query: "fragment nameFragment on Pet {\n  ... on Dog {\n    name\n  }\n  ... on Cat {\n    name\n  }\n}\nfragment spreadsInAnon on Pet {\n  ... on Dog {\n    ...nameFragment\n  }\n  ... on Cat {\n    ...nameFragment\n  }\n}",
+		},
+		{
+			name:  "UnknownFragment",
+			query: "fragment nameFragment on Pet {\n  ...UnknownFragment\n}",
+		},
+		{
+			name:  "SpreadingRecursivelyWithinFieldFails",
+			query: "fragment fragA on Human {\n  relatives {\n    ...fragA\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 3, 5),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfDirectly",
+			query: "fragment fragA on Dog {\n  ...fragA\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 2, 3),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfDirectlyWithinInlineFragment",
+			query: "fragment fragA on Pet {\n  ... on Dog {\n    ...fragA\n  }\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 3, 5),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfIndirectly",
+			query: "fragment fragA on Dog {\n  ...fragB\n}\nfragment fragB on Dog {\n  ...fragA\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 2, 3, 5, 3),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfIndirectlyReportsOppositeOrder",
+			query: "fragment fragB on Dog {\n  ...fragA\n}\nfragment fragA on Dog {\n  ...fragB\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragB'", 2, 3, 5, 3),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfDeeply",
+			query: "fragment fragA on Dog {\n  ...fragB\n}\nfragment fragB on Dog {\n  ...fragC\n}\nfragment fragC on Dog {\n  ...fragA\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 2, 3, 5, 3, 8, 3),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfDeeplyTwoPaths",
+			query: "fragment fragA on Dog {\n  ...fragB\n  ...fragC\n}\nfragment fragB on Dog {\n  ...fragA\n}\nfragment fragC on Dog {\n  ...fragA\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragA'", 2, 3, 6, 3),
+				ruleErr("fragment cycle detected for fragment 'fragA'", 3, 3, 9, 3),
+			},
+		},
+		{
+			name:  "NoSpreadingItselfDeeplyAndImmediately",
+			query: "fragment fragA on Dog {\n  ...fragB\n}\nfragment fragB on Dog {\n  ...fragB\n  ...fragC\n}\nfragment fragC on Dog {\n  ...fragA\n  ...fragB\n}",
+			errors: []ruleError{
+				ruleErr("fragment cycle detected for fragment 'fragB'", 5, 3),
+				ruleErr("fragment cycle detected for fragment 'fragA'", 2, 3, 6, 3, 9, 3),
+				ruleErr("fragment cycle detected for fragment 'fragB'", 6, 3, 10, 3),
+			},
+		},
+	})
+}
+
+func TestPossibleFragmentSpreadsRule(t *testing.T) {
+	runRuleTests(t, gql.PossibleFragmentSpreadsRule, []ruleTest{
+		{
+			name:  "OfTheSameObject",
+			query: "fragment objectWithinObject on Dog {\n  ...dogFragment\n}\nfragment dogFragment on Dog {\n  barkVolume\n}",
+		},
+		{
+			name:  "OfTheSameObjectWithInlineFragment",
+			query: "fragment objectWithinObjectAnon on Dog {\n  ... on Dog {\n    barkVolume\n  }\n}",
+		},
+		{
+			name:  "ObjectIntoAnImplementedInterface",
+			query: "fragment objectWithinInterface on Pet {\n  ...dogFragment\n}\nfragment dogFragment on Dog {\n  barkVolume\n}",
+		},
+		{
+			name:  "ObjectIntoContainingUnion",
+			query: "fragment objectWithinUnion on CatOrDog {\n  ...dogFragment\n}\nfragment dogFragment on Dog {\n  barkVolume\n}",
+		},
+		{
+			name:  "UnionIntoContainedObject",
+			query: "fragment unionWithinObject on Dog {\n  ...catOrDogFragment\n}\nfragment catOrDogFragment on CatOrDog {\n  __typename\n}",
+		},
+		{
+			name:  "UnionIntoOverlappingInterface",
+			query: "fragment unionWithinInterface on Pet {\n  ...catOrDogFragment\n}\nfragment catOrDogFragment on CatOrDog {\n  __typename\n}",
+		},
+		{
+			name:  "UnionIntoOverlappingUnion",
+			query: "fragment unionWithinUnion on DogOrHuman {\n  ...catOrDogFragment\n}\nfragment catOrDogFragment on CatOrDog {\n  __typename\n}",
+		},
+		{
+			name:  "InterfaceIntoImplementedObject",
+			query: "fragment interfaceWithinObject on Dog {\n  ...petFragment\n}\nfragment petFragment on Pet {\n  name\n}",
+		},
+		{
+			name:  "InterfaceIntoOverlappingUnion",
+			query: "fragment interfaceWithinUnion on CatOrDog {\n  ...petFragment\n}\nfragment petFragment on Pet {\n  name\n}",
+		},
+		{
+			name:  "IgnoresIncorrectType",
+			query: "fragment petFragment on Pet {\n  ...badInADifferentWay\n}\nfragment badInADifferentWay on String {\n  name\n}",
+		},
+		{
+			name:  "IgnoresUnknownFragments",
+			query: "fragment petFragment on Pet {\n  ...UnknownFragment\n}",
+		},
+		{
+			name:  "DifferentObjectIntoObject",
+			query: "fragment invalidObjectWithinObject on Cat {\n  ...dogFragment\n}\nfragment dogFragment on Dog {\n  barkVolume\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'dogFragment' spread on type 'Cat'", 2, 3),
+			},
+		},
+		{
+			name:  "DifferentObjectIntoObjectWithInlineFragment",
+			query: "fragment invalidObjectWithinObjectAnon on Cat {\n  ... on Dog {\n    barkVolume\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid use of inline fragment on type 'Cat': target does not match", 2, 3),
+			},
+		},
+		{
+			name:  "ObjectIntoNotImplementingInterface",
+			query: "fragment invalidObjectWithinInterface on Pet {\n  ...humanFragment\n}\nfragment humanFragment on Human {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'humanFragment' spread on type 'Pet'", 2, 3),
+			},
+		},
+		{
+			name:  "ObjectIntoNotContainingUnion",
+			query: "fragment invalidObjectWithinUnion on CatOrDog {\n  ...humanFragment\n}\nfragment humanFragment on Human {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'humanFragment' spread on type 'CatOrDog'", 2, 3),
+			},
+		},
+		{
+			name:  "UnionIntoNotContainedObject",
+			query: "fragment invalidUnionWithinObject on Human {\n  ...catOrDogFragment\n}\nfragment catOrDogFragment on CatOrDog {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'catOrDogFragment' spread on type 'Human'", 2, 3),
+			},
+		},
+		{
+			name:  "UnionIntoNonOverlappingInterface",
+			query: "fragment invalidUnionWithinInterface on Pet {\n  ...humanOrAlienFragment\n}\nfragment humanOrAlienFragment on HumanOrAlien {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'humanOrAlienFragment' spread on type 'Pet'", 2, 3),
+			},
+		},
+		{
+			name:  "UnionIntoNonOverlappingUnion",
+			query: "fragment invalidUnionWithinUnion on CatOrDog {\n  ...humanOrAlienFragment\n}\nfragment humanOrAlienFragment on HumanOrAlien {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'humanOrAlienFragment' spread on type 'CatOrDog'", 2, 3),
+			},
+		},
+		{
+			name:  "InterfaceIntoNonImplementingObject",
+			query: "fragment invalidInterfaceWithinObject on Cat {\n  ...sentientFragment\n}\nfragment sentientFragment on Sentient {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'sentientFragment' spread on type 'Cat'", 2, 3),
+			},
+		},
+		{
+			name:  "InterfaceIntoNonOverlappingInterface",
+			query: "fragment invalidInterfaceWithinInterface on Pet {\n  ...sentientFragment\n}\nfragment sentientFragment on Sentient {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'sentientFragment' spread on type 'Pet'", 2, 3),
+			},
+		},
+		{
+			name:  "InterfaceIntoNonOverlappingInterfaceWithInlineFragment",
+			query: "fragment invalidInterfaceWithinInterfaceAnon on Pet {\n  ... on Sentient {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid use of inline fragment on type 'Pet': target does not match", 2, 3),
+			},
+		},
+		{
+			name:  "InterfaceIntoNonOverlappingUnion",
+			query: "fragment invalidInterfaceWithinUnion on HumanOrAlien {\n  ...petFragment\n}\nfragment petFragment on Pet {\n  name\n}",
+			errors: []ruleError{
+				ruleErr("cannot use 'petFragment' spread on type 'HumanOrAlien'", 2, 3),
+			},
+		},
+	})
+}
+
+func TestValuesOfCorrectTypeRule(t *testing.T) {
+	runRuleTests(t, gql.ValuesOfCorrectTypeRule, []ruleTest{
+		{
+			name:  "GoodIntValue",
+			query: "{\n  complicatedArgs {\n    intArgField(intArg: 2)\n  }\n}",
+		},
+		{
+			name:  "GoodNegativeIntValue",
+			query: "{\n  complicatedArgs {\n    intArgField(intArg: -2)\n  }\n}",
+		},
+		{
+			name:  "GoodBooleanValue",
+			query: "{\n  complicatedArgs {\n    booleanArgField(booleanArg: true)\n  }\n}",
+		},
+		{
+			name:  "GoodStringValue",
+			query: "{\n  complicatedArgs {\n    stringArgField(stringArg: \"foo\")\n  }\n}",
+		},
+		{
+			name:  "GoodFloatValue",
+			query: "{\n  complicatedArgs {\n    floatArgField(floatArg: 1.1)\n  }\n}",
+		},
+		{
+			name:  "IntIntoFloat",
+			query: "{\n  complicatedArgs {\n    floatArgField(floatArg: 1)\n  }\n}",
+		},
+		{
+			name:  "IntIntoID",
+			query: "{\n  complicatedArgs {\n    idArgField(idArg: 1)\n  }\n}",
+		},
+		{
+			name:  "StringIntoID",
+			query: "{\n  complicatedArgs {\n    idArgField(idArg: \"someIdString\")\n  }\n}",
+		},
+		{
+			name:  "GoodEnumValue",
+			query: "{\n  dog {\n    doesKnowCommand(dogCommand: SIT)\n  }\n}",
+		},
+		{
+			name:  "NullIntoNullableType",
+			query: "{\n  complicatedArgs {\n    intArgField(intArg: null)\n  }\n}",
+		},
+		{
+			name:  "Variables",
+			query: "query Q($a: Int) {\n  complicatedArgs {\n    intArgField(intArg: $a)\n  }\n}",
+		},
+		{
+			name:  "IntIntoString",
+			query: "{\n  complicatedArgs {\n    stringArgField(stringArg: 1)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for String scalar", 3, 31),
+			},
+		},
+		{
+			name:  "BooleanIntoString",
+			query: "{\n  complicatedArgs {\n    stringArgField(stringArg: true)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for String scalar", 3, 31),
+			},
+		},
+		{
+			name:  "UnquotedStringIntoString",
+			query: "{\n  complicatedArgs {\n    stringArgField(stringArg: BAR)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for String scalar", 3, 31),
+			},
+		},
+		{
+			name:  "StringIntoInt",
+			query: "{\n  complicatedArgs {\n    intArgField(intArg: \"3\")\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for Int scalar", 3, 25),
+			},
+		},
+		{
+			name:  "FloatIntoInt",
+			query: "{\n  complicatedArgs {\n    intArgField(intArg: 3.0)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for Int scalar", 3, 25),
+			},
+		},
+		{
+			name:  "StringIntoEnum",
+			query: "{\n  dog {\n    doesKnowCommand(dogCommand: \"SIT\")\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value for Enum", 3, 33),
+			},
+		},
+		{
+			name:  "UnknownEnumValue",
+			query: "{\n  dog {\n    doesKnowCommand(dogCommand: JUGGLE)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid enum value 'JUGGLE'", 3, 33),
+			},
+		},
+		{
+			name:  "DifferentCaseEnumValue",
+			query: "{\n  dog {\n    doesKnowCommand(dogCommand: sit)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid enum value 'sit'. Did you mean 'SIT'?", 3, 33),
+			},
+		},
+		{
+			name:  "NullIntoNonNullType",
+			query: "{\n  complicatedArgs {\n    nonNullIntArgField(nonNullIntArg: null)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("null value provided for NonNull type", 3, 39),
+			},
+		},
+		{
+			name:  "GoodListValue",
+			query: "{\n  complicatedArgs {\n    stringListArgField(stringListArg: [\"one\", null, \"two\"])\n  }\n}",
+		},
+		{
+			name:  "SingleValueIntoList",
+			query: "{\n  complicatedArgs {\n    stringListArgField(stringListArg: \"one\")\n  }\n}",
+		},
+		{
+			name:  "IncorrectListItemType",
+			query: "{\n  complicatedArgs {\n    stringListArgField(stringListArg: [\"one\", 2])\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for String scalar", 3, 47),
+			},
+		},
+		{
+			name:  "NullListItemForNonNullItemType",
+			query: "{\n  complicatedArgs {\n    stringListNonNullArgField(stringListNonNullArg: [\"one\", null])\n  }\n}",
+			errors: []ruleError{
+				ruleErr("null value provided for NonNull type", 3, 61),
+			},
+		},
+		{
+			name:  "ObjectWithRequiredField",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true })\n  }\n}",
+		},
+		{
+			name:  "ObjectWithAllFields",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: {\n      requiredField: true\n      nonNullField: true\n      intField: 4\n      stringField: \"foo\"\n      booleanField: false\n      stringListField: [\"one\", \"two\"]\n    })\n  }\n}",
+		},
+		{
+			name:  "ObjectMissingRequiredField",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: { intField: 4 })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("no value provided for field 'requiredField' with NonNull type", 3, 33),
+			},
+		},
+		{
+			name:  "ObjectWithIncorrectFieldValue",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: {\n      stringListField: [\"one\", 2]\n      requiredField: true\n    })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for String scalar", 4, 32),
+			},
+		},
+		{
+			name:  "ObjectWithNullForRequiredField",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true, nonNullField: null })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("null value provided for NonNull type", 3, 70),
+			},
+		},
+		{
+			name:  "ObjectWithUnknownField",
+			query: "{\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true, invalidField: \"value\" })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("field 'invalidField' is not defined. Did you mean 'intField'?", 3, 56),
+			},
+		},
+		{
+			name:  "DirectiveArguments",
+			query: "{\n  dog @include(if: true) {\n    name\n  }\n  human @skip(if: false) {\n    name\n  }\n}",
+		},
+		{
+			name:  "IncorrectDirectiveArgument",
+			query: "{\n  dog @include(if: \"yes\") {\n    name @skip(if: ENUM)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for Boolean scalar", 2, 20),
+				ruleErr("invalid value type for Boolean scalar", 3, 20),
+			},
+		},
+		{
+			name:  "VariablesWithValidDefaultValues",
+			query: "query WithDefaultValues(\n  $a: Int = 1,\n  $b: String = \"ok\",\n  $c: ComplicatedInput = { requiredField: true, intField: 3 }\n  $d: Int! = 123\n) {\n  dog {\n    name\n  }\n}",
+		},
+		{
+			name:  "GoodOneOfValue",
+			query: "{\n  complicatedArgs {\n    oneOfArgField(oneOfArg: { stringField: \"abc\" })\n  }\n}",
+		},
+		{
+			name:  "OneOfWithMultipleFields",
+			query: "{\n  complicatedArgs {\n    oneOfArgField(oneOfArg: { stringField: \"abc\", intField: 123 })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("exactly one field must be provided for OneOf input object 'OneOfInput'", 3, 29),
+			},
+		},
+		{
+			name:  "OneOfWithNoFields",
+			query: "{\n  complicatedArgs {\n    oneOfArgField(oneOfArg: {})\n  }\n}",
+			errors: []ruleError{
+				ruleErr("exactly one field must be provided for OneOf input object 'OneOfInput'", 3, 29),
+			},
+		},
+		{
+			name:  "OneOfWithNullField",
+			query: "{\n  complicatedArgs {\n    oneOfArgField(oneOfArg: { stringField: null })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("field 'stringField' of OneOf input object 'OneOfInput' must not be null", 3, 44),
+			},
+		},
+		{
+			name:  "VariablesWithInvalidDefaultValues",
+			query: "query InvalidDefaultValues(\n  $a: Int = \"one\",\n  $b: String = 4,\n  $c: ComplicatedInput = \"NotVeryComplex\"\n) {\n  dog {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("invalid value type for Int scalar", 2, 13),
+				ruleErr("invalid value type for String scalar", 3, 16),
+				ruleErr("invalid value for InputObject", 4, 26),
+			},
+		},
+	})
+}
+
+func TestUniqueInputFieldNamesRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueInputFieldNamesRule, []ruleTest{
+		{
+			name:  "InputObjectWithFields",
+			query: "{\n  field(arg: { f: true })\n}",
+		},
+		{
+			name:  "SameInputObjectWithinTwoArgs",
+			query: "{\n  field(arg1: { f: true }, arg2: { f: true })\n}",
+		},
+		{
+			name:  "MultipleInputObjectFields",
+			query: "{\n  field(arg: { f1: \"value\", f2: \"value\", f3: \"value\" })\n}",
+		},
+		{
+			name:  "AllowsForNestedInputObjectsWithSimilarFields",
+			query: "{\n  field(arg: {\n    deep: {\n      deep: {\n        id: 1\n      }\n      id: 1\n    }\n    id: 1\n  })\n}",
+		},
+		{
+			name:  "DuplicateInputObjectFields",
+			query: "{\n  field(arg: { f1: \"value\", f1: \"value\" })\n}",
+			errors: []ruleError{
+				ruleErr("field 'f1' was set multiple times", 2, 16, 2, 29),
+			},
+		},
+		{
+			name:  "NestedDuplicateInputObjectFields",
+			query: "{\n  field(arg: { f1: {f2: \"value\", f2: \"value\" }})\n}",
+			errors: []ruleError{
+				ruleErr("field 'f2' was set multiple times", 2, 21, 2, 34),
+			},
+		},
+	})
+}
+
+func TestKnownDirectivesRule(t *testing.T) {
+	runRuleTests(t, gql.KnownDirectivesRule, []ruleTest{
+		{
+			name:  "NoDirectives",
+			query: "query Foo {\n  name\n  ...Frag\n}\nfragment Frag on Dog {\n  name\n}",
+		},
+		{
+			name:  "KnownDirectives",
+			query: "{\n  dog @include(if: true) {\n    name\n  }\n  human @skip(if: false) {\n    name\n  }\n}",
+		},
+		{
+			name:  "UnknownDirective",
+			query: "{\n  dog @unknown(directive: \"value\") {\n    name\n  }\n}",
+			errors: []ruleError{
+				ruleErr("directive 'unknown' is not defined", 2, 7),
+			},
+		},
+		{
+			name:  "ManyUnknownDirectives",
+			query: "{\n  dog @unknown(directive: \"value\") {\n    name\n  }\n  human @unknown(directive: \"value\") {\n    name\n    pets @unknown(directive: \"value\") {\n      name\n    }\n  }\n}",
+			errors: []ruleError{
+				ruleErr("directive 'unknown' is not defined", 2, 7),
+				ruleErr("directive 'unknown' is not defined", 5, 9),
+				ruleErr("directive 'unknown' is not defined", 7, 10),
+			},
+		},
+		{
+			name:  "WellPlacedDirectives",
+			query: "{\n  dog @include(if: true) {\n    ...Frag @skip(if: false)\n    ... on Dog @include(if: true) {\n      name\n    }\n  }\n}",
+		},
+		{
+			name:  "MisplacedDirectives",
+			query: "query Foo @include(if: true) {\n  name\n}\nfragment Frag on Dog @skip(if: true) {\n  name @deprecated\n}",
+			errors: []ruleError{
+				ruleErr("directive 'include' is on invalid location", 1, 11),
+				ruleErr("directive 'skip' is on invalid location", 4, 22),
+				ruleErr("directive 'deprecated' is on invalid location", 5, 8),
+			},
+		},
+	})
+}
+
+func TestUniqueDirectivesPerLocationRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueDirectivesPerLocationRule, []ruleTest{
+		{
+			name:  "NoDirectives",
+			query: "fragment Test on Type {\n  field\n}",
+		},
+		{
+			name:  "UniqueDirectivesInDifferentLocations",
+			query: "fragment Test on Type @directiveA {\n  field @directiveB\n}",
+		},
+		{
+			name:  "UniqueDirectivesInSameLocations",
+			query: "fragment Test on Type @directiveA @directiveB {\n  field @directiveA @directiveB\n}",
+		},
+		{
+			name:  "SameDirectivesInDifferentLocations",
+			query: "fragment Test on Type @directiveA {\n  field @directiveA\n}",
+		},
+		{
+			name:  "SameDirectivesInSimilarLocations",
+			query: "fragment Test on Type {\n  field @directive\n  field @directive\n}",
+		},
+		{
+			name:  "DuplicateDirectivesInOneLocation",
+			query: "fragment Test on Type {\n  field @directive @directive\n}",
+			errors: []ruleError{
+				ruleErr("directive 'directive' is not unique per location", 2, 9, 2, 20),
+			},
+		},
+		{
+			name:  "ManyDuplicateDirectivesInOneLocation",
+			query: "fragment Test on Type {\n  field @directive @directive @directive\n}",
+			errors: []ruleError{
+				ruleErr("directive 'directive' is not unique per location", 2, 9, 2, 20),
+				ruleErr("directive 'directive' is not unique per location", 2, 9, 2, 31),
+			},
+		},
+		{
+			name:  "DifferentDuplicateDirectivesInOneLocation",
+			query: "fragment Test on Type {\n  field @directiveA @directiveB @directiveA @directiveB\n}",
+			errors: []ruleError{
+				ruleErr("directive 'directiveA' is not unique per location", 2, 9, 2, 33),
+				ruleErr("directive 'directiveB' is not unique per location", 2, 21, 2, 45),
+			},
+		},
+		{
+			name:  "DuplicateDirectivesInManyLocations",
+			query: "query Q @directive @directive {\n  ...Test\n}\nfragment Test on Type @directive @directive {\n  field @directive @directive\n  ... @directive @directive {\n    field\n  }\n  ...Other @directive @directive\n}",
+			errors: []ruleError{
+				ruleErr("directive 'directive' is not unique per location", 1, 9, 1, 20),
+				ruleErr("directive 'directive' is not unique per location", 4, 23, 4, 34),
+				ruleErr("directive 'directive' is not unique per location", 5, 9, 5, 20),
+				ruleErr("directive 'directive' is not unique per location", 6, 7, 6, 18),
+				ruleErr("directive 'directive' is not unique per location", 9, 12, 9, 23),
+			},
+		},
+	})
+}
+
+func TestUniqueVariableNamesRule(t *testing.T) {
+	runRuleTests(t, gql.UniqueVariableNamesRule, []ruleTest{
+		{
+			name:  "UniqueVariableNames",
+			query: "query A($x: Int, $y: String) {\n  __typename\n}\nquery B($x: String, $y: Int) {\n  __typename\n}",
+		},
+		{
+			name:  "DuplicateVariableNames",
+			query: "query A($x: Int, $x: Int, $x: String) {\n  __typename\n}\nquery B($x: String, $x: Int) {\n  __typename\n}\nquery C($x: Int, $x: Int) {\n  __typename\n}",
+			errors: []ruleError{
+				ruleErr("variable 'x' is defined multiple times", 1, 9, 1, 18),
+				ruleErr("variable 'x' is defined multiple times", 1, 9, 1, 27),
+				ruleErr("variable 'x' is defined multiple times", 4, 9, 4, 21),
+				ruleErr("variable 'x' is defined multiple times", 7, 9, 7, 18),
+			},
+		},
+	})
+}
+
+func TestVariablesAreInputTypesRule(t *testing.T) {
+	runRuleTests(t, gql.VariablesAreInputTypesRule, []ruleTest{
+		{
+			name:  "InputTypesAreValid",
+			query: "query Foo($a: String, $b: [Boolean!]!, $c: ComplicatedInput) {\n  field(a: $a, b: $b, c: $c)\n}",
+		},
+		{
+			name:  "OutputTypesAreInvalid",
+			query: "query Foo($a: Dog, $b: [[CatOrDog!]]!, $c: Pet) {\n  field(a: $a, b: $b, c: $c)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'a' is not an input type", 1, 11),
+				ruleErr("variable 'b' is not an input type", 1, 20),
+				ruleErr("variable 'c' is not an input type", 1, 40),
+			},
+		},
+		{
+			name:  "UnknownTypesAreInvalid",
+			query: "query Foo($a: Unknown) {\n  field(a: $a)\n}",
+			errors: []ruleError{
+				ruleErr("Unknown type 'Unknown'.", 1, 15),
+			},
+		},
+	})
+}
+
+func TestNoUndefinedVariablesRule(t *testing.T) {
+	runRuleTests(t, gql.NoUndefinedVariablesRule, []ruleTest{
+		{
+			name:  "AllVariablesDefined",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  field(a: $a, b: $b, c: $c)\n}",
+		},
+		{
+			name:  "AllVariablesDeeplyDefined",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  field(a: $a) {\n    field(b: $b) {\n      field(c: $c)\n    }\n  }\n}",
+		},
+		{
+			name:  "AllVariablesDeeplyDefinedInInlineFragments",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  ... on Type {\n    field(a: $a) {\n      field(b: $b) {\n        ... on Type {\n          field(c: $c)\n        }\n      }\n    }\n  }\n}",
+		},
+		{
+			name:  "AllVariablesInFragmentsDeeplyDefined",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  ...FragA\n}\nfragment FragA on Type {\n  field(a: $a) {\n    ...FragB\n  }\n}\nfragment FragB on Type {\n  field(b: $b) {\n    ...FragC\n  }\n}\nfragment FragC on Type {\n  field(c: $c)\n}",
+		},
+		{
+			name:  "VariableWithinRecursiveFragmentDefined",
+			query: "query Foo($a: String) {\n  ...FragA\n}\nfragment FragA on Type {\n  field(a: $a) {\n    ...FragA\n  }\n}",
+		},
+		{
+			name:  "VariableNotDefined",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  field(a: $a, b: $b, c: $c, d: $d)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'd' is not defined", 2, 33),
+			},
+		},
+		{
+			name:  "VariableNotDefinedByAnonymousQuery",
+			query: "{\n  field(a: $a)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'a' is not defined", 2, 12),
+			},
+		},
+		{
+			name:  "VariableInFragmentNotDefinedByOperation",
+			query: "query Foo($a: String, $b: String) {\n  ...FragA\n}\nfragment FragA on Type {\n  field(a: $a) {\n    ...FragB\n  }\n}\nfragment FragB on Type {\n  field(b: $b) {\n    ...FragC\n  }\n}\nfragment FragC on Type {\n  field(c: $c)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'c' is not defined", 15, 12),
+			},
+		},
+		{
+			name:  "VariablesInFragmentNotDefinedByMultipleOperations",
+			query: "query Foo($b: String) {\n  ...FragAB\n}\nquery Bar($a: String) {\n  ...FragAB\n}\nfragment FragAB on Type {\n  field1(a: $a, b: $b)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'a' is not defined", 8, 13),
+				ruleErr("variable 'b' is not defined", 8, 20),
+			},
+		},
+	})
+}
+
+func TestNoUnusedVariablesRule(t *testing.T) {
+	runRuleTests(t, gql.NoUnusedVariablesRule, []ruleTest{
+		{
+			name:  "UsesAllVariables",
+			query: "query ($a: String, $b: String, $c: String) {\n  field(a: $a, b: $b, c: $c)\n}",
+		},
+		{
+			name:  "UsesAllVariablesDeeply",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  field(a: $a) {\n    field(b: $b) {\n      field(c: $c)\n    }\n  }\n}",
+		},
+		{
+			name:  "UsesAllVariablesInFragments",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  ...FragA\n}\nfragment FragA on Type {\n  field(a: $a) {\n    ...FragB\n  }\n}\nfragment FragB on Type {\n  field(b: $b) {\n    ...FragC\n  }\n}\nfragment FragC on Type {\n  field(c: $c)\n}",
+		},
+		{
+			name:  "UsesVariablesInDirectivesAndObjects",
+			query: "query Foo($a: Boolean, $b: String) {\n  field(arg: { list: [$b] }) @include(if: $a)\n}",
+		},
+		{
+			name:  "VariableNotUsed",
+			query: "query ($a: String, $b: String, $c: String) {\n  field(a: $a, b: $b)\n}",
+			errors: []ruleError{
+				ruleErr("Variable defined but not used", 1, 32),
+			},
+		},
+		{
+			name:  "MultipleVariablesNotUsed",
+			query: "query Foo($a: String, $b: String, $c: String) {\n  field(b: $b)\n}",
+			errors: []ruleError{
+				ruleErr("Variable defined but not used", 1, 11),
+				ruleErr("Variable defined but not used", 1, 35),
+			},
+		},
+		{
+			name:  "VariableNotUsedByFragments",
+			query: "query Foo($a: String, $b: String) {\n  ...FragA\n}\nfragment FragA on Type {\n  field(a: $a)\n}\nfragment FragB on Type {\n  field(b: $b)\n}",
+			errors: []ruleError{
+				ruleErr("Variable defined but not used", 1, 23),
+			},
+		},
+		{
+			name:  "VariableNotUsedByMultipleOperations",
+			query: "query Foo($b: String) {\n  ...FragA\n}\nquery Bar($a: String) {\n  ...FragB\n}\nfragment FragA on Type {\n  field(a: $a)\n}\nfragment FragB on Type {\n  field(b: $b)\n}",
+			errors: []ruleError{
+				ruleErr("Variable defined but not used", 1, 11),
+				ruleErr("Variable defined but not used", 4, 11),
+			},
+		},
+	})
+}
+
+func TestVariablesInAllowedPositionRule(t *testing.T) {
+	runRuleTests(t, gql.VariablesInAllowedPositionRule, []ruleTest{
+		{
+			name:  "BooleanIntoBoolean",
+			query: "query Query($booleanArg: Boolean) {\n  complicatedArgs {\n    booleanArgField(booleanArg: $booleanArg)\n  }\n}",
+		},
+		{
+			name:  "BooleanIntoBooleanWithinFragment",
+			query: "fragment booleanArgFrag on ComplicatedArgs {\n  booleanArgField(booleanArg: $booleanArg)\n}\nquery Query($booleanArg: Boolean) {\n  complicatedArgs {\n    ...booleanArgFrag\n  }\n}",
+		},
+		{
+			name:  "NonNullBooleanIntoBoolean",
+			query: "query Query($nonNullBooleanArg: Boolean!) {\n  complicatedArgs {\n    booleanArgField(booleanArg: $nonNullBooleanArg)\n  }\n}",
+		},
+		{
+			name:  "ListIntoList",
+			query: "query Query($stringListVar: [String]) {\n  complicatedArgs {\n    stringListArgField(stringListArg: $stringListVar)\n  }\n}",
+		},
+		{
+			name:  "NonNullListItemsIntoList",
+			query: "query Query($stringListVar: [String!]) {\n  complicatedArgs {\n    stringListArgField(stringListArg: $stringListVar)\n  }\n}",
+		},
+		{
+			name:  "StringIntoListItem",
+			query: "query Query($stringVar: String) {\n  complicatedArgs {\n    stringListArgField(stringListArg: [$stringVar])\n  }\n}",
+		},
+		{
+			name:  "NonNullStringIntoListItem",
+			query: "query Query($stringVar: String!) {\n  complicatedArgs {\n    stringListArgField(stringListArg: [$stringVar])\n  }\n}",
+		},
+		{
+			name:  "InputObjectIntoInputObject",
+			query: "query Query($complexVar: ComplicatedInput) {\n  complicatedArgs {\n    complexArgField(complexArg: $complexVar)\n  }\n}",
+		},
+		{
+			name:  "ListIntoInputObjectField",
+			query: "query Query($stringListVar: [String]) {\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true, stringListField: $stringListVar })\n  }\n}",
+		},
+		{
+			name:  "NonNullBooleanIntoDirective",
+			query: "query Query($boolVar: Boolean!) {\n  dog @include(if: $boolVar)\n}",
+		},
+		{
+			name:  "NullableWithDefaultIntoNonNull",
+			query: "query Query($intVar: Int = 1) {\n  complicatedArgs {\n    nonNullIntArgField(nonNullIntArg: $intVar)\n  }\n}",
+		},
+		{
+			name:  "NullableIntoNonNullArgumentWithDefault",
+			query: "query Query($intVar: Int) {\n  complicatedArgs {\n    nonNullFieldWithDefault(arg: $intVar)\n  }\n}",
+		},
+		{
+			name:  "NullableIntoNonNullInputFieldWithDefault",
+			query: "query Query($boolVar: Boolean) {\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true, nonNullField: $boolVar })\n  }\n}",
+		},
+		{
+			name:  "NullableWithDefaultIntoNonNullDirective",
+			query: "query Query($boolVar: Boolean = false) {\n  dog @include(if: $boolVar)\n}",
+		},
+		{
+			name:  "IntIntoNonNullInt",
+			query: "query Query($intArg: Int) {\n  complicatedArgs {\n    nonNullIntArgField(nonNullIntArg: $intArg)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'intArg' is not allowed to use", 3, 39),
+			},
+		},
+		{
+			name:  "IntIntoNonNullIntWithinFragment",
+			query: "fragment nonNullIntArgFieldFrag on ComplicatedArgs {\n  nonNullIntArgField(nonNullIntArg: $intArg)\n}\nquery Query($intArg: Int) {\n  complicatedArgs {\n    ...nonNullIntArgFieldFrag\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'intArg' is not allowed to use", 2, 37),
+			},
+		},
+		{
+			name:  "IntWithNullDefaultIntoNonNullInt",
+			query: "query Query($intVar: Int = null) {\n  complicatedArgs {\n    nonNullIntArgField(nonNullIntArg: $intVar)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'intVar' is not allowed to use", 3, 39),
+			},
+		},
+		{
+			name:  "StringIntoBoolean",
+			query: "query Query($stringVar: String) {\n  complicatedArgs {\n    booleanArgField(booleanArg: $stringVar)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'stringVar' is not allowed to use", 3, 33),
+			},
+		},
+		{
+			name:  "StringIntoList",
+			query: "query Query($stringVar: String) {\n  complicatedArgs {\n    stringListArgField(stringListArg: $stringVar)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'stringVar' is not allowed to use", 3, 39),
+			},
+		},
+		{
+			name:  "BooleanIntoNonNullBooleanInDirective",
+			query: "query Query($boolVar: Boolean) {\n  dog @include(if: $boolVar)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'boolVar' is not allowed to use", 2, 20),
+			},
+		},
+		{
+			name:  "StringIntoNonNullBooleanInDirective",
+			query: "query Query($stringVar: String) {\n  dog @include(if: $stringVar)\n}",
+			errors: []ruleError{
+				ruleErr("variable 'stringVar' is not allowed to use", 2, 20),
+			},
+		},
+		{
+			name:  "ListIntoNonNullItemList",
+			query: "query Query($stringListVar: [String]) {\n  complicatedArgs {\n    stringListNonNullArgField(stringListNonNullArg: $stringListVar)\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'stringListVar' is not allowed to use", 3, 53),
+			},
+		},
+		{
+			name:  "NullableIntoRequiredInputField",
+			query: "query Query($boolVar: Boolean) {\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: $boolVar })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'boolVar' is not allowed to use", 3, 50),
+			},
+		},
+		{
+			name:  "NonNullIntoOneOfField",
+			query: "query Query($stringVar: String!) {\n  complicatedArgs {\n    oneOfArgField(oneOfArg: { stringField: $stringVar })\n  }\n}",
+		},
+		{
+			name:  "NullableIntoOneOfField",
+			query: "query Query($stringVar: String) {\n  complicatedArgs {\n    oneOfArgField(oneOfArg: { stringField: $stringVar })\n  }\n}",
+			errors: []ruleError{
+				ruleErr("variable 'stringVar' must be non-nullable to be used for OneOf input object 'OneOfInput'", 3, 44),
+			},
+		},
+	})
+}
@@ -2,9 +2,14 @@ package gql_test
 
 import (
 	"context"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/rigglo/gql"
+	"github.com/rigglo/gql/pkg/language/ast"
+	"github.com/rigglo/gql/pkg/language/parser"
+	"github.com/rigglo/gql/pkg/language/visitor"
 	"github.com/rigglo/gql/pkg/testutil"
 )
 
@@ -2092,3 +2097,317 @@ func Test_AllVariableUsegesAreAllowed(t *testing.T) {
 		})
 	}
 }
+
+func Test_ErrorLocations(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		query     string
+		message   string
+		locations []*gql.ErrorLocation
+	}{
+		{
+			name:      "VariableNotUsed",
+			query:     "query Q($a: Int) {\n  dog {\n    name\n  }\n}",
+			message:   "Variable defined but not used",
+			locations: []*gql.ErrorLocation{{Line: 1, Column: 9}},
+		},
+		{
+			name:      "FragmentNotUsed",
+			query:     "{\n  dog {\n    name\n  }\n}\nfragment F on Dog {\n  name\n}",
+			message:   "fragment 'F' is not used",
+			locations: []*gql.ErrorLocation{{Line: 6, Column: 1}},
+		},
+		{
+			name:      "FieldDoesNotExist",
+			query:     "{\n  dog {\n    meowVolume\n  }\n}",
+			message:   "Field 'meowVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?",
+			locations: []*gql.ErrorLocation{{Line: 3, Column: 5}},
+		},
+		{
+			name:      "DirectiveNotDefined",
+			query:     "{\n  dog @foo {\n    name\n  }\n}",
+			message:   "directive 'foo' is not defined",
+			locations: []*gql.ErrorLocation{{Line: 2, Column: 7}},
+		},
+		{
+			name:      "ArgumentNotProvided",
+			query:     "{\n  dog {\n    doesKnowCommand\n  }\n}",
+			message:   "argument 'dogCommand' is required (NonNull) but not provided",
+			locations: []*gql.ErrorLocation{{Line: 3, Column: 5}},
+		},
+		{
+			name:      "ArgumentSetMultipleTimes",
+			query:     "{\n  dog {\n    doesKnowCommand(dogCommand: SIT, dogCommand: SIT)\n  }\n}",
+			message:   "argument 'dogCommand' is set multiple times",
+			locations: []*gql.ErrorLocation{{Line: 3, Column: 21}, {Line: 3, Column: 38}},
+		},
+		{
+			name:      "SelectionMissing",
+			query:     "{\n  dog {\n    owner\n  }\n}",
+			message:   "Selection on type 'Human' is missing",
+			locations: []*gql.ErrorLocation{{Line: 3, Column: 5}},
+		},
+		{
+			name:      "FragmentNotDefined",
+			query:     "{\n  dog {\n    ...F\n  }\n}",
+			message:   "fragment 'F' is not defined in query",
+			locations: []*gql.ErrorLocation{{Line: 3, Column: 5}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := gql.Execute(ctx, testutil.Schema, gql.Params{Query: tt.query})
+			for _, e := range r.Errors {
+				if e.Message != tt.message {
+					continue
+				}
+				if !reflect.DeepEqual(e.Locations, tt.locations) {
+					t.Fatalf("expected locations %v, got %v", tt.locations, e.Locations)
+				}
+				return
+			}
+			t.Fatalf("expected error '%s', got %+v", tt.message, r.Errors)
+		})
+	}
+}
+
+func Test_ValidationRules(t *testing.T) {
+	ctx := context.Background()
+	// noNicknames is a custom rule that rejects the nickname fields
+	noNicknames := &gql.ValidationRule{
+		Name: "NoNicknames",
+		Visitor: func(ctx *gql.ValidationContext) *visitor.Visitor {
+			return &visitor.Visitor{
+				Kinds: map[visitor.Kind]visitor.Funcs{
+					visitor.FieldKind: {
+						Enter: func(node interface{}, _ *visitor.Info) visitor.Action {
+							f := node.(*ast.Field)
+							if ctx.ParentType() != nil && f.Name == "nickname" {
+								ctx.ReportError(&gql.Error{
+									Message:   "nickname on " + ctx.ParentType().GetName() + " is not allowed",
+									Locations: []*gql.ErrorLocation{{Line: f.Location.Line, Column: f.Location.Column}},
+								})
+							}
+							return visitor.Continue
+						},
+					},
+				},
+			}
+		},
+	}
+	withoutRule := func(name string) []*gql.ValidationRule {
+		rules := []*gql.ValidationRule{}
+		for _, r := range gql.SpecifiedRules {
+			if r.Name != name {
+				rules = append(rules, r)
+			}
+		}
+		return rules
+	}
+
+	tests := []struct {
+		name     string
+		rules    []*gql.ValidationRule
+		query    string
+		messages []string
+	}{
+		{
+			name:     "SpecifiedRules",
+			query:    "{\n  dog {\n    name\n    nickname\n  }\n}",
+			messages: []string{},
+		},
+		{
+			name:     "CustomRule",
+			rules:    append(append([]*gql.ValidationRule{}, gql.SpecifiedRules...), noNicknames),
+			query:    "{\n  dog {\n    name\n    nickname\n  }\n}",
+			messages: []string{"nickname on Dog is not allowed"},
+		},
+		{
+			name:     "RemovedRule",
+			rules:    withoutRule(gql.NoUnusedFragmentsRule.Name),
+			query:    "{\n  dog {\n    name\n  }\n}\nfragment F on Dog {\n  name\n}",
+			messages: []string{},
+		},
+		{
+			name:     "NoRules",
+			rules:    []*gql.ValidationRule{},
+			query:    "query Q($a: Int) {\n  dog {\n    name\n  }\n}",
+			messages: []string{},
+		},
+		{
+			name:     "MultipleErrors",
+			query:    "query Q($a: Int) {\n  dog {\n    meowVolume\n    ...F\n  }\n}",
+			messages: []string{"Field 'meowVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?", "fragment 'F' is not defined in query", "Variable defined but not used"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages := []string{}
+			for _, e := range gql.Validate(testutil.Schema, doc, tt.rules) {
+				messages = append(messages, e.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.messages) {
+				t.Fatalf("expected errors %q, got %q", tt.messages, messages)
+			}
+
+			// the same rules are used by the executor
+			r := gql.NewExecutor(gql.ExecutorConfig{
+				Schema:          testutil.Schema,
+				ValidationRules: tt.rules,
+			}).Execute(ctx, gql.Params{Query: tt.query})
+			if len(tt.messages) != 0 && (len(r.Errors) != len(tt.messages) || r.Errors[0].Message != tt.messages[0]) {
+				t.Fatalf("expected errors %q from the executor, got %+v", tt.messages, r.Errors)
+			}
+		})
+	}
+}
+
+func Test_Suggestions(t *testing.T) {
+	tests := []struct {
+		name        string
+		query       string
+		message     string
+		suggestions []string
+	}{
+		{
+			name:        "Field",
+			query:       "{\n  dog {\n    nam\n  }\n}",
+			message:     "Field 'nam' does not exist on type 'Dog'. Did you mean 'name'?",
+			suggestions: []string{"name"},
+		},
+		{
+			name:        "FieldOnAbstractType",
+			query:       "{\n  pet {\n    barkVolume\n  }\n}",
+			message:     "Field 'barkVolume' does not exist on type 'Pet'. Did you mean to use an inline fragment on 'Dog'?",
+			suggestions: []string{"Dog"},
+		},
+		{
+			name:        "Argument",
+			query:       "{\n  dog {\n    isHousetrained(atOtherHome: true)\n  }\n}",
+			message:     "argument 'atOtherHome' is not defined. Did you mean 'atOtherHomes'?",
+			suggestions: []string{"atOtherHomes"},
+		},
+		{
+			name:        "Type",
+			query:       "{\n  dog {\n    ... on Dgo {\n      name\n    }\n  }\n}",
+			message:     "fragment's target type (Dgo) is not defined in query. Did you mean 'Dog'?",
+			suggestions: []string{"Dog"},
+		},
+		{
+			name:        "VariableType",
+			query:       "query Q($a: Strin) {\n  complicatedArgs {\n    stringArgField(stringArg: $a)\n  }\n}",
+			message:     "Unknown type 'Strin'. Did you mean 'String'?",
+			suggestions: []string{"String"},
+		},
+		{
+			name:        "EnumValue",
+			query:       "{\n  dog {\n    doesKnowCommand(dogCommand: SITT)\n  }\n}",
+			message:     "invalid enum value 'SITT'. Did you mean 'SIT'?",
+			suggestions: []string{"SIT"},
+		},
+		{
+			name:        "InputField",
+			query:       "{\n  complicatedArgs {\n    complexArgField(complexArg: { requiredField: true, intFeild: 1 })\n  }\n}",
+			message:     "field 'intFeild' is not defined. Did you mean 'intField'?",
+			suggestions: []string{"intField"},
+		},
+		{
+			name:        "Fragment",
+			query:       "{\n  dog {\n    ...DogFields\n  }\n  d: dog {\n    ...DogFeilds\n  }\n}\nfragment DogFields on Dog {\n  name\n}",
+			message:     "fragment 'DogFeilds' is not defined in query. Did you mean 'DogFields'?",
+			suggestions: []string{"DogFields"},
+		},
+		{
+			name:        "Directive",
+			query:       "{\n  dog @inclde(if: true) {\n    name\n  }\n}",
+			message:     "directive 'inclde' is not defined. Did you mean 'include'?",
+			suggestions: []string{"include"},
+		},
+		{
+			name:    "NoSimilarOptions",
+			query:   "{\n  dog {\n    tailLength\n  }\n}",
+			message: "Field 'tailLength' does not exist on type 'Dog'",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			errs := gql.Validate(testutil.Schema, doc, nil)
+			if len(errs) != 1 {
+				t.Fatalf("expected one error, got %+v", errs)
+			}
+			if errs[0].Message != tt.message {
+				t.Fatalf("expected error '%s', got '%s'", tt.message, errs[0].Message)
+			}
+			if tt.suggestions == nil {
+				if _, ok := errs[0].Extensions["suggestions"]; ok {
+					t.Fatalf("expected no suggestions, got %v", errs[0].Extensions)
+				}
+				return
+			}
+			if !reflect.DeepEqual(errs[0].Extensions["suggestions"], tt.suggestions) {
+				t.Fatalf("expected suggestions %v, got %v", tt.suggestions, errs[0].Extensions["suggestions"])
+			}
+
+			// the suggestions can be turned off for the executor
+			r := gql.NewExecutor(gql.ExecutorConfig{
+				Schema:             testutil.Schema,
+				DisableSuggestions: true,
+			}).Execute(context.Background(), gql.Params{Query: tt.query})
+			if len(r.Errors) != 1 || strings.Contains(r.Errors[0].Message, "Did you mean") || r.Errors[0].Extensions["suggestions"] != nil {
+				t.Fatalf("expected an error without suggestions, got %+v", r.Errors)
+			}
+		})
+	}
+}
+
+func Test_ValidateDocument(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		messages []string
+	}{
+		{
+			name:     "ValidOperationsWithVariables",
+			query:    "query A($id: ID) {\n  human(id: $id) {\n    name\n  }\n}\nquery B($cmd: DogCommand!) {\n  dog {\n    ...DogFields\n    doesKnowCommand(dogCommand: $cmd)\n  }\n}\nfragment DogFields on Dog {\n  name\n}",
+			messages: []string{},
+		},
+		{
+			name:  "AllOperations",
+			query: "query A($unused: Int) {\n  dog {\n    ...DogFields\n  }\n}\nquery B {\n  dog {\n    meowVolume\n  }\n}\nquery C($id: ID) {\n  human(id: $id) {\n    name\n  }\n  cat {\n    name(surname: $surname)\n  }\n}\nfragment DogFields on Dog {\n  name\n}\nfragment Unused on Dog {\n  name\n}",
+			messages: []string{
+				"Variable defined but not used",
+				"Field 'meowVolume' does not exist on type 'Dog'. Did you mean 'barkVolume'?",
+				"argument 'surname' is not defined",
+				"variable 'surname' is not defined",
+				"fragment 'Unused' is not used",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := parser.Parse([]byte(tt.query))
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages := []string{}
+			for _, e := range gql.ValidateDocument(testutil.Schema, doc) {
+				if e.Extensions["code"] != string(gql.ErrValidationFailed) {
+					t.Fatalf("expected the validation error code, got %v", e.Extensions)
+				}
+				messages = append(messages, e.Message)
+			}
+			if !reflect.DeepEqual(messages, tt.messages) {
+				t.Fatalf("expected errors %q, got %q", tt.messages, messages)
+			}
+		})
+	}
+}